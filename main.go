@@ -3,17 +3,34 @@ package main
 import (
 	"flag"
 	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/astdot"
+	"gocompiler/src/astjson"
+	"gocompiler/src/astprint"
+	"gocompiler/src/diff"
 	lexer "gocompiler/src/lexer"
 	"gocompiler/src/parser"
+	"gocompiler/src/printer"
 	"gocompiler/src/tokens"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 var options struct {
-	lex    bool
-	ast    bool
-	source string
+	lex       bool
+	ast       bool
+	dot       bool
+	positions bool
+	trace     bool
+	format    string
+	source    string
+
+	fmt   bool
+	list  bool
+	diff  bool
+	write bool
 }
 
 func main() {
@@ -22,12 +39,21 @@ func main() {
 	flag.StringVar(&options.source, "source", "input.txt", "filename of source to lex")
 	flag.BoolVar(&options.lex, "lex", false, "perform lexical analysis")
 	flag.BoolVar(&options.ast, "ast", false, "creates AST tree for the code")
+	flag.BoolVar(&options.dot, "dot", false, "creates AST tree for the code and prints it as a Graphviz graph")
+	flag.BoolVar(&options.positions, "positions", false, "show source positions in the graph, use with -dot")
+	flag.StringVar(&options.format, "format", "tree", "output format of -ast: tree or json")
+	flag.BoolVar(&options.trace, "trace", false, "print the productions entered and exited while parsing, use with -ast")
+	flag.BoolVar(&options.fmt, "fmt", false, "format the files or directories given as arguments, or the source")
+	flag.BoolVar(&options.list, "l", false, "list the files whose formatting differs, use with -fmt")
+	flag.BoolVar(&options.diff, "d", false, "print the diffs of the formatting, use with -fmt")
+	flag.BoolVar(&options.write, "w", false, "write the formatted source back to the files, use with -fmt")
 	flag.Parse()
 
 	if options.lex {
 		file, err := os.Open(options.source)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 		lexerInstance := lexer.NewLexer(file)
 		for {
@@ -41,25 +67,145 @@ func main() {
 			}
 		}
 	} else if options.ast {
-		file, err := os.Open(options.source)
-		if err != nil {
-			panic(err)
+		if options.format != "tree" && options.format != "json" {
+			fmt.Fprintf(os.Stderr, "unknown format %q\n", options.format)
+			os.Exit(1)
 		}
-		lexerInstance := lexer.NewLexer(file)
-		var tokenList []tokens.Token
-		for {
-			pos, tok, lex, lit := lexerInstance.Lex()
-			if tok == tokens.EOF {
-				break
+		f := parseSource()
+		if options.format == "json" {
+			b, err := astjson.MarshalIndent(f, "", "  ")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
 			}
-			tokenList = append(tokenList, tokens.Token{Pos: pos, Tok: tok, Lex: lex, Lit: lit})
-			if tok == tokens.ILLEGAL {
-				break
+			fmt.Println(string(b))
+		} else {
+			str := astprint.PrintFile(f)
+			fmt.Println(str)
+		}
+	} else if options.dot {
+		f := parseSource()
+		nodes := make([]ast.Node, len(f.Decls))
+		for i, decl := range f.Decls {
+			nodes[i] = decl
+		}
+		var mode astdot.Mode
+		if options.positions {
+			mode |= astdot.Positions
+		}
+		astdot.Fprint(os.Stdout, nodes, mode)
+	} else if options.fmt {
+		paths := flag.Args()
+		if len(paths) == 0 {
+			paths = []string{options.source}
+		}
+		exitCode := 0
+		for _, path := range paths {
+			if err := formatPath(path); err != nil {
+				exitCode = 2
+			}
+		}
+		os.Exit(exitCode)
+	}
+}
+
+// formatPath formats the file at path, or the .go files of the directory tree at path;
+// hidden directories are skipped. Errors are reported and the last one is returned
+func formatPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+	if !info.IsDir() {
+		return formatFile(path)
+	}
+	var last error
+	err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			last = err
+		case d.IsDir() && name != path && strings.HasPrefix(d.Name(), "."):
+			return filepath.SkipDir
+		case !d.IsDir() && filepath.Ext(name) == ".go":
+			if err := formatFile(name); err != nil {
+				last = err
 			}
 		}
-		parserInstance := parser.NewParser(tokenList)
-		astTree := parserInstance.Parse()
-		str := parser.PrintAST(astTree)
-		fmt.Println(str)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return last
+}
+
+// formatFile formats a single file according to the -l, -d and -w options; without
+// any of them the formatted source is written to the standard output
+func formatFile(name string) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+	f, err := parser.ParseFile(tokens.NewFileSet(), name, src, parser.ParseComments)
+	if list, isList := err.(parser.ErrorList); isList {
+		for _, e := range list {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		return err
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+	res, err := printer.Source(f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+
+	if !options.list && !options.diff && !options.write {
+		os.Stdout.Write(res)
+		return nil
+	}
+	if string(res) == string(src) {
+		return nil
+	}
+	if options.list {
+		fmt.Println(name)
+	}
+	if options.write {
+		info, err := os.Stat(name)
+		if err == nil {
+			err = os.WriteFile(name, res, info.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return err
+		}
+	}
+	if options.diff {
+		os.Stdout.Write(diff.Unified(name+".orig", name, src, res))
+	}
+	return nil
+}
+
+// parseSource parses the source file; on errors it reports them and exits
+func parseSource() *ast.File {
+	mode := parser.ParseComments
+	if options.trace {
+		mode |= parser.Trace
+	}
+	f, err := parser.ParseFile(tokens.NewFileSet(), options.source, nil, mode)
+	if list, isList := err.(parser.ErrorList); isList {
+		for _, e := range list {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	return f
 }
@@ -1,21 +1,605 @@
+// Package ast declares the types used to represent syntax trees of the Go subset
 package ast
 
 import (
 	"gocompiler/src/tokens"
+	"unicode/utf8"
 )
 
+// Node is implemented by all node types. Pos is the position of the first character
+// of the node and End the position of the first character immediately after it
 type Node interface {
 	Pos() tokens.Position
 	End() tokens.Position
 }
 
-type Expr interface {
+type Expression interface {
 	Node
-	expressionNode()
+	exprNode()
 }
 
-type BinaryExpression struct {
-	left     Node
-	right    Node
-	operator tokens.TokenType
+type Statement interface {
+	Node
+	stmtNode()
+}
+
+type Spec interface {
+	Node
+	specNode()
+}
+
+type Declaration interface {
+	Node
+	declNode()
+}
+
+// Comment is a // or /* */ comment; Text holds the comment as written, markers included
+type Comment struct {
+	Slash tokens.Position
+	Text  string
+}
+
+func (c *Comment) Pos() tokens.Position { return c.Slash }
+func (c *Comment) End() tokens.Position {
+	return (&tokens.Token{Pos: c.Slash, Lit: c.Text}).End()
+}
+
+// Field is a field declaration in a struct type or a parameter in a signature; embedded fields have no names
+type Field struct {
+	Names []*Ident
+	Type  Expression
+	Tag   *BasicLiteral
+}
+
+func (f *Field) Pos() tokens.Position {
+	if len(f.Names) > 0 {
+		return f.Names[0].Pos()
+	}
+	return f.Type.Pos()
+}
+
+func (f *Field) End() tokens.Position {
+	if f.Tag != nil {
+		return f.Tag.End()
+	}
+	return f.Type.End()
+}
+
+// FieldList is a list of fields enclosed in parentheses, brackets or braces; Opening and Closing
+// are invalid if the list is not enclosed, like a single unnamed result
+type FieldList struct {
+	Opening tokens.Position
+	List    []*Field
+	Closing tokens.Position
+}
+
+func (f *FieldList) Pos() tokens.Position {
+	if f.Opening.IsValid() {
+		return f.Opening
+	}
+	if len(f.List) > 0 {
+		return f.List[0].Pos()
+	}
+	return tokens.Position{}
+}
+
+func (f *FieldList) End() tokens.Position {
+	if f.Closing.IsValid() {
+		return f.Closing.Add(1)
+	}
+	if n := len(f.List); n > 0 {
+		return f.List[n-1].End()
+	}
+	return tokens.Position{}
+}
+
+func (f *FieldList) NumFields() int {
+	n := 0
+	if f != nil {
+		for _, g := range f.List {
+			m := len(g.Names)
+			if m == 0 {
+				m = 1
+			}
+			n += m
+		}
+	}
+	return n
+}
+
+// File is a parsed source file; the package clause is optional in this subset of Go
+type File struct {
+	Package  tokens.Position // position of "package"; invalid if the clause is missing
+	Name     *Ident          // package name; nil if the clause is missing
+	Decls    []Declaration
+	Imports  []*ImportSpec // imports of this file
+	Comments []*Comment    // all comments of the file, collected in ParseComments mode only
+}
+
+func (f *File) Pos() tokens.Position {
+	if f.Package.IsValid() {
+		return f.Package
+	}
+	if len(f.Decls) > 0 {
+		return f.Decls[0].Pos()
+	}
+	return tokens.Position{}
 }
+
+func (f *File) End() tokens.Position {
+	if n := len(f.Decls); n > 0 {
+		return f.Decls[n-1].End()
+	}
+	if f.Name != nil {
+		return f.Name.End()
+	}
+	return tokens.Position{}
+}
+
+// spec nodes
+
+type (
+	ImportSpec struct {
+		Name *Ident // local package name, "."; or nil
+		Path *BasicLiteral
+	}
+
+	ValueSpec struct {
+		Names  []*Ident
+		Type   Expression // value type; or nil
+		Values []Expression
+	}
+
+	TypeSpec struct {
+		Name       *Ident
+		TypeParams *FieldList
+		AssignPos  tokens.Position // position of "=" of an alias declaration; or invalid
+		Type       Expression
+	}
+)
+
+// declaration nodes
+type (
+	FunctionDeclaration struct {
+		Name *Ident
+		Type *FunctionType // Type.Func is the position of the "func" keyword
+		Body *BlockStatement
+	}
+
+	GenericDeclaration struct {
+		Token     tokens.TokenType // IMPORT, CONST, TYPE or VAR
+		TokPos    tokens.Position  // position of Token
+		LParenPos tokens.Position  // invalid if the declaration is not grouped
+		RParenPos tokens.Position
+		Specs     []Spec
+	}
+
+	// BadDeclaration is a placeholder for a declaration containing syntax errors
+	BadDeclaration struct {
+		From tokens.Position
+		To   tokens.Position
+	}
+)
+
+// expression nodes
+type (
+	Ident struct {
+		NamePos tokens.Position
+		Name    string
+		Obj     *Object // denoted object, set by the resolver; or nil
+	}
+
+	Ellipsis struct {
+		Ellipsis tokens.Position // position of "..."
+		Elt      Expression      // element type of a variadic parameter; or nil
+	}
+
+	BasicLiteral struct {
+		ValuePos tokens.Position
+		Type     tokens.TokenType
+		Value    tokens.Token
+	}
+
+	FunctionLiteral struct {
+		Type *FunctionType
+		Body *BlockStatement
+	}
+
+	CompositeLiteral struct {
+		Type       Expression
+		LbracePos  tokens.Position
+		RbracePos  tokens.Position
+		Elements   []Expression
+		Incomplete bool
+	}
+
+	ParenExpression struct {
+		LParenPos tokens.Position
+		X         Expression
+		RParenPos tokens.Position
+	}
+
+	IndexExpressions struct {
+		X       Expression
+		Lbrack  tokens.Position
+		Rbrack  tokens.Position
+		Indices []Expression
+	}
+
+	StarExpression struct {
+		Star tokens.Position // position of "*"
+		X    Expression
+	}
+
+	UnaryExpression struct {
+		OpPos    tokens.Position
+		Operator tokens.TokenType
+		X        Expression
+	}
+
+	BinaryExpression struct {
+		OpPos    tokens.Position
+		Operator tokens.TokenType
+		LeftX    Expression
+		RightX   Expression
+	}
+
+	SelectorExpression struct {
+		X        Expression
+		Selector *Ident
+	}
+
+	CallExpression struct {
+		Function  Expression
+		LParenPos tokens.Position
+		Arguments []Expression
+		Ellipsis  tokens.Position // position of "..." after the last argument; or invalid
+		RParenPos tokens.Position
+	}
+
+	IndexExpression struct {
+		X           Expression
+		LBracketPos tokens.Position
+		RBracketPos tokens.Position
+		Index       Expression
+	}
+
+	KeyValueExpression struct {
+		Key      Expression
+		ColonPos tokens.Position
+		Value    Expression
+	}
+
+	BadExpression struct {
+		From tokens.Position
+		To   tokens.Position
+	}
+)
+
+// type-specific expression nodes
+type (
+	FunctionType struct {
+		Func       tokens.Position // position of "func"; invalid for an interface method
+		TypeParams *FieldList
+		Params     *FieldList
+		Results    *FieldList
+	}
+
+	ArrayType struct {
+		Lbrack      tokens.Position
+		Len         Expression // nil for slice types
+		ElementType Expression
+	}
+
+	StructType struct {
+		Struct     tokens.Position // position of "struct"
+		Fields     *FieldList
+		Incomplete bool
+	}
+
+	// InterfaceType is an interface type. A method is a field named after the method whose type
+	// is a *FunctionType without "func"; an embedded element is a field without names, a type or
+	// a union of terms joined by "|" in BinaryExpressions, with ~T terms in UnaryExpressions
+	InterfaceType struct {
+		Interface  tokens.Position // position of "interface"
+		Methods    *FieldList
+		Incomplete bool
+	}
+)
+
+// statements
+type (
+	BlockStatement struct {
+		LbracePos tokens.Position
+		List      []Statement
+		RbracePos tokens.Position
+	}
+
+	ReturnStatement struct {
+		Return  tokens.Position
+		Results []Expression
+	}
+
+	IfStatement struct {
+		If   tokens.Position
+		Init Statement
+		Cond Expression
+		Body *BlockStatement
+		Else Statement
+	}
+
+	ForStatement struct {
+		For  tokens.Position
+		Init Statement  // initialization statement; or nil
+		Cond Expression // condition; or nil
+		Post Statement  // post iteration statement; or nil
+		Body *BlockStatement
+	}
+
+	RangeStatement struct {
+		For        tokens.Position
+		Key, Value Expression      // Key, Value may be nil
+		TokPos     tokens.Position // position of Tok; invalid if Key == nil
+		Tok        tokens.Token    // ASSIGN, DEFINE; undefined if Key == nil
+		X          Expression      // value to range over
+		Body       *BlockStatement
+	}
+
+	SwitchStatement struct {
+		Switch tokens.Position
+		Init   Statement  // initialization statement; or nil
+		Tag    Expression // tag expression; or nil
+		Body   *BlockStatement
+	}
+
+	CaseClause struct {
+		Case  tokens.Position // position of "case" or "default"
+		List  []Expression    // list of expressions; nil means default case
+		Colon tokens.Position
+		Body  []Statement
+	}
+
+	AssignStatement struct {
+		Lhs    []Expression
+		TokPos tokens.Position // position of Tok
+		Tok    tokens.Token    // assignment token, DEFINE
+		Rhs    []Expression
+	}
+
+	IncDecStatement struct {
+		X      Expression
+		TokPos tokens.Position // position of Tok
+		Tok    tokens.Token    // INC or DEC
+	}
+
+	ExpressionStatement struct {
+		X Expression
+	}
+
+	DeclarationStatement struct {
+		Decl Declaration
+	}
+
+	// BranchStatement is a break, continue, goto or fallthrough statement
+	BranchStatement struct {
+		TokPos tokens.Position // position of Tok
+		Tok    tokens.Token    // BREAK, CONTINUE, GOTO or FALLTHROUGH
+		Label  *Ident          // label; or nil
+	}
+
+	LabeledStatement struct {
+		Label *Ident
+		Colon tokens.Position // position of ":"
+		Stmt  Statement       // labeled statement; or nil if the label ends a block
+	}
+
+	// BadStatement is a placeholder for a statement containing syntax errors
+	BadStatement struct {
+		From tokens.Position
+		To   tokens.Position
+	}
+)
+
+func (n *Ident) Pos() tokens.Position           { return n.NamePos }
+func (n *Ellipsis) Pos() tokens.Position        { return n.Ellipsis }
+func (n *BasicLiteral) Pos() tokens.Position    { return n.ValuePos }
+func (n *FunctionLiteral) Pos() tokens.Position { return n.Type.Pos() }
+func (n *CompositeLiteral) Pos() tokens.Position {
+	if n.Type != nil {
+		return n.Type.Pos()
+	}
+	return n.LbracePos
+}
+func (n *ParenExpression) Pos() tokens.Position    { return n.LParenPos }
+func (n *IndexExpressions) Pos() tokens.Position   { return n.X.Pos() }
+func (n *StarExpression) Pos() tokens.Position     { return n.Star }
+func (n *UnaryExpression) Pos() tokens.Position    { return n.OpPos }
+func (n *BinaryExpression) Pos() tokens.Position   { return n.LeftX.Pos() }
+func (n *SelectorExpression) Pos() tokens.Position { return n.X.Pos() }
+func (n *CallExpression) Pos() tokens.Position     { return n.Function.Pos() }
+func (n *IndexExpression) Pos() tokens.Position    { return n.X.Pos() }
+func (n *KeyValueExpression) Pos() tokens.Position { return n.Key.Pos() }
+func (n *BadExpression) Pos() tokens.Position      { return n.From }
+func (n *ArrayType) Pos() tokens.Position          { return n.Lbrack }
+func (n *StructType) Pos() tokens.Position         { return n.Struct }
+func (n *InterfaceType) Pos() tokens.Position      { return n.Interface }
+func (n *FunctionType) Pos() tokens.Position {
+	if n.Func.IsValid() || n.Params == nil {
+		return n.Func
+	}
+	return n.Params.Pos()
+}
+
+func (n *Ident) End() tokens.Position {
+	return n.NamePos.Add(utf8.RuneCountInString(n.Name))
+}
+func (n *Ellipsis) End() tokens.Position {
+	if n.Elt != nil {
+		return n.Elt.End()
+	}
+	return n.Ellipsis.Add(3)
+}
+func (n *BasicLiteral) End() tokens.Position       { return n.Value.End() }
+func (n *FunctionLiteral) End() tokens.Position    { return n.Body.End() }
+func (n *CompositeLiteral) End() tokens.Position   { return n.RbracePos.Add(1) }
+func (n *ParenExpression) End() tokens.Position    { return n.RParenPos.Add(1) }
+func (n *IndexExpressions) End() tokens.Position   { return n.Rbrack.Add(1) }
+func (n *StarExpression) End() tokens.Position     { return n.X.End() }
+func (n *UnaryExpression) End() tokens.Position    { return n.X.End() }
+func (n *BinaryExpression) End() tokens.Position   { return n.RightX.End() }
+func (n *SelectorExpression) End() tokens.Position { return n.Selector.End() }
+func (n *CallExpression) End() tokens.Position     { return n.RParenPos.Add(1) }
+func (n *IndexExpression) End() tokens.Position    { return n.RBracketPos.Add(1) }
+func (n *KeyValueExpression) End() tokens.Position { return n.Value.End() }
+func (n *BadExpression) End() tokens.Position      { return n.To }
+func (n *FunctionType) End() tokens.Position {
+	if n.Results.NumFields() > 0 {
+		return n.Results.End()
+	}
+	return n.Params.End()
+}
+func (n *ArrayType) End() tokens.Position     { return n.ElementType.End() }
+func (n *StructType) End() tokens.Position    { return n.Fields.End() }
+func (n *InterfaceType) End() tokens.Position { return n.Methods.End() }
+
+func (n *BlockStatement) Pos() tokens.Position  { return n.LbracePos }
+func (n *ReturnStatement) Pos() tokens.Position { return n.Return }
+func (n *IfStatement) Pos() tokens.Position     { return n.If }
+func (n *ForStatement) Pos() tokens.Position    { return n.For }
+func (n *RangeStatement) Pos() tokens.Position  { return n.For }
+func (n *SwitchStatement) Pos() tokens.Position { return n.Switch }
+func (n *CaseClause) Pos() tokens.Position      { return n.Case }
+func (n *AssignStatement) Pos() tokens.Position {
+	if len(n.Lhs) > 0 {
+		return n.Lhs[0].Pos()
+	}
+	return n.Rhs[0].Pos() // a range clause without iteration variables
+}
+func (n *IncDecStatement) Pos() tokens.Position      { return n.X.Pos() }
+func (n *ExpressionStatement) Pos() tokens.Position  { return n.X.Pos() }
+func (n *DeclarationStatement) Pos() tokens.Position { return n.Decl.Pos() }
+func (n *BadStatement) Pos() tokens.Position         { return n.From }
+func (n *BranchStatement) Pos() tokens.Position      { return n.TokPos }
+func (n *LabeledStatement) Pos() tokens.Position     { return n.Label.Pos() }
+
+func (n *BlockStatement) End() tokens.Position { return n.RbracePos.Add(1) }
+func (n *ReturnStatement) End() tokens.Position {
+	if k := len(n.Results); k > 0 {
+		return n.Results[k-1].End()
+	}
+	return n.Return.Add(len("return"))
+}
+func (n *IfStatement) End() tokens.Position {
+	if n.Else != nil {
+		return n.Else.End()
+	}
+	return n.Body.End()
+}
+func (n *ForStatement) End() tokens.Position    { return n.Body.End() }
+func (n *RangeStatement) End() tokens.Position  { return n.Body.End() }
+func (n *SwitchStatement) End() tokens.Position { return n.Body.End() }
+func (n *CaseClause) End() tokens.Position {
+	if k := len(n.Body); k > 0 {
+		return n.Body[k-1].End()
+	}
+	return n.Colon.Add(1)
+}
+func (n *AssignStatement) End() tokens.Position      { return n.Rhs[len(n.Rhs)-1].End() }
+func (n *IncDecStatement) End() tokens.Position      { return n.TokPos.Add(2) }
+func (n *ExpressionStatement) End() tokens.Position  { return n.X.End() }
+func (n *DeclarationStatement) End() tokens.Position { return n.Decl.End() }
+func (n *BadStatement) End() tokens.Position         { return n.To }
+func (n *BranchStatement) End() tokens.Position {
+	if n.Label != nil {
+		return n.Label.End()
+	}
+	return n.TokPos.Add(len(n.Tok.Tok.String()))
+}
+func (n *LabeledStatement) End() tokens.Position {
+	if n.Stmt != nil {
+		return n.Stmt.End()
+	}
+	return n.Colon.Add(1)
+}
+
+func (n *ImportSpec) Pos() tokens.Position {
+	if n.Name != nil {
+		return n.Name.Pos()
+	}
+	return n.Path.Pos()
+}
+func (n *ValueSpec) Pos() tokens.Position { return n.Names[0].Pos() }
+func (n *TypeSpec) Pos() tokens.Position  { return n.Name.Pos() }
+
+func (n *ImportSpec) End() tokens.Position { return n.Path.End() }
+func (n *ValueSpec) End() tokens.Position {
+	if k := len(n.Values); k > 0 {
+		return n.Values[k-1].End()
+	}
+	if n.Type != nil {
+		return n.Type.End()
+	}
+	return n.Names[len(n.Names)-1].End()
+}
+func (n *TypeSpec) End() tokens.Position { return n.Type.End() }
+
+func (n *FunctionDeclaration) Pos() tokens.Position { return n.Type.Pos() }
+func (n *GenericDeclaration) Pos() tokens.Position  { return n.TokPos }
+func (n *BadDeclaration) Pos() tokens.Position      { return n.From }
+
+func (n *FunctionDeclaration) End() tokens.Position {
+	if n.Body != nil {
+		return n.Body.End()
+	}
+	return n.Type.End()
+}
+func (n *GenericDeclaration) End() tokens.Position {
+	if n.RParenPos.IsValid() {
+		return n.RParenPos.Add(1)
+	}
+	return n.Specs[0].End()
+}
+func (n *BadDeclaration) End() tokens.Position { return n.To }
+
+func (*Ident) exprNode()              {}
+func (*Ellipsis) exprNode()           {}
+func (*BasicLiteral) exprNode()       {}
+func (*UnaryExpression) exprNode()    {}
+func (*StarExpression) exprNode()     {}
+func (*ParenExpression) exprNode()    {}
+func (*BinaryExpression) exprNode()   {}
+func (*ArrayType) exprNode()          {}
+func (*StructType) exprNode()         {}
+func (*InterfaceType) exprNode()      {}
+func (*FunctionType) exprNode()       {}
+func (*SelectorExpression) exprNode() {}
+func (*CallExpression) exprNode()     {}
+func (*IndexExpression) exprNode()    {}
+func (*IndexExpressions) exprNode()   {}
+func (*CompositeLiteral) exprNode()   {}
+func (*KeyValueExpression) exprNode() {}
+func (*FunctionLiteral) exprNode()    {}
+func (*BadExpression) exprNode()      {}
+
+func (*BlockStatement) stmtNode()       {}
+func (*ReturnStatement) stmtNode()      {}
+func (*IfStatement) stmtNode()          {}
+func (*ForStatement) stmtNode()         {}
+func (*RangeStatement) stmtNode()       {}
+func (*SwitchStatement) stmtNode()      {}
+func (*CaseClause) stmtNode()           {}
+func (*AssignStatement) stmtNode()      {}
+func (*IncDecStatement) stmtNode()      {}
+func (*ExpressionStatement) stmtNode()  {}
+func (*DeclarationStatement) stmtNode() {}
+func (*BadStatement) stmtNode()         {}
+func (*BranchStatement) stmtNode()      {}
+func (*LabeledStatement) stmtNode()     {}
+
+func (*ImportSpec) specNode() {}
+func (*ValueSpec) specNode()  {}
+func (*TypeSpec) specNode()   {}
+
+func (*FunctionDeclaration) declNode() {}
+func (*GenericDeclaration) declNode()  {}
+func (*BadDeclaration) declNode()      {}
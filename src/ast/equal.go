@@ -0,0 +1,121 @@
+package ast
+
+import (
+	"gocompiler/src/tokens"
+	"reflect"
+)
+
+// Mode controls Equal and Fprint
+type Mode uint
+
+const (
+	IgnorePositions Mode = 1 << iota // positions of nodes and tokens are neither compared nor printed
+)
+
+var (
+	positionType = reflect.TypeOf(tokens.Position{})
+	tokenType    = reflect.TypeOf(tokens.Token{})
+)
+
+// skipField reports whether the field i of the struct type t does not belong to the tree structure:
+// Ident.Obj refers to resolver data that may point back into the tree
+func skipField(t reflect.Type, i int) bool {
+	return t == reflect.TypeOf(Ident{}) && t.Field(i).Name == "Obj"
+}
+
+// Equal reports whether the trees x and y have the same structure and content.
+// Tokens are compared by type and literal text; nil and empty lists are equal,
+// and Ident.Obj is not compared
+func Equal(x, y Node, mode Mode) bool {
+	return equal(reflect.ValueOf(x), reflect.ValueOf(y), mode)
+}
+
+func equal(x, y reflect.Value, mode Mode) bool {
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() == y.IsValid()
+	}
+	if x.Type() != y.Type() {
+		return false
+	}
+	switch x.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		return equal(x.Elem(), y.Elem(), mode)
+	case reflect.Slice:
+		if x.Len() != y.Len() {
+			return false
+		}
+		for i := 0; i < x.Len(); i++ {
+			if !equal(x.Index(i), y.Index(i), mode) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		switch x.Type() {
+		case positionType:
+			return mode&IgnorePositions != 0 || x.Interface() == y.Interface()
+		case tokenType:
+			a, b := x.Interface().(tokens.Token), y.Interface().(tokens.Token)
+			return a.Tok == b.Tok && a.Lit == b.Lit && (mode&IgnorePositions != 0 || a.Pos == b.Pos)
+		}
+		for i := 0; i < x.NumField(); i++ {
+			if !skipField(x.Type(), i) && !equal(x.Field(i), y.Field(i), mode) {
+				return false
+			}
+		}
+		return true
+	}
+	return x.Interface() == y.Interface()
+}
+
+// Clone returns a deep copy of the tree rooted at node. Ident.Obj is shared with the original
+func Clone(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	return clone(reflect.ValueOf(node)).Interface().(Node)
+}
+
+func clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(clone(v.Elem()))
+		return c
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(clone(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(clone(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		if v.Type() == positionType || v.Type() == tokenType {
+			return c
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !skipField(v.Type(), i) {
+				c.Field(i).Set(clone(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
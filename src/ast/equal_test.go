@@ -0,0 +1,98 @@
+package ast_test
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"strings"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	x := parse(t)
+	y := parse(t)
+	if !ast.Equal(x, y, 0) {
+		t.Errorf("trees of the same source must be equal")
+	}
+
+	moved, err := parser.ParseFile(tokens.NewFileSet(), "p.go", "\n\n"+src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ast.Equal(x, moved, 0) {
+		t.Errorf("trees at different positions must differ")
+	}
+	if !ast.Equal(x, moved, ast.IgnorePositions) {
+		t.Errorf("trees at different positions must be equal when positions are ignored")
+	}
+
+	changed, err := parser.ParseFile(tokens.NewFileSet(), "p.go", strings.Replace(src, "r += v * i", "r += v * j", 1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ast.Equal(x, changed, ast.IgnorePositions) {
+		t.Errorf("trees with different identifiers must differ")
+	}
+
+	empty := &ast.BlockStatement{}
+	if !ast.Equal(empty, &ast.BlockStatement{List: []ast.Statement{}}, 0) {
+		t.Errorf("nil and empty lists must be equal")
+	}
+	if ast.Equal(empty, &ast.BlockStatement{List: []ast.Statement{&ast.BadStatement{}}}, 0) {
+		t.Errorf("lists of different length must differ")
+	}
+}
+
+func TestClone(t *testing.T) {
+	f := parse(t)
+	c := ast.Clone(f).(*ast.File)
+	if !ast.Equal(f, c, 0) {
+		t.Fatalf("the clone must be equal to the original")
+	}
+	if ast.Sprint(f, 0) != ast.Sprint(c, 0) {
+		t.Errorf("the clone must print like the original")
+	}
+
+	// modifying the clone must not affect the original
+	ast.Inspect(c, func(n ast.Node) bool {
+		if id, isIdent := n.(*ast.Ident); isIdent {
+			id.Name = "z"
+		}
+		return true
+	})
+	c.Decls = c.Decls[:1]
+	if !ast.Equal(f, parse(t), 0) {
+		t.Errorf("the original was modified through the clone")
+	}
+	if ast.Equal(f, c, ast.IgnorePositions) {
+		t.Errorf("the modified clone must differ from the original")
+	}
+}
+
+func TestSprint(t *testing.T) {
+	expr, err := parser.ParseExpr("-a[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `UnaryExpression {
+  OpPos: 1:1
+  Operator: -
+  X: IndexExpression {
+    X: Ident {
+      NamePos: 1:2
+      Name: "a"
+    }
+    LBracketPos: 1:3
+    RBracketPos: 1:5
+    Index: BasicLiteral {
+      ValuePos: 1:4
+      Type: INT
+      Value: INT "1" 1:4
+    }
+  }
+}
+`
+	if result := ast.Sprint(expr, 0); result != expected {
+		t.Errorf("expected\n%s got\n%s", expected, result)
+	}
+}
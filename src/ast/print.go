@@ -0,0 +1,111 @@
+package ast
+
+import (
+	"fmt"
+	"gocompiler/src/tokens"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Fprint writes a dump of the tree rooted at node to w. The dump lists every node with
+// its non-empty fields, one per line, and depends only on the node types: unlike the tree
+// drawings of package astprint it is meant as a stable serialized form for golden tests.
+// Tokens are written as their type and quoted literal text, Ident.Obj is omitted
+func Fprint(w io.Writer, node Node, mode Mode) error {
+	p := &printer{mode: mode}
+	p.value(reflect.ValueOf(node))
+	p.buf.WriteByte('\n')
+	_, err := io.WriteString(w, p.buf.String())
+	return err
+}
+
+// Sprint returns the dump written by Fprint
+func Sprint(node Node, mode Mode) string {
+	var b strings.Builder
+	Fprint(&b, node, mode)
+	return b.String()
+}
+
+type printer struct {
+	buf    strings.Builder
+	mode   Mode
+	indent int
+}
+
+func (p *printer) newline() {
+	p.buf.WriteByte('\n')
+	p.buf.WriteString(strings.Repeat("  ", p.indent))
+}
+
+// empty reports whether the field value v is omitted from the dump
+func (p *printer) empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	case reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Struct:
+		return v.Type() == positionType && (p.mode&IgnorePositions != 0 || !v.Interface().(tokens.Position).IsValid())
+	}
+	return false
+}
+
+func (p *printer) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		p.buf.WriteString("nil")
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			p.buf.WriteString("nil")
+			return
+		}
+		p.value(v.Elem())
+	case reflect.Slice:
+		p.buf.WriteByte('[')
+		p.indent++
+		for i := 0; i < v.Len(); i++ {
+			p.newline()
+			p.value(v.Index(i))
+		}
+		p.indent--
+		p.newline()
+		p.buf.WriteByte(']')
+	case reflect.Struct:
+		switch v.Type() {
+		case positionType:
+			p.buf.WriteString(v.Interface().(tokens.Position).ToString())
+			return
+		case tokenType:
+			tok := v.Interface().(tokens.Token)
+			fmt.Fprintf(&p.buf, "%s %q", tok.Tok, tok.Lit)
+			if p.mode&IgnorePositions == 0 && tok.Pos.IsValid() {
+				p.buf.WriteString(" " + tok.Pos.ToString())
+			}
+			return
+		}
+		p.buf.WriteString(v.Type().Name() + " {")
+		p.indent++
+		fields := 0
+		for i := 0; i < v.NumField(); i++ {
+			if skipField(v.Type(), i) || p.empty(v.Field(i)) {
+				continue
+			}
+			p.newline()
+			p.buf.WriteString(v.Type().Field(i).Name + ": ")
+			p.value(v.Field(i))
+			fields++
+		}
+		p.indent--
+		if fields > 0 {
+			p.newline()
+		}
+		p.buf.WriteByte('}')
+	case reflect.String:
+		fmt.Fprintf(&p.buf, "%q", v.String())
+	default:
+		fmt.Fprint(&p.buf, v.Interface())
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/ast to the syntax trees of this module.
+
+package ast
+
+import (
+	"gocompiler/src/tokens"
+	"sort"
+	"strings"
+)
+
+// Scope maps the names declared in a block to their objects and links to the scope
+// of the enclosing block
+type Scope struct {
+	Outer   *Scope
+	Objects map[string]*Object
+}
+
+// NewScope creates a new scope nested in the outer scope
+func NewScope(outer *Scope) *Scope {
+	return &Scope{Outer: outer, Objects: map[string]*Object{}}
+}
+
+// Lookup returns the object with the given name declared in s itself, or nil
+func (s *Scope) Lookup(name string) *Object {
+	return s.Objects[name]
+}
+
+// LookupParent returns the object with the given name declared in s or the innermost
+// enclosing scope declaring it, or nil
+func (s *Scope) LookupParent(name string) *Object {
+	for ; s != nil; s = s.Outer {
+		if obj := s.Objects[name]; obj != nil {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Insert declares obj in s unless s already holds an object with the same name,
+// which is returned then; otherwise Insert returns nil
+func (s *Scope) Insert(obj *Object) (alt *Object) {
+	if alt = s.Objects[obj.Name]; alt == nil {
+		s.Objects[obj.Name] = obj
+	}
+	return
+}
+
+// String returns the names declared in s in sorted order, for debugging
+func (s *Scope) String() string {
+	names := make([]string, 0, len(s.Objects))
+	for name := range s.Objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("scope {")
+	for _, name := range names {
+		b.WriteString("\n\t" + s.Objects[name].Kind.String() + " " + name)
+	}
+	b.WriteString("\n}")
+	return b.String()
+}
+
+// Object is a named language entity: a package, constant, type, variable, function or label.
+// Decl is the node declaring the object:
+//
+//	Pkg   *ImportSpec
+//	Con   *ValueSpec
+//	Typ   *TypeSpec, or *Field for a type parameter
+//	Var   *ValueSpec, *Field, *AssignStatement or *RangeStatement
+//	Fun   *FunctionDeclaration
+//	Lbl   *LabeledStatement
+//
+// Decl is nil for the predeclared objects of the universe scope. Data holds the
+// index of the spec of a constant in its declaration, the value of iota
+type Object struct {
+	Kind ObjKind
+	Name string
+	Decl any
+	Data any
+	Type any // placeholder for type information
+}
+
+// NewObj creates an object of the given kind and name
+func NewObj(kind ObjKind, name string) *Object {
+	return &Object{Kind: kind, Name: name}
+}
+
+// Pos returns the position of the identifier declaring obj, or an invalid position
+// if it is not known
+func (obj *Object) Pos() tokens.Position {
+	name := obj.Name
+	switch d := obj.Decl.(type) {
+	case *Field:
+		for _, n := range d.Names {
+			if n.Name == name {
+				return n.Pos()
+			}
+		}
+	case *ImportSpec:
+		if d.Name != nil && d.Name.Name == name {
+			return d.Name.Pos()
+		}
+		return d.Path.Pos()
+	case *ValueSpec:
+		for _, n := range d.Names {
+			if n.Name == name {
+				return n.Pos()
+			}
+		}
+	case *TypeSpec:
+		if d.Name.Name == name {
+			return d.Name.Pos()
+		}
+	case *FunctionDeclaration:
+		if d.Name.Name == name {
+			return d.Name.Pos()
+		}
+	case *AssignStatement:
+		for _, x := range d.Lhs {
+			if ident, isIdent := x.(*Ident); isIdent && ident.Name == name {
+				return ident.Pos()
+			}
+		}
+	case *RangeStatement:
+		for _, x := range []Expression{d.Key, d.Value} {
+			if ident, isIdent := x.(*Ident); isIdent && ident.Name == name {
+				return ident.Pos()
+			}
+		}
+	case *LabeledStatement:
+		if d.Label.Name == name {
+			return d.Label.Pos()
+		}
+	}
+	return tokens.Position{}
+}
+
+// ObjKind is the kind of an object
+type ObjKind int
+
+const (
+	Bad ObjKind = iota // for error handling
+	Pkg                // package
+	Con                // constant
+	Typ                // type
+	Var                // variable
+	Fun                // function
+	Lbl                // label
+)
+
+var objKindStrings = [...]string{
+	Bad: "bad",
+	Pkg: "package",
+	Con: "const",
+	Typ: "type",
+	Var: "var",
+	Fun: "func",
+	Lbl: "label",
+}
+
+func (kind ObjKind) String() string { return objKindStrings[kind] }
+
+// Package is a set of files forming a package, with the scope of its package level declarations
+type Package struct {
+	Name  string
+	Scope *Scope
+	Files map[string]*File
+}
@@ -0,0 +1,77 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from reflect.StructTag to the field tags of this module.
+
+package ast
+
+import "strconv"
+
+// StructTag is the tag string of a struct field. By convention it is a
+// space-separated list of key:"value" pairs, e.g. `json:"name,omitempty" db:"name"`
+type StructTag string
+
+// StructTag returns the unquoted tag of the field, or an empty tag if it has none
+func (f *Field) StructTag() StructTag {
+	if f.Tag == nil {
+		return ""
+	}
+	value, _ := f.Tag.Value.Lex.(string)
+	return StructTag(value)
+}
+
+// Get returns the value associated with key in the tag,
+// or an empty string if there is no such key
+func (tag StructTag) Get(key string) string {
+	value, _ := tag.Lookup(key)
+	return value
+}
+
+// Lookup returns the value associated with key in the tag and whether the key was present
+func (tag StructTag) Lookup(key string) (value string, ok bool) {
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// a key is a non-empty run of characters other than space, quote, colon and control characters
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// scan the quoted value, skipping escaped characters
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quoted := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if key == name {
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				break
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,294 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/ast to the syntax trees of this module.
+
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil)
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+func walkList[N Node](v Visitor, list []N) {
+	for _, node := range list {
+		Walk(v, node)
+	}
+}
+
+// Walk traverses the tree in depth-first order: it starts by calling v.Visit(node);
+// node must not be nil. If the visitor w returned by v.Visit(node) is not nil,
+// Walk is invoked recursively with visitor w for each of the non-nil children
+// of node, followed by a call of w.Visit(nil)
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// comments and fields
+	case *Comment:
+		// nothing to do
+
+	case *Field:
+		walkList(v, n.Names)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		if n.Tag != nil {
+			Walk(v, n.Tag)
+		}
+
+	case *FieldList:
+		walkList(v, n.List)
+
+	// expressions
+	case *BadExpression, *Ident, *BasicLiteral:
+		// nothing to do
+
+	case *Ellipsis:
+		if n.Elt != nil {
+			Walk(v, n.Elt)
+		}
+
+	case *FunctionLiteral:
+		Walk(v, n.Type)
+		Walk(v, n.Body)
+
+	case *CompositeLiteral:
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		walkList(v, n.Elements)
+
+	case *ParenExpression:
+		Walk(v, n.X)
+
+	case *SelectorExpression:
+		Walk(v, n.X)
+		Walk(v, n.Selector)
+
+	case *IndexExpression:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+
+	case *IndexExpressions:
+		Walk(v, n.X)
+		walkList(v, n.Indices)
+
+	case *CallExpression:
+		Walk(v, n.Function)
+		walkList(v, n.Arguments)
+
+	case *StarExpression:
+		Walk(v, n.X)
+
+	case *UnaryExpression:
+		Walk(v, n.X)
+
+	case *BinaryExpression:
+		Walk(v, n.LeftX)
+		Walk(v, n.RightX)
+
+	case *KeyValueExpression:
+		Walk(v, n.Key)
+		Walk(v, n.Value)
+
+	// types
+	case *ArrayType:
+		if n.Len != nil {
+			Walk(v, n.Len)
+		}
+		Walk(v, n.ElementType)
+
+	case *StructType:
+		Walk(v, n.Fields)
+
+	case *InterfaceType:
+		Walk(v, n.Methods)
+
+	case *FunctionType:
+		if n.TypeParams != nil {
+			Walk(v, n.TypeParams)
+		}
+		if n.Params != nil {
+			Walk(v, n.Params)
+		}
+		if n.Results != nil {
+			Walk(v, n.Results)
+		}
+
+	// statements
+	case *BadStatement:
+		// nothing to do
+
+	case *DeclarationStatement:
+		Walk(v, n.Decl)
+
+	case *ExpressionStatement:
+		Walk(v, n.X)
+
+	case *IncDecStatement:
+		Walk(v, n.X)
+
+	case *AssignStatement:
+		walkList(v, n.Lhs)
+		walkList(v, n.Rhs)
+
+	case *ReturnStatement:
+		walkList(v, n.Results)
+
+	case *BranchStatement:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+
+	case *LabeledStatement:
+		Walk(v, n.Label)
+		if n.Stmt != nil {
+			Walk(v, n.Stmt)
+		}
+
+	case *BlockStatement:
+		walkList(v, n.List)
+
+	case *IfStatement:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+
+	case *CaseClause:
+		walkList(v, n.List)
+		walkList(v, n.Body)
+
+	case *SwitchStatement:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Tag != nil {
+			Walk(v, n.Tag)
+		}
+		Walk(v, n.Body)
+
+	case *ForStatement:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		if n.Post != nil {
+			Walk(v, n.Post)
+		}
+		Walk(v, n.Body)
+
+	case *RangeStatement:
+		if n.Key != nil {
+			Walk(v, n.Key)
+		}
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+		Walk(v, n.X)
+		Walk(v, n.Body)
+
+	// declarations
+	case *ImportSpec:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		Walk(v, n.Path)
+
+	case *ValueSpec:
+		walkList(v, n.Names)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		walkList(v, n.Values)
+
+	case *TypeSpec:
+		Walk(v, n.Name)
+		if n.TypeParams != nil {
+			Walk(v, n.TypeParams)
+		}
+		Walk(v, n.Type)
+
+	case *BadDeclaration:
+		// nothing to do
+
+	case *GenericDeclaration:
+		walkList(v, n.Specs)
+
+	case *FunctionDeclaration:
+		Walk(v, n.Name)
+		Walk(v, n.Type)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	// files
+	case *File:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkList(v, n.Decls)
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order: it starts by calling f(node);
+// node must not be nil. If f returns true, Inspect invokes f recursively for each
+// of the non-nil children of node, followed by a call of f(nil)
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// prePost visits the children of node, see WalkPrePost
+type prePost struct {
+	node Node
+	pre  func(Node) bool
+	post func(Node)
+}
+
+func (v *prePost) Visit(node Node) Visitor {
+	if node == nil {
+		if v.post != nil {
+			v.post(v.node)
+		}
+		return nil
+	}
+	if v.pre != nil && !v.pre(node) {
+		return nil
+	}
+	return &prePost{node: node, pre: v.pre, post: v.post}
+}
+
+// WalkPrePost traverses the tree in depth-first order calling pre before the children
+// of every node and post after them. If pre returns false, the children of the node are
+// skipped and post is not called for it. Either function may be nil
+func WalkPrePost(node Node, pre func(Node) bool, post func(Node)) {
+	Walk(&prePost{pre: pre, post: post}, node)
+}
@@ -0,0 +1,182 @@
+package ast_test
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"strings"
+	"testing"
+)
+
+const src = `package p
+
+import m "math"
+
+type T struct {
+	a, b int ` + "`tag`" + `
+}
+
+func f(x ...int) (r int) {
+	for i, v := range x {
+		if v > 0 {
+			r += v * i
+		} else {
+			r--
+		}
+	}
+	switch y := m.Abs(1); {
+	case y > 1:
+		return []int{1}[0]
+	}
+	return
+}
+`
+
+func parse(t *testing.T) *ast.File {
+	f, err := parser.ParseFile(tokens.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func name(n ast.Node) string {
+	s := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+	if id, isIdent := n.(*ast.Ident); isIdent {
+		s += " " + id.Name
+	}
+	return s
+}
+
+func TestInspect(t *testing.T) {
+	var visited []string
+	depth := 0
+	ast.Inspect(parse(t), func(n ast.Node) bool {
+		if n == nil {
+			depth--
+			return true
+		}
+		visited = append(visited, strings.Repeat(".", depth)+name(n))
+		depth++
+		return true
+	})
+	expected := `File
+.Ident p
+.GenericDeclaration
+..ImportSpec
+...Ident m
+...BasicLiteral
+.GenericDeclaration
+..TypeSpec
+...Ident T
+...StructType
+....FieldList
+.....Field
+......Ident a
+......Ident b
+......Ident int
+......BasicLiteral
+.FunctionDeclaration
+..Ident f
+..FunctionType
+...FieldList
+....Field
+.....Ident x
+.....Ellipsis
+......Ident int
+...FieldList
+....Field
+.....Ident r
+.....Ident int
+..BlockStatement
+...RangeStatement
+....Ident i
+....Ident v
+....Ident x
+....BlockStatement
+.....IfStatement
+......BinaryExpression
+.......Ident v
+.......BasicLiteral
+......BlockStatement
+.......AssignStatement
+........Ident r
+........BinaryExpression
+.........Ident v
+.........Ident i
+......BlockStatement
+.......IncDecStatement
+........Ident r
+...SwitchStatement
+....AssignStatement
+.....Ident y
+.....CallExpression
+......SelectorExpression
+.......Ident m
+.......Ident Abs
+......BasicLiteral
+....BlockStatement
+.....CaseClause
+......BinaryExpression
+.......Ident y
+.......BasicLiteral
+......ReturnStatement
+.......IndexExpression
+........CompositeLiteral
+.........ArrayType
+..........Ident int
+.........BasicLiteral
+........BasicLiteral
+...ReturnStatement`
+	if result := strings.Join(visited, "\n"); result != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, result)
+	}
+	if depth != 0 {
+		t.Errorf("expected a post call for every node, depth is %d", depth)
+	}
+}
+
+type counter map[string]int
+
+func (c counter) Visit(n ast.Node) ast.Visitor {
+	if n != nil {
+		c[name(n)]++
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	c := counter{}
+	ast.Walk(c, parse(t))
+	if c["Ident r"] != 3 || c["FieldList"] != 3 || c["Field"] != 3 || c["BasicLiteral"] != 7 {
+		t.Errorf("unexpected node counts %v", c)
+	}
+}
+
+func TestWalkPrePost(t *testing.T) {
+	expr, err := parser.ParseExpr("a + f(b) * -c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	ast.WalkPrePost(expr, func(n ast.Node) bool {
+		order = append(order, "pre "+name(n))
+		_, isCall := n.(*ast.CallExpression)
+		return !isCall
+	}, func(n ast.Node) {
+		order = append(order, "post "+name(n))
+	})
+	expected := []string{
+		"pre BinaryExpression",
+		"pre Ident a", "post Ident a",
+		"pre BinaryExpression",
+		"pre CallExpression",
+		"pre UnaryExpression", "pre Ident c", "post Ident c", "post UnaryExpression",
+		"post BinaryExpression",
+		"post BinaryExpression",
+	}
+	if result, want := strings.Join(order, ", "), strings.Join(expected, ", "); result != want {
+		t.Errorf("expected %s\ngot %s", want, result)
+	}
+}
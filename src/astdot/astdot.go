@@ -0,0 +1,149 @@
+// Package astdot renders syntax trees as Graphviz DOT graphs
+package astdot
+
+import (
+	"bufio"
+	"fmt"
+	"gocompiler/src/ast"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Mode controls Fprint
+type Mode uint
+
+const (
+	// Positions adds the source range of every node to its label and puts the graph
+	// of every top level node into a cluster subgraph labelled with its range
+	Positions Mode = 1 << iota
+)
+
+// Fprint writes a directed graph of the trees rooted at nodes to w. The label of a graph node
+// is the node type followed by its operator, identifier or literal, if any; the edges are
+// labelled with the names of the fields holding the children
+func Fprint(w io.Writer, nodes []ast.Node, mode Mode) error {
+	p := &printer{out: bufio.NewWriter(w), mode: mode}
+	p.printf("digraph AST {\n")
+	p.printf("\tnode [shape=box, fontname=\"monospace\"];\n")
+	p.printf("\tedge [fontname=\"monospace\", fontsize=10];\n")
+	for i, node := range nodes {
+		if mode&Positions != 0 {
+			p.printf("\tsubgraph cluster_%d {\n", i)
+			p.printf("\t\tlabel=%s;\n", quote(span(node)))
+			p.indent = "\t\t"
+			p.node(node)
+			p.printf("\t}\n")
+		} else {
+			p.indent = "\t"
+			p.node(node)
+		}
+	}
+	p.printf("}\n")
+	return p.out.Flush()
+}
+
+// Sprint returns the graph written by Fprint
+func Sprint(nodes []ast.Node, mode Mode) string {
+	var b strings.Builder
+	Fprint(&b, nodes, mode)
+	return b.String()
+}
+
+type printer struct {
+	out    *bufio.Writer
+	mode   Mode
+	indent string
+	count  int // number of graph nodes written
+}
+
+func (p *printer) printf(format string, args ...any) {
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// node writes the graph node of n and the subgraphs of its children and returns the id of the graph node
+func (p *printer) node(n ast.Node) string {
+	p.count++
+	id := fmt.Sprintf("n%d", p.count)
+	text := label(n)
+	if p.mode&Positions != 0 {
+		text += "\n" + span(n)
+	}
+	p.printf("%s%s [label=%s];\n", p.indent, id, quote(text))
+
+	v := reflect.ValueOf(n).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type() == identType && v.Type().Field(i).Name == "Obj" {
+			continue
+		}
+		name := v.Type().Field(i).Name
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Interface, reflect.Pointer:
+			if child, isNode := f.Interface().(ast.Node); isNode && !f.IsNil() {
+				p.edge(id, p.node(child), name)
+			}
+		case reflect.Slice:
+			for j := 0; j < f.Len(); j++ {
+				if child, isNode := f.Index(j).Interface().(ast.Node); isNode && !f.Index(j).IsNil() {
+					p.edge(id, p.node(child), fmt.Sprintf("%s[%d]", name, j))
+				}
+			}
+		}
+	}
+	return id
+}
+
+func (p *printer) edge(from, to, name string) {
+	p.printf("%s%s -> %s [label=%s];\n", p.indent, from, to, quote(name))
+}
+
+var identType = reflect.TypeOf(ast.Ident{})
+
+// label returns the node type of n followed by its operator, identifier or literal
+func label(n ast.Node) string {
+	kind := reflect.TypeOf(n).Elem().Name()
+	var detail string
+	switch n := n.(type) {
+	case *ast.Ident:
+		detail = n.Name
+	case *ast.BasicLiteral:
+		detail = n.Value.Lit
+	case *ast.UnaryExpression:
+		detail = n.Operator.String()
+	case *ast.BinaryExpression:
+		detail = n.Operator.String()
+	case *ast.AssignStatement:
+		detail = n.Tok.Tok.String()
+	case *ast.IncDecStatement:
+		detail = n.Tok.Tok.String()
+	case *ast.BranchStatement:
+		detail = n.Tok.Tok.String()
+	case *ast.RangeStatement:
+		if n.Key != nil {
+			detail = n.Tok.Tok.String()
+		}
+	case *ast.GenericDeclaration:
+		detail = n.Token.String()
+	case *ast.FunctionDeclaration:
+		detail = n.Name.Name
+	case *ast.Comment:
+		detail = n.Text
+	}
+	if detail == "" {
+		return kind
+	}
+	return kind + "\n" + detail
+}
+
+// span returns the source range of n
+func span(n ast.Node) string {
+	return n.Pos().ToString() + "-" + n.End().ToString()
+}
+
+// quote returns s as a DOT string; line breaks become centered line breaks of the label
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
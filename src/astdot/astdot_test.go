@@ -0,0 +1,50 @@
+package astdot_test
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/astdot"
+	"gocompiler/src/parser"
+	"strings"
+	"testing"
+)
+
+func TestFprint(t *testing.T) {
+	expr, err := parser.ParseExpr(`-a[i] == "\"x\""`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph AST {
+	node [shape=box, fontname="monospace"];
+	edge [fontname="monospace", fontsize=10];
+	n1 [label="BinaryExpression\n=="];
+	n2 [label="UnaryExpression\n-"];
+	n3 [label="IndexExpression"];
+	n4 [label="Ident\na"];
+	n3 -> n4 [label="X"];
+	n5 [label="Ident\ni"];
+	n3 -> n5 [label="Index"];
+	n2 -> n3 [label="X"];
+	n1 -> n2 [label="LeftX"];
+	n6 [label="BasicLiteral\n\"\\\"x\\\"\""];
+	n1 -> n6 [label="RightX"];
+}
+`
+	if result := astdot.Sprint([]ast.Node{expr}, 0); result != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, result)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	x, _ := parser.ParseExpr("x")
+	y, _ := parser.ParseExpr("f(y)")
+	result := astdot.Sprint([]ast.Node{x, y}, astdot.Positions)
+	for _, s := range []string{
+		"\tsubgraph cluster_0 {\n\t\tlabel=\"1:1-1:2\";\n\t\tn1 [label=\"Ident\\nx\\n1:1-1:2\"];\n\t}\n",
+		"\tsubgraph cluster_1 {\n\t\tlabel=\"1:1-1:5\";\n",
+		"\t\tn4 [label=\"Ident\\ny\\n1:3-1:4\"];\n\t\tn2 -> n4 [label=\"Arguments[0]\"];\n",
+	} {
+		if !strings.Contains(result, s) {
+			t.Errorf("expected %q in\n%s", s, result)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Package astjson encodes syntax trees as JSON and decodes them back.
+//
+// Every node is a JSON object whose "Kind" member names the node type and whose "Pos"
+// and "End" members hold the positions of the node; the other members are the fields
+// of the node under their Go names, in declaration order. Nil, empty and false fields
+// and invalid positions are omitted. A position is encoded as {"Line": l, "Column": c},
+// a token as {"Tok": "IDENT", "Lit": "x", "Pos": ...} and a token type as its string,
+// "+" or "IDENT" for example. Ident.Obj is not encoded
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/lexer"
+	"gocompiler/src/tokens"
+	"reflect"
+	"strings"
+)
+
+// kinds maps the names of the node types to the types
+var kinds = map[string]reflect.Type{}
+
+// tokenTypes maps the strings of the token types to the types
+var tokenTypes = map[string]tokens.TokenType{}
+
+func init() {
+	for _, n := range []ast.Node{
+		// comments and fields
+		&ast.Comment{}, &ast.Field{}, &ast.FieldList{},
+		// expressions
+		&ast.BadExpression{}, &ast.Ident{}, &ast.Ellipsis{}, &ast.BasicLiteral{}, &ast.FunctionLiteral{},
+		&ast.CompositeLiteral{}, &ast.ParenExpression{}, &ast.SelectorExpression{}, &ast.IndexExpression{},
+		&ast.IndexExpressions{}, &ast.CallExpression{}, &ast.StarExpression{}, &ast.UnaryExpression{},
+		&ast.BinaryExpression{}, &ast.KeyValueExpression{},
+		// types
+		&ast.ArrayType{}, &ast.StructType{}, &ast.FunctionType{}, &ast.InterfaceType{},
+		// statements
+		&ast.BadStatement{}, &ast.DeclarationStatement{}, &ast.ExpressionStatement{}, &ast.IncDecStatement{},
+		&ast.AssignStatement{}, &ast.ReturnStatement{}, &ast.BlockStatement{}, &ast.IfStatement{},
+		&ast.CaseClause{}, &ast.SwitchStatement{}, &ast.ForStatement{}, &ast.RangeStatement{},
+		&ast.BranchStatement{}, &ast.LabeledStatement{},
+		// declarations
+		&ast.ImportSpec{}, &ast.ValueSpec{}, &ast.TypeSpec{}, &ast.BadDeclaration{}, &ast.GenericDeclaration{},
+		&ast.FunctionDeclaration{},
+		// files
+		&ast.File{},
+	} {
+		t := reflect.TypeOf(n).Elem()
+		kinds[t.Name()] = t
+	}
+	for t := tokens.EOF; t <= tokens.VAR; t++ {
+		tokenTypes[t.String()] = t
+	}
+}
+
+var (
+	positionType = reflect.TypeOf(tokens.Position{})
+	tokenType    = reflect.TypeOf(tokens.Token{})
+	tokTypeType  = reflect.TypeOf(tokens.TokenType(0))
+)
+
+// skipField reports whether the field i of the struct type t is not encoded
+func skipField(t reflect.Type, i int) bool {
+	return t == kinds["Ident"] && t.Field(i).Name == "Obj"
+}
+
+// Marshal returns the JSON encoding of the tree rooted at node
+func Marshal(node ast.Node) ([]byte, error) {
+	e := &encoder{}
+	if err := e.value(reflect.ValueOf(node)); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// MarshalIndent is like Marshal but applies json.Indent to format the output
+func MarshalIndent(node ast.Node, prefix, indent string) ([]byte, error) {
+	b, err := Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+// empty reports whether the field value v is omitted from the encoding
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	case reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Struct:
+		return v.Type() == positionType && !v.Interface().(tokens.Position).IsValid()
+	}
+	return false
+}
+
+func (e *encoder) member(name string) {
+	if b := e.buf.Bytes(); b[len(b)-1] != '{' {
+		e.buf.WriteByte(',')
+	}
+	e.json(name)
+	e.buf.WriteByte(':')
+}
+
+func (e *encoder) json(x any) error {
+	b, err := json.Marshal(x)
+	e.buf.Write(b)
+	return err
+}
+
+func (e *encoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Invalid:
+		e.buf.WriteString("null")
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.value(v.Elem())
+	case reflect.Slice:
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+	case reflect.Struct:
+		switch v.Type() {
+		case positionType:
+			return e.json(v.Interface())
+		case tokenType:
+			tok := v.Interface().(tokens.Token)
+			e.buf.WriteByte('{')
+			e.member("Tok")
+			e.json(tok.Tok.String())
+			e.member("Lit")
+			e.json(tok.Lit)
+			if tok.Pos.IsValid() {
+				e.member("Pos")
+				e.json(tok.Pos)
+			}
+			e.buf.WriteByte('}')
+			return nil
+		}
+		if kinds[v.Type().Name()] != v.Type() {
+			return fmt.Errorf("astjson: unexpected type %s", v.Type())
+		}
+		e.buf.WriteByte('{')
+		e.member("Kind")
+		e.json(v.Type().Name())
+		node := v.Addr().Interface().(ast.Node)
+		if pos := node.Pos(); pos.IsValid() {
+			e.member("Pos")
+			e.json(pos)
+		}
+		if end := node.End(); end.IsValid() {
+			e.member("End")
+			e.json(end)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if skipField(v.Type(), i) || empty(v.Field(i)) {
+				continue
+			}
+			e.member(v.Type().Field(i).Name)
+			if err := e.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte('}')
+	case reflect.Int:
+		if v.Type() == tokTypeType {
+			return e.json(v.Interface().(tokens.TokenType).String())
+		}
+		return e.json(v.Interface())
+	default:
+		return e.json(v.Interface())
+	}
+	return nil
+}
+
+// Unmarshal decodes a tree encoded by Marshal. The lexical values of the tokens
+// are recovered by scanning their literal text again
+func Unmarshal(data []byte) (ast.Node, error) {
+	var node ast.Node
+	if err := decode(data, reflect.ValueOf(&node).Elem()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// decode stores the JSON value data in v, which must be settable
+func decode(data json.RawMessage, v reflect.Value) error {
+	if string(data) == "null" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer:
+		n, err := decodeNode(data)
+		if err != nil {
+			return err
+		}
+		if !n.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("astjson: expected %s, found %s", typeName(v.Type()), n.Elem().Type().Name())
+		}
+		v.Set(n)
+	case v.Kind() == reflect.Slice:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, x := range list {
+			if err := decode(x, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case v.Type() == tokenType:
+		var tok struct {
+			Tok string
+			Lit string
+			Pos tokens.Position
+		}
+		if err := strictUnmarshal(data, &tok); err != nil {
+			return err
+		}
+		t, known := tokenTypes[tok.Tok]
+		if !known {
+			return fmt.Errorf("astjson: unknown token %q", tok.Tok)
+		}
+		token := tokens.Token{Pos: tok.Pos, Tok: t, Lit: tok.Lit}
+		if tok.Lit != "" {
+			_, _, token.Lex, _ = lexer.NewLexer(strings.NewReader(tok.Lit)).Lex()
+		}
+		v.Set(reflect.ValueOf(token))
+	case v.Type() == tokTypeType:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		t, known := tokenTypes[s]
+		if !known {
+			return fmt.Errorf("astjson: unknown token %q", s)
+		}
+		v.Set(reflect.ValueOf(t))
+	case v.Type() == positionType:
+		return strictUnmarshal(data, v.Addr().Interface())
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	return nil
+}
+
+// decodeNode decodes a JSON object holding a node and returns a pointer to the node
+func decodeNode(data json.RawMessage) (reflect.Value, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return reflect.Value{}, err
+	}
+	var kind string
+	if err := json.Unmarshal(members["Kind"], &kind); err != nil {
+		return reflect.Value{}, fmt.Errorf("astjson: missing node kind")
+	}
+	t, known := kinds[kind]
+	if !known {
+		return reflect.Value{}, fmt.Errorf("astjson: unknown node kind %q", kind)
+	}
+	delete(members, "Kind")
+	delete(members, "Pos") // Pos and End are computed from the fields
+	delete(members, "End")
+
+	n := reflect.New(t)
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		data, present := members[name]
+		if !present || skipField(t, i) {
+			continue
+		}
+		if err := decode(data, n.Elem().Field(i)); err != nil {
+			return reflect.Value{}, err
+		}
+		delete(members, name)
+	}
+	for name := range members {
+		return reflect.Value{}, fmt.Errorf("astjson: unknown field %s.%s", kind, name)
+	}
+	return n, nil
+}
+
+// strictUnmarshal is like json.Unmarshal but rejects unknown members
+func strictUnmarshal(data []byte, x any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	return d.Decode(x)
+}
+
+// typeName returns the name of a node field type for error messages
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem().Name()
+	}
+	return t.Name()
+}
@@ -0,0 +1,81 @@
+package astjson_test
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/astjson"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	expr, err := parser.ParseExpr("-a[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := astjson.Marshal(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Kind":"UnaryExpression","Pos":{"Line":1,"Column":1},"End":{"Line":1,"Column":6},` +
+		`"OpPos":{"Line":1,"Column":1},"Operator":"-","X":{"Kind":"IndexExpression","Pos":{"Line":1,"Column":2},` +
+		`"End":{"Line":1,"Column":6},"X":{"Kind":"Ident","Pos":{"Line":1,"Column":2},"End":{"Line":1,"Column":3},` +
+		`"NamePos":{"Line":1,"Column":2},"Name":"a"},"LBracketPos":{"Line":1,"Column":3},"RBracketPos":{"Line":1,"Column":5},` +
+		`"Index":{"Kind":"BasicLiteral","Pos":{"Line":1,"Column":4},"End":{"Line":1,"Column":5},"ValuePos":{"Line":1,"Column":4},` +
+		`"Type":"INT","Value":{"Tok":"INT","Lit":"1","Pos":{"Line":1,"Column":4}}}}}`
+	if string(b) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b)
+	}
+}
+
+// TestRoundTrip decodes the encoding of every parser test input and compares the result with the parsed tree
+func TestRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../tests/parser/input/*/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no test inputs: %v", err)
+	}
+	for _, name := range files {
+		f, _ := parser.ParseFile(tokens.NewFileSet(), name, nil, parser.ParseComments|parser.AllErrors)
+		if f == nil {
+			t.Fatalf("%s: cannot parse", name)
+		}
+		b, err := astjson.MarshalIndent(f, "", "  ")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		node, err := astjson.Unmarshal(b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !ast.Equal(f, node, 0) {
+			t.Errorf("%s: the decoded tree differs from the parsed one", name)
+		}
+	}
+}
+
+func TestUnmarshalLex(t *testing.T) {
+	node, err := astjson.Unmarshal([]byte(`{"Kind":"BasicLiteral","Type":"STRING","Value":{"Tok":"STRING","Lit":"\"a\\tb\""}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lex := node.(*ast.BasicLiteral).Value.Lex; lex != "a\tb" {
+		t.Errorf("expected the value \"a\\tb\", got %q", lex)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, test := range []struct{ json, err string }{
+		{`{"Name":"x"}`, "astjson: missing node kind"},
+		{`{"Kind":"Foo"}`, `astjson: unknown node kind "Foo"`},
+		{`{"Kind":"Ident","Nme":"x"}`, "astjson: unknown field Ident.Nme"},
+		{`{"Kind":"UnaryExpression","Operator":"+++"}`, `astjson: unknown token "+++"`},
+		{`{"Kind":"ExpressionStatement","X":{"Kind":"BlockStatement"}}`, "astjson: expected Expression, found BlockStatement"},
+		{`{"Kind":"SelectorExpression","Selector":{"Kind":"BadExpression"}}`, "astjson: expected Ident, found BadExpression"},
+	} {
+		_, err := astjson.Unmarshal([]byte(test.json))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: expected error %q, got %v", test.json, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Package astprint renders syntax trees as text trees, one tree per top level node
+package astprint
+
+import (
+	"gocompiler/src/ast"
+	"strings"
+
+	treePrinter "github.com/xlab/treeprint"
+)
+
+func PrintAST(nodes []ast.Node) string {
+	var result strings.Builder
+	for _, node := range nodes {
+		tree := treePrinter.New()
+		printNode(tree, node)
+		result.WriteString(tree.String())
+	}
+	return result.String()
+}
+
+// PrintFile prints the package clause of the file, if any, followed by its declarations
+func PrintFile(f *ast.File) string {
+	var result strings.Builder
+	if f.Name != nil {
+		tree := treePrinter.New()
+		printNode(tree.AddBranch("package"), f.Name)
+		result.WriteString(tree.String())
+	}
+	for _, decl := range f.Decls {
+		tree := treePrinter.New()
+		printNode(tree, decl)
+		result.WriteString(tree.String())
+	}
+	return result.String()
+}
+
+func printNode(tree treePrinter.Tree, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Ident:
+		tree.AddNode(n.Name)
+
+	case *ast.Ellipsis:
+		t := tree.AddBranch("...")
+		printNode(t, n.Elt)
+
+	case *ast.BasicLiteral:
+		tree.AddNode(n.Type.String() + " " + n.Value.LexString())
+
+	case *ast.BinaryExpression:
+		t := tree.AddBranch(n.Operator.String())
+		printNode(t, n.LeftX)
+		if n.RightX != nil {
+			printNode(t, n.RightX)
+		}
+
+	case *ast.ParenExpression:
+		t := tree.AddBranch("paren")
+		printNode(t, n.X)
+
+	case *ast.StarExpression:
+		t := tree.AddBranch("*")
+		printNode(t, n.X)
+
+	case *ast.UnaryExpression:
+		t := tree.AddBranch(n.Operator.String())
+		printNode(t, n.X)
+
+	case *ast.FunctionDeclaration:
+		t := tree.AddBranch(n.Name.Name)
+		body := t.AddBranch("body")
+		typ := t.AddBranch("type")
+		printNode(body, n.Body)
+		printNode(typ, n.Type)
+
+	case *ast.BlockStatement:
+		for _, stmt := range n.List {
+			printNode(tree, stmt)
+		}
+
+	case *ast.FunctionType:
+		t := tree.AddBranch("func_type")
+		if n.TypeParams != nil {
+			tParams := t.AddBranch("type_params")
+			printNode(tParams, n.TypeParams)
+		}
+		if n.Params != nil {
+			params := t.AddBranch("params")
+			printNode(params, n.Params)
+		}
+		if n.Results != nil {
+			results := t.AddBranch("results")
+			printNode(results, n.Results)
+		}
+
+	case *ast.FieldList:
+		for _, f := range n.List {
+			printNode(tree, f)
+		}
+
+	case *ast.Field:
+		t := tree.AddBranch("field")
+		if len(n.Names) > 0 {
+			names := t.AddBranch("names")
+			for _, name := range n.Names {
+				printNode(names, name)
+			}
+		}
+		typ := t.AddBranch("type")
+
+		printNode(typ, n.Type)
+		if n.Tag != nil {
+			t.AddBranch("tag").AddNode(n.Tag.Value.Lit)
+		}
+
+	case *ast.ArrayType:
+		t := tree.AddBranch("array")
+		length := t.AddBranch("length")
+		typ := t.AddBranch("type")
+		if n.Len != nil {
+			printNode(length, n.Len)
+		}
+		printNode(typ, n.ElementType)
+
+	case *ast.StructType:
+		printNode(tree.AddBranch("struct"), n.Fields)
+
+	case *ast.InterfaceType:
+		printNode(tree.AddBranch("interface"), n.Methods)
+
+	case *ast.ImportSpec:
+		spec := tree.AddBranch("spec")
+		if n.Name != nil {
+			printNode(spec.AddBranch("name"), n.Name)
+		}
+		printNode(spec.AddBranch("path"), n.Path)
+
+	case *ast.ValueSpec:
+		names := tree.AddBranch("names")
+		typ := tree.AddBranch("type")
+		values := tree.AddBranch("values")
+		for _, name := range n.Names {
+			printNode(names, name)
+		}
+		if n.Type != nil {
+			printNode(typ, n.Type)
+		}
+		for _, value := range n.Values {
+			printNode(values, value)
+		}
+
+	case *ast.TypeSpec:
+		spec := tree.AddBranch("spec")
+		printNode(spec.AddBranch("name"), n.Name)
+		printNode(spec.AddBranch("type"), n.Type)
+		if n.TypeParams != nil {
+			printNode(tree.AddBranch("type_params"), n.TypeParams)
+		}
+
+	case *ast.AssignStatement:
+		t := tree.AddBranch(n.Tok.LexString())
+		l := t.AddBranch("left")
+		for _, exp := range n.Lhs {
+			printNode(l, exp)
+		}
+		r := t.AddBranch("right")
+		for _, exp := range n.Rhs {
+			printNode(r, exp)
+		}
+
+	case *ast.IfStatement:
+		t := tree.AddBranch("if")
+		body := t.AddBranch("body")
+		if n.Init != nil {
+			init := t.AddBranch("init")
+			printNode(init, n.Init)
+		}
+		if n.Else != nil {
+			printNode(t.AddBranch("else"), n.Else)
+		}
+		printNode(t.AddBranch("condition"), n.Cond)
+		printNode(body, n.Body)
+
+	case *ast.ReturnStatement:
+		t := tree.AddBranch("return")
+		for _, exp := range n.Results {
+			printNode(t, exp)
+		}
+
+	case *ast.BranchStatement:
+		t := tree.AddBranch(n.Tok.Tok.String())
+		if n.Label != nil {
+			printNode(t, n.Label)
+		}
+
+	case *ast.LabeledStatement:
+		t := tree.AddBranch("label")
+		printNode(t, n.Label)
+		if n.Stmt != nil {
+			printNode(t, n.Stmt)
+		}
+
+	case *ast.ForStatement:
+		t := tree.AddBranch("for")
+		if n.Init != nil {
+			printNode(t.AddBranch("init"), n.Init)
+		}
+		if n.Cond != nil {
+			printNode(t.AddBranch("condition"), n.Cond)
+		}
+		if n.Post != nil {
+			printNode(t.AddBranch("post"), n.Post)
+		}
+		body := t.AddBranch("body")
+		printNode(body, n.Body)
+
+	case *ast.RangeStatement:
+		t := tree.AddBranch("range")
+		if n.Key != nil {
+			printNode(t.AddBranch("key"), n.Key)
+		}
+		if n.Value != nil {
+			printNode(t.AddBranch("value"), n.Value)
+		}
+		printNode(t.AddBranch("x"), n.X)
+		printNode(t.AddBranch("body"), n.Body)
+
+	case *ast.SwitchStatement:
+		t := tree.AddBranch("switch")
+		if n.Init != nil {
+			printNode(t.AddBranch("init"), n.Init)
+		}
+		if n.Tag != nil {
+			printNode(t.AddBranch("tag"), n.Tag)
+		}
+		printNode(t.AddBranch("body"), n.Body)
+
+	case *ast.CaseClause:
+		var t treePrinter.Tree
+		if n.List == nil {
+			t = tree.AddBranch("default")
+		} else {
+			t = tree.AddBranch("case")
+			values := t.AddBranch("values")
+			for _, exp := range n.List {
+				printNode(values, exp)
+			}
+		}
+		body := t.AddBranch("body")
+		for _, stmt := range n.Body {
+			printNode(body, stmt)
+		}
+
+	case *ast.ExpressionStatement:
+		printNode(tree, n.X)
+
+	case *ast.IncDecStatement:
+		t := tree.AddBranch(n.Tok.Tok.String())
+		printNode(t, n.X)
+
+	case *ast.SelectorExpression:
+		t := tree.AddBranch("selector")
+		name := t.AddBranch("name")
+		printNode(name, n.Selector)
+		method := t.AddBranch("method")
+		printNode(method, n.X)
+
+	case *ast.CallExpression:
+		fun := tree.AddBranch("method")
+		printNode(fun, n.Function)
+		args := fun.AddBranch("args")
+		for _, arg := range n.Arguments {
+			printNode(args, arg)
+		}
+		if n.Ellipsis.IsValid() {
+			args.AddNode("...")
+		}
+
+	case *ast.DeclarationStatement:
+		t := tree.AddBranch("declaration")
+		printNode(t, n.Decl)
+
+	case *ast.GenericDeclaration:
+		t := tree.AddBranch(n.Token.String())
+		for _, spec := range n.Specs {
+			printNode(t, spec)
+		}
+
+	case *ast.IndexExpression:
+		t := tree.AddBranch("index_expression")
+		printNode(t.AddBranch("name"), n.X)
+		printNode(t.AddBranch("index"), n.Index)
+
+	case *ast.IndexExpressions:
+		t := tree.AddBranch("index_expression")
+		printNode(t.AddBranch("name"), n.X)
+		indicies := t.AddBranch("indicies")
+		for _, arg := range n.Indices {
+			printNode(indicies, arg)
+		}
+
+	case *ast.CompositeLiteral:
+		t := tree.AddBranch("composite_literal")
+		printNode(t.AddBranch("type"), n.Type)
+		elems := t.AddBranch("elements")
+		for _, elem := range n.Elements {
+			printNode(elems, elem)
+		}
+
+	case *ast.KeyValueExpression:
+		t := tree.AddBranch("key_value")
+		printNode(t.AddBranch("key"), n.Key)
+		printNode(t.AddBranch("value"), n.Value)
+
+	case *ast.FunctionLiteral:
+		t := tree.AddBranch("func")
+		printNode(t.AddBranch("type"), n.Type)
+		printNode(t.AddBranch("body"), n.Body)
+
+	case *ast.BadExpression:
+		tree.AddNode("bad_expression")
+
+	case *ast.BadStatement:
+		tree.AddNode("bad_statement")
+
+	case *ast.BadDeclaration:
+		tree.AddNode("bad_declaration")
+	}
+}
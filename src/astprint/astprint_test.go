@@ -0,0 +1,39 @@
+package astprint_test
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/astprint"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPrintAST draws the trees of the parser test inputs and compares them with the drawings in src/tests/astprint
+func TestPrintAST(t *testing.T) {
+	files, err := filepath.Glob("../tests/astprint/*/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no test outputs: %v", err)
+	}
+	for _, name := range files {
+		input := filepath.Join("../tests/parser/input", filepath.Base(filepath.Dir(name)), filepath.Base(name))
+		f, err := parser.ParseFile(tokens.NewFileSet(), input, nil, 0)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+		var nodes []ast.Node
+		for _, decl := range f.Decls {
+			nodes = append(nodes, decl)
+		}
+		expected, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := astprint.PrintAST(nodes); result != strings.ReplaceAll(string(expected), "\r", "") {
+			t.Errorf("%s: expected\n%s got\n%s", name, expected, result)
+		}
+	}
+}
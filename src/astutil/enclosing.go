@@ -0,0 +1,262 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from golang.org/x/tools/go/ast/astutil to the syntax trees of this module.
+
+package astutil
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+	"sort"
+)
+
+// PathEnclosingInterval returns the node that encloses the source interval [start, end),
+// and all its ancestors up to the AST root.
+//
+// The definition of "enclosing" used by this function considers additional whitespace
+// abutting a node to be enclosed by it. In this example:
+//
+//	z := x + y // add them
+//	     <-A->
+//	    <----B----->
+//
+// the ast.BinaryExpression(+) node is considered to enclose interval B even though its
+// [Pos()..End()) is actually only interval A. This behaviour makes user interfaces more
+// tolerant of imperfect input.
+//
+// For the same reason, syntax tokens such as operators, keywords and brackets are treated
+// as pseudo-children of their node: an interval within the "+" token above encloses the
+// ast.BinaryExpression, and an interval overlapping several children of a node is enclosed
+// by the node itself.
+//
+// The path is ordered from the innermost enclosing node to the root, path[0] being the
+// innermost node; it always ends with root, even if the interval lies outside the
+// declarations of the file. An empty interval [start, start) is treated as the interval
+// of the character at start.
+//
+// exact is true if the interval contains only path[0] and perhaps some adjacent whitespace.
+// It is false if the interval overlaps multiple children of path[0], or if it contains only
+// interior whitespace of path[0]
+func PathEnclosingInterval(root *ast.File, start, end tokens.Position) (path []ast.Node, exact bool) {
+	// Precondition: node.[Pos..End) and adjoining whitespace contain [start, end)
+	var visit func(node ast.Node) bool
+	visit = func(node ast.Node) bool {
+		path = append(path, node)
+
+		nodePos := node.Pos()
+		nodeEnd := node.End()
+
+		// intersect [start, end) with the interval of node
+		if start.Before(nodePos) {
+			start = nodePos
+		}
+		if nodeEnd.Before(end) {
+			end = nodeEnd
+		}
+
+		// find the sole child that contains [start, end)
+		children := childrenOf(node)
+		l := len(children)
+		for i, child := range children {
+			// [childPos, childEnd) is the interval of the child
+			childPos := child.Pos()
+			childEnd := child.End()
+
+			// [augPos, augEnd) is the interval of the child augmented with whitespace
+			augPos := childPos
+			augEnd := childEnd
+			if i > 0 {
+				augPos = children[i-1].End() // start of the preceding whitespace
+			}
+			if i < l-1 {
+				nextChildPos := children[i+1].Pos()
+				// does [start, end) lie between the child and the next child?
+				if !start.Before(augEnd) && !nextChildPos.Before(end) {
+					return false // inexact match
+				}
+				augEnd = nextChildPos // end of the following whitespace
+			}
+
+			// does the augmented child contain [start, end)?
+			if !start.Before(augPos) && !augEnd.Before(end) {
+				if _, isToken := child.(tokenNode); isToken {
+					return true
+				}
+
+				// childrenOf elides the FunctionType below a FunctionDeclaration;
+				// add it back for its parameter and result lists
+				if decl, isDecl := node.(*ast.FunctionDeclaration); isDecl {
+					if _, isFields := child.(*ast.FieldList); isFields {
+						path = append(path, decl.Type)
+					}
+				}
+				return visit(child)
+			}
+
+			// does [start, end) overlap multiple children, that is, does the
+			// left-augmented child contain start but not end?
+			if start.Before(childEnd) && augEnd.Before(end) {
+				break
+			}
+		}
+
+		// No single child contains [start, end), so node is the result. It is exact
+		// if the intervals are equal; testing this before the loop would give the wrong
+		// result for a node with a sole child of the same interval, like an
+		// ExpressionStatement
+		return start == nodePos && end == nodeEnd
+	}
+
+	// ensure [start, end) is nondecreasing
+	if end.Before(start) {
+		start, end = end, start
+	}
+
+	if start.Before(root.End()) && root.Pos().Before(end) {
+		if start == end {
+			end = start.Add(1) // the interval of the character at start
+		}
+		exact = visit(root)
+
+		for i, l := 0, len(path); i < l/2; i++ {
+			path[i], path[l-1-i] = path[l-1-i], path[i]
+		}
+	} else {
+		// the interval lies within the whitespace preceding the first or following
+		// the last declaration of the file
+		path = append(path, root)
+	}
+	return
+}
+
+// tokenNode is a dummy node standing for a syntax token, such as an operator, keyword or bracket
+type tokenNode struct {
+	pos tokens.Position
+	end tokens.Position
+}
+
+func (n tokenNode) Pos() tokens.Position { return n.pos }
+func (n tokenNode) End() tokens.Position { return n.end }
+
+// tok returns the node of the token of the given length at pos, or nil if pos is invalid
+func tok(pos tokens.Position, length int) ast.Node {
+	if !pos.IsValid() {
+		return nil
+	}
+	return tokenNode{pos, pos.Add(length)}
+}
+
+// childrenOf returns the direct children of n and its syntax tokens with known positions,
+// in lexical order. The nodes without a position, like the empty result list of
+// a function, are left out
+func childrenOf(n ast.Node) []ast.Node {
+	var children []ast.Node
+
+	// all the children of n, in the order of the fields
+	ast.Inspect(n, func(node ast.Node) bool {
+		if node == n {
+			return true
+		}
+		if node != nil {
+			children = append(children, node)
+		}
+		return false
+	})
+
+	// the tokens, which are not nodes
+	switch n := n.(type) {
+	case *ast.File:
+		children = append(children, tok(n.Package, len("package")))
+	case *ast.FieldList:
+		children = append(children, tok(n.Opening, 1), tok(n.Closing, 1))
+	case *ast.TypeSpec:
+		children = append(children, tok(n.AssignPos, 1))
+	case *ast.GenericDeclaration:
+		children = append(children, tok(n.TokPos, len(n.Token.String())), tok(n.LParenPos, 1), tok(n.RParenPos, 1))
+	case *ast.FunctionDeclaration:
+		// the name of the function is within the extent of its type, so
+		// the lists of the type become the children instead
+		children = []ast.Node{tok(n.Type.Func, len("func")), n.Name}
+		for _, list := range []*ast.FieldList{n.Type.TypeParams, n.Type.Params, n.Type.Results} {
+			if list != nil {
+				children = append(children, list)
+			}
+		}
+		if n.Body != nil {
+			children = append(children, n.Body)
+		}
+	case *ast.FunctionType:
+		children = append(children, tok(n.Func, len("func")))
+	case *ast.Ellipsis:
+		children = append(children, tok(n.Ellipsis, len("...")))
+	case *ast.CompositeLiteral:
+		children = append(children, tok(n.LbracePos, 1), tok(n.RbracePos, 1))
+	case *ast.ParenExpression:
+		children = append(children, tok(n.LParenPos, 1), tok(n.RParenPos, 1))
+	case *ast.IndexExpression:
+		children = append(children, tok(n.LBracketPos, 1), tok(n.RBracketPos, 1))
+	case *ast.IndexExpressions:
+		children = append(children, tok(n.Lbrack, 1), tok(n.Rbrack, 1))
+	case *ast.StarExpression:
+		children = append(children, tok(n.Star, 1))
+	case *ast.UnaryExpression:
+		children = append(children, tok(n.OpPos, len(n.Operator.String())))
+	case *ast.BinaryExpression:
+		children = append(children, tok(n.OpPos, len(n.Operator.String())))
+	case *ast.CallExpression:
+		children = append(children, tok(n.LParenPos, 1), tok(n.Ellipsis, len("...")), tok(n.RParenPos, 1))
+	case *ast.KeyValueExpression:
+		children = append(children, tok(n.ColonPos, 1))
+	case *ast.ArrayType:
+		children = append(children, tok(n.Lbrack, 1))
+	case *ast.StructType:
+		children = append(children, tok(n.Struct, len("struct")))
+	case *ast.InterfaceType:
+		children = append(children, tok(n.Interface, len("interface")))
+	case *ast.BlockStatement:
+		children = append(children, tok(n.LbracePos, 1), tok(n.RbracePos, 1))
+	case *ast.ReturnStatement:
+		children = append(children, tok(n.Return, len("return")))
+	case *ast.IfStatement:
+		children = append(children, tok(n.If, len("if")))
+	case *ast.ForStatement:
+		children = append(children, tok(n.For, len("for")))
+	case *ast.RangeStatement:
+		children = append(children, tok(n.For, len("for")))
+		if n.Key != nil {
+			children = append(children, tok(n.TokPos, len(n.Tok.Tok.String())))
+		}
+	case *ast.SwitchStatement:
+		children = append(children, tok(n.Switch, len("switch")))
+	case *ast.CaseClause:
+		keyword := len("case")
+		if n.List == nil {
+			keyword = len("default")
+		}
+		children = append(children, tok(n.Case, keyword), tok(n.Colon, 1))
+	case *ast.AssignStatement:
+		children = append(children, tok(n.TokPos, len(n.Tok.Tok.String())))
+	case *ast.IncDecStatement:
+		children = append(children, tok(n.TokPos, 2))
+	case *ast.BranchStatement:
+		children = append(children, tok(n.TokPos, len(n.Tok.Tok.String())))
+	case *ast.LabeledStatement:
+		children = append(children, tok(n.Colon, 1))
+	}
+
+	// drop the missing tokens and the nodes without a position
+	list := children[:0]
+	for _, child := range children {
+		if child != nil && child.Pos().IsValid() {
+			list = append(list, child)
+		}
+	}
+	children = list
+
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].Pos().Before(children[j].Pos())
+	})
+	return children
+}
@@ -0,0 +1,125 @@
+package astutil
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const input = `package p
+
+func f(x, y int) (z int) {
+	z = x + y // add them
+	if s := []int{1, 2}; len(s) > 1 {
+		return s[0]
+	}
+	return
+}
+`
+
+// describe returns the node types of path, with the operators, names and literals
+func describe(path []ast.Node) string {
+	var list []string
+	for _, n := range path {
+		s := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+		switch n := n.(type) {
+		case *ast.Ident:
+			s += "(" + n.Name + ")"
+		case *ast.BasicLiteral:
+			s += "(" + n.Value.Lit + ")"
+		case *ast.BinaryExpression:
+			s += "(" + n.Operator.String() + ")"
+		}
+		list = append(list, s)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestPathEnclosingInterval(t *testing.T) {
+	f := parse(t, input)
+	pos := func(line, column int) tokens.Position { return tokens.Position{Line: line, Column: column} }
+	for _, test := range []struct {
+		start, end tokens.Position
+		path       string
+		exact      bool
+	}{
+		// a single identifier
+		{pos(4, 6), pos(4, 7), "Ident(x) BinaryExpression(+) AssignStatement BlockStatement FunctionDeclaration File", true},
+		// an empty interval at an identifier
+		{pos(4, 10), pos(4, 10), "Ident(y) BinaryExpression(+) AssignStatement BlockStatement FunctionDeclaration File", true},
+		// the operator token
+		{pos(4, 8), pos(4, 9), "BinaryExpression(+) AssignStatement BlockStatement FunctionDeclaration File", true},
+		// the binary expression with the adjacent whitespace
+		{pos(4, 5), pos(4, 13), "BinaryExpression(+) AssignStatement BlockStatement FunctionDeclaration File", true},
+		// interior whitespace of the binary expression
+		{pos(4, 7), pos(4, 8), "BinaryExpression(+) AssignStatement BlockStatement FunctionDeclaration File", false},
+		// the assignment overlapping two of its children
+		{pos(4, 2), pos(4, 7), "AssignStatement BlockStatement FunctionDeclaration File", false},
+		// the function name and its parameter and result lists
+		{pos(3, 6), pos(3, 7), "Ident(f) FunctionDeclaration File", true},
+		{pos(3, 8), pos(3, 9), "Ident(x) Field FieldList FunctionType FunctionDeclaration File", true},
+		{pos(3, 19), pos(3, 20), "Ident(z) Field FieldList FunctionType FunctionDeclaration File", true},
+		{pos(3, 1), pos(3, 5), "FunctionDeclaration File", true},
+		// a literal in the header of the if statement and the index in its body
+		{pos(5, 16), pos(5, 17), "BasicLiteral(1) CompositeLiteral AssignStatement IfStatement BlockStatement FunctionDeclaration File", true},
+		{pos(5, 23), pos(5, 35), "IfStatement BlockStatement FunctionDeclaration File", false},
+		{pos(6, 12), pos(6, 13), "BasicLiteral(0) IndexExpression ReturnStatement BlockStatement IfStatement BlockStatement FunctionDeclaration File", true},
+		// the keyword of a statement
+		{pos(8, 2), pos(8, 8), "ReturnStatement BlockStatement FunctionDeclaration File", true},
+		// outside the declarations
+		{pos(11, 1), pos(11, 1), "File", false},
+	} {
+		path, exact := PathEnclosingInterval(f, test.start, test.end)
+		if got := describe(path); got != test.path || exact != test.exact {
+			t.Errorf("[%s, %s): expected %s (exact %t), got %s (exact %t)",
+				test.start.ToString(), test.end.ToString(), test.path, test.exact, got, exact)
+		}
+	}
+}
+
+// TestNodeExtents checks that every node of the parser inputs lies within its parent,
+// so that the path to the node is found from its extent. The type of a function
+// declaration is left out: it encloses the function name and is elided from paths
+func TestNodeExtents(t *testing.T) {
+	files, err := filepath.Glob("../tests/parser/input/*/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatal("no input files", err)
+	}
+	more, _ := filepath.Glob("../tests/printer/*.txt")
+	files = append(files, more...)
+	for _, name := range files {
+		if filepath.Base(filepath.Dir(name)) == "errors" {
+			continue
+		}
+		f, err := parser.ParseFile(tokens.NewFileSet(), name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		elided := map[ast.Node]bool{}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if decl, isDecl := n.(*ast.FunctionDeclaration); isDecl {
+				elided[decl.Type] = true
+			}
+			if n == nil || !n.Pos().IsValid() || elided[n] {
+				return n != nil
+			}
+			if n.End().Before(n.Pos()) {
+				t.Errorf("%s: %T ends at %s before its start %s", name, n, n.End().ToString(), n.Pos().ToString())
+				return true
+			}
+			path, exact := PathEnclosingInterval(f, n.Pos(), n.End())
+			found := false
+			for _, m := range path {
+				found = found || m == n
+			}
+			if !found || !exact {
+				t.Errorf("%s: %T at %s-%s not found, got %s", name, n, n.Pos().ToString(), n.End().ToString(), describe(path))
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package astutil
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/astprint"
+	"gocompiler/src/parser"
+	"gocompiler/src/tokens"
+	"testing"
+)
+
+func parse(t *testing.T, src string) *ast.File {
+	f, err := parser.ParseFile(tokens.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func check(t *testing.T, got ast.Node, expected string) {
+	t.Helper()
+	var nodes []ast.Node
+	for _, decl := range got.(*ast.File).Decls {
+		nodes = append(nodes, decl)
+	}
+	want := astprint.PrintAST(parseDecls(t, expected))
+	if result := astprint.PrintAST(nodes); result != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, result)
+	}
+}
+
+func parseDecls(t *testing.T, src string) (nodes []ast.Node) {
+	for _, decl := range parse(t, src).Decls {
+		nodes = append(nodes, decl)
+	}
+	return
+}
+
+func TestReplaceIncDec(t *testing.T) {
+	f := parse(t, "func f() {\n\tx++\n\tfor i := 0; i < 3; i-- {\n\t}\n}")
+	result := Apply(f, nil, func(c *Cursor) bool {
+		if s, isIncDec := c.Node().(*ast.IncDecStatement); isIncDec {
+			op := tokens.ADD_ASSIGN
+			if s.Tok.Tok == tokens.DEC {
+				op = tokens.SUB_ASSIGN
+			}
+			one := &ast.BasicLiteral{ValuePos: s.TokPos, Type: tokens.INT, Value: tokens.Token{Tok: tokens.INT, Lex: uint64(1), Lit: "1"}}
+			c.Replace(&ast.AssignStatement{Lhs: []ast.Expression{s.X}, TokPos: s.TokPos, Tok: tokens.Token{Tok: op, Lex: op.String()}, Rhs: []ast.Expression{one}})
+		}
+		return true
+	})
+	check(t, result, "func f() {\n\tx += 1\n\tfor i := 0; i < 3; i -= 1 {\n\t}\n}")
+}
+
+func TestInsertAndDelete(t *testing.T) {
+	f := parse(t, "func f() {\n\ta()\n\tb()\n\tc()\n}")
+	var visited []string
+	Apply(f, func(c *Cursor) bool {
+		call, isCall := c.Node().(*ast.CallExpression)
+		if !isCall {
+			return true
+		}
+		visited = append(visited, call.Function.(*ast.Ident).Name)
+		return true
+	}, func(c *Cursor) bool {
+		stmt, isStmt := c.Node().(*ast.ExpressionStatement)
+		if !isStmt {
+			return true
+		}
+		switch stmt.X.(*ast.CallExpression).Function.(*ast.Ident).Name {
+		case "a":
+			c.InsertBefore(&ast.ExpressionStatement{X: &ast.CallExpression{Function: &ast.Ident{Name: "enter"}}})
+		case "b":
+			c.Delete()
+		case "c":
+			c.InsertAfter(&ast.ExpressionStatement{X: &ast.CallExpression{Function: &ast.Ident{Name: "exit"}}})
+		}
+		return true
+	})
+	check(t, f, "func f() {\n\tenter()\n\ta()\n\tc()\n\texit()\n}")
+	if len(visited) != 3 {
+		t.Errorf("inserted nodes must not be walked, visited %v", visited)
+	}
+}
+
+func TestReplaceExpression(t *testing.T) {
+	f := parse(t, "var x = old(1) + old(old(2))")
+	Apply(f, nil, func(c *Cursor) bool {
+		if call, isCall := c.Node().(*ast.CallExpression); isCall {
+			c.Replace(&ast.BinaryExpression{Operator: tokens.MUL, LeftX: call.Arguments[0], RightX: &ast.Ident{Name: "k"}})
+		}
+		return true
+	})
+	check(t, f, "var x = 1*k + 2*k*k")
+}
+
+func TestAbort(t *testing.T) {
+	f := parse(t, "var a, b, c = 1, 2, 3")
+	count := 0
+	result := Apply(f, nil, func(c *Cursor) bool {
+		if _, isLit := c.Node().(*ast.BasicLiteral); isLit {
+			count++
+			return count < 2
+		}
+		return true
+	})
+	if count != 2 || result != f {
+		t.Errorf("expected traversal to stop at the second literal, visited %d", count)
+	}
+}
@@ -0,0 +1,831 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/constant to the tokens of this module.
+
+// Package constant implements the values of Go constants and the operations on them:
+// exact integers, rationals and floats of arbitrary precision, complex numbers, booleans
+// and strings
+package constant
+
+import (
+	"fmt"
+	"gocompiler/src/tokens"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the kind of a constant value
+type Kind int
+
+const (
+	Unknown Kind = iota // the value of an invalid constant
+	Bool
+	String
+	Int
+	Float
+	Complex
+)
+
+var kindNames = [...]string{
+	Unknown: "Unknown",
+	Bool:    "Bool",
+	String:  "String",
+	Int:     "Int",
+	Float:   "Float",
+	Complex: "Complex",
+}
+
+func (k Kind) String() string {
+	if 0 <= k && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Value is a constant value; the values are immutable
+type Value interface {
+	Kind() Kind
+
+	// String returns a short form of the value, with floats rounded to 6 digits and
+	// long strings shortened
+	String() string
+
+	// ExactString returns the exact form of the value, fractions as a/b
+	ExactString() string
+
+	implementsValue()
+}
+
+const (
+	// prec is the precision in bits of the float values
+	prec = 512
+
+	// maxExp is the largest binary exponent of the numerators, denominators and floats
+	// represented by rationals; the values beyond it are floats
+	maxExp = 4 << 10
+)
+
+type (
+	unknownVal struct{}
+	boolVal    bool
+	stringVal  string
+	intVal     struct{ val *big.Int }   // any integer
+	ratVal     struct{ val *big.Rat }   // a fraction with a small numerator and denominator
+	floatVal   struct{ val *big.Float } // a float too large or too small for a ratVal
+	complexVal struct{ re, im Value }   // re and im are intVal, ratVal or floatVal
+)
+
+func (unknownVal) Kind() Kind { return Unknown }
+func (boolVal) Kind() Kind    { return Bool }
+func (stringVal) Kind() Kind  { return String }
+func (intVal) Kind() Kind     { return Int }
+func (ratVal) Kind() Kind     { return Float }
+func (floatVal) Kind() Kind   { return Float }
+func (complexVal) Kind() Kind { return Complex }
+
+func (unknownVal) String() string { return "unknown" }
+func (x boolVal) String() string  { return strconv.FormatBool(bool(x)) }
+
+// String returns the quoted string, shortened to 72 characters with "..."
+func (x stringVal) String() string {
+	const maxLen = 72
+	s := strconv.Quote(string(x))
+	if utf8.RuneCountInString(s) > maxLen {
+		i := 0
+		for n := 0; n < maxLen-3; n++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		s = s[:i] + "..."
+	}
+	return s
+}
+
+func (x intVal) String() string { return x.val.String() }
+func (x ratVal) String() string { return r2f(x).String() }
+
+// String formats the float with 6 significant digits; the floats beyond the range of
+// float64 are converted to a decimal mantissa and exponent approximately
+func (x floatVal) String() string {
+	f := x.val
+	if f.IsInf() {
+		return f.String()
+	}
+	if x, _ := f.Float64(); (f.Sign() == 0) == (x == 0) && !math.IsInf(x, 0) {
+		s := fmt.Sprintf("%.6g", x)
+		if !f.IsInt() && strings.IndexByte(s, '.') < 0 {
+			// a fraction must not look like an integer
+			s = fmt.Sprintf("%g", x)
+		}
+		return s
+	}
+
+	// f = mant * 2**exp with 0.5 <= |mant| < 1, approximated as m * 10**e
+	var mant big.Float
+	exp := f.MantExp(&mant)
+	m, _ := mant.Float64()
+	d := float64(exp) * (math.Ln2 / math.Ln10)
+	e := int64(d)
+	m *= math.Pow(10, d-float64(e))
+	switch am := math.Abs(m); {
+	case am < 1-0.5e-6:
+		m *= 10
+		e--
+	case am >= 10:
+		m /= 10
+		e++
+	}
+	return fmt.Sprintf("%.6ge%+d", m, e)
+}
+
+func (x complexVal) String() string { return fmt.Sprintf("(%s + %si)", x.re, x.im) }
+
+func (x unknownVal) ExactString() string { return x.String() }
+func (x boolVal) ExactString() string    { return x.String() }
+func (x stringVal) ExactString() string  { return strconv.Quote(string(x)) }
+func (x intVal) ExactString() string     { return x.String() }
+
+func (x ratVal) ExactString() string {
+	if x.val.IsInt() {
+		return x.val.Num().String()
+	}
+	return x.val.String()
+}
+
+func (x floatVal) ExactString() string { return x.val.Text('p', 0) }
+
+func (x complexVal) ExactString() string {
+	return fmt.Sprintf("(%s + %si)", x.re.ExactString(), x.im.ExactString())
+}
+
+func (unknownVal) implementsValue() {}
+func (boolVal) implementsValue()    {}
+func (stringVal) implementsValue()  {}
+func (intVal) implementsValue()     {}
+func (ratVal) implementsValue()     {}
+func (floatVal) implementsValue()   {}
+func (complexVal) implementsValue() {}
+
+func newInt() *big.Int     { return new(big.Int) }
+func newRat() *big.Rat     { return new(big.Rat) }
+func newFloat() *big.Float { return new(big.Float).SetPrec(prec) }
+
+func i2r(x intVal) ratVal     { return ratVal{newRat().SetInt(x.val)} }
+func i2f(x intVal) floatVal   { return floatVal{newFloat().SetInt(x.val)} }
+func r2f(x ratVal) floatVal   { return floatVal{newFloat().SetRat(x.val)} }
+func vtoc(x Value) complexVal { return complexVal{x, intVal{newInt()}} }
+
+func makeInt(x *big.Int) Value { return intVal{x} }
+
+// makeRat returns x as a ratVal if its numerator and denominator are small, as a floatVal otherwise
+func makeRat(x *big.Rat) Value {
+	if smallInt(x.Num()) && smallInt(x.Denom()) {
+		return ratVal{x}
+	}
+	return floatVal{newFloat().SetRat(x)}
+}
+
+// makeFloat returns x as a floatVal, or an unknown value for an infinity
+func makeFloat(x *big.Float) Value {
+	if x.Sign() == 0 {
+		return ratVal{newRat()}
+	}
+	if x.IsInf() {
+		return unknownVal{}
+	}
+	return floatVal{x}
+}
+
+func makeComplex(re, im Value) Value {
+	if re.Kind() == Unknown || im.Kind() == Unknown {
+		return unknownVal{}
+	}
+	return complexVal{re, im}
+}
+
+func smallInt(x *big.Int) bool { return x.BitLen() < maxExp }
+
+func smallFloat(x *big.Float) bool {
+	if x.IsInf() {
+		return false
+	}
+	e := x.MantExp(nil)
+	return -maxExp < e && e < maxExp
+}
+
+// MakeUnknown returns the value of an invalid constant
+func MakeUnknown() Value { return unknownVal{} }
+
+// MakeBool returns the boolean value b
+func MakeBool(b bool) Value { return boolVal(b) }
+
+// MakeString returns the string value s
+func MakeString(s string) Value { return stringVal(s) }
+
+// MakeInt64 returns the integer value x
+func MakeInt64(x int64) Value { return intVal{big.NewInt(x)} }
+
+// MakeUint64 returns the integer value x
+func MakeUint64(x uint64) Value { return intVal{newInt().SetUint64(x)} }
+
+// MakeFloat64 returns the float value x, or an unknown value if x is not finite
+func MakeFloat64(x float64) Value {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return unknownVal{}
+	}
+	return ratVal{newRat().SetFloat64(x)}
+}
+
+// MakeFromLiteral returns the value of the literal lit of the kind tok, an INT, FLOAT, IMAG,
+// CHAR or STRING, or an unknown value if lit is not a valid literal
+func MakeFromLiteral(lit string, tok tokens.TokenType) Value {
+	switch tok {
+	case tokens.INT:
+		if x, ok := newInt().SetString(lit, 0); ok {
+			return makeInt(x)
+		}
+
+	case tokens.FLOAT:
+		if x := makeFloatFromLiteral(lit); x != nil {
+			return x
+		}
+
+	case tokens.IMAG:
+		if n := len(lit) - 1; n > 0 && lit[n] == 'i' {
+			if im := makeFloatFromLiteral(lit[:n]); im != nil {
+				return makeComplex(intVal{newInt()}, im)
+			}
+		}
+
+	case tokens.CHAR:
+		if n := len(lit); n >= 2 {
+			if r, _, tail, err := strconv.UnquoteChar(lit[1:n-1], '\''); err == nil && tail == "" {
+				return MakeInt64(int64(r))
+			}
+		}
+
+	case tokens.STRING:
+		if strings.HasPrefix(lit, "`") {
+			// carriage returns are discarded from raw strings
+			lit = strings.ReplaceAll(lit, "\r", "")
+		}
+		if s, err := strconv.Unquote(lit); err == nil {
+			return MakeString(s)
+		}
+	}
+	return unknownVal{}
+}
+
+// makeFloatFromLiteral returns the value of a float or integer literal, or nil
+func makeFloatFromLiteral(lit string) Value {
+	lit = strings.ReplaceAll(lit, "_", "")
+	f, ok := newFloat().SetString(lit)
+	if !ok {
+		return nil
+	}
+	if smallFloat(f) {
+		if f.Sign() == 0 {
+			// a tiny value underflows to zero and takes long to parse as a rational
+			lit = "0"
+		}
+		if r, ok := newRat().SetString(lit); ok {
+			return ratVal{r}
+		}
+	}
+	return makeFloat(f)
+}
+
+// BoolVal returns the value of a Bool or Unknown x; an unknown value is false
+func BoolVal(x Value) bool {
+	switch x := x.(type) {
+	case boolVal:
+		return bool(x)
+	case unknownVal:
+		return false
+	}
+	panic(fmt.Sprintf("%v not a Bool", x))
+}
+
+// StringVal returns the value of a String or Unknown x; an unknown value is ""
+func StringVal(x Value) string {
+	switch x := x.(type) {
+	case stringVal:
+		return string(x)
+	case unknownVal:
+		return ""
+	}
+	panic(fmt.Sprintf("%v not a String", x))
+}
+
+// Int64Val returns the value of an Int or Unknown x and whether it is exact
+func Int64Val(x Value) (int64, bool) {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.Int64(), x.val.IsInt64()
+	case unknownVal:
+		return 0, false
+	}
+	panic(fmt.Sprintf("%v not an Int", x))
+}
+
+// Uint64Val returns the value of an Int or Unknown x and whether it is exact
+func Uint64Val(x Value) (uint64, bool) {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.Uint64(), x.val.IsUint64()
+	case unknownVal:
+		return 0, false
+	}
+	panic(fmt.Sprintf("%v not an Int", x))
+}
+
+// Float32Val is like Float64Val but for float32
+func Float32Val(x Value) (float32, bool) {
+	switch x := x.(type) {
+	case intVal:
+		f, acc := newFloat().SetInt(x.val).Float32()
+		return f, acc == big.Exact
+	case ratVal:
+		return x.val.Float32()
+	case floatVal:
+		f, acc := x.val.Float32()
+		return f, acc == big.Exact
+	case unknownVal:
+		return 0, false
+	}
+	panic(fmt.Sprintf("%v not a Float", x))
+}
+
+// Float64Val returns the nearest float64 to the value of an Int, Float or Unknown x and
+// whether it is exact; a value beyond the range of float64 gives an infinity
+func Float64Val(x Value) (float64, bool) {
+	switch x := x.(type) {
+	case intVal:
+		f, acc := newFloat().SetInt(x.val).Float64()
+		return f, acc == big.Exact
+	case ratVal:
+		return x.val.Float64()
+	case floatVal:
+		f, acc := x.val.Float64()
+		return f, acc == big.Exact
+	case unknownVal:
+		return 0, false
+	}
+	panic(fmt.Sprintf("%v not a Float", x))
+}
+
+// BitLen returns the number of bits of the absolute value of an Int or Unknown x
+func BitLen(x Value) int {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.BitLen()
+	case unknownVal:
+		return 0
+	}
+	panic(fmt.Sprintf("%v not an Int", x))
+}
+
+// Sign returns -1, 0 or 1 for a numeric x less than, equal to or greater than zero;
+// a complex x is 0 only if both parts are, and an unknown x gives 1
+func Sign(x Value) int {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.Sign()
+	case ratVal:
+		return x.val.Sign()
+	case floatVal:
+		return x.val.Sign()
+	case complexVal:
+		return Sign(x.re) | Sign(x.im)
+	case unknownVal:
+		return 1 // avoid follow-up errors like division by zero
+	}
+	panic(fmt.Sprintf("%v not numeric", x))
+}
+
+// MakeImag returns the complex value x*i of an Int, Float or Unknown x
+func MakeImag(x Value) Value {
+	switch x.(type) {
+	case unknownVal:
+		return x
+	case intVal, ratVal, floatVal:
+		return makeComplex(intVal{newInt()}, x)
+	}
+	panic(fmt.Sprintf("%v not Int or Float", x))
+}
+
+// Real returns the real part of a numeric or unknown x
+func Real(x Value) Value {
+	switch x := x.(type) {
+	case unknownVal, intVal, ratVal, floatVal:
+		return x
+	case complexVal:
+		return x.re
+	}
+	panic(fmt.Sprintf("%v not numeric", x))
+}
+
+// Imag returns the imaginary part of a numeric or unknown x
+func Imag(x Value) Value {
+	switch x := x.(type) {
+	case unknownVal:
+		return x
+	case intVal, ratVal, floatVal:
+		return intVal{newInt()}
+	case complexVal:
+		return x.im
+	}
+	panic(fmt.Sprintf("%v not numeric", x))
+}
+
+// ToInt returns x as an Int if it is an integer, or a float so close to one that the
+// difference is a rounding error; otherwise the result is unknown
+func ToInt(x Value) Value {
+	switch x := x.(type) {
+	case intVal:
+		return x
+	case ratVal:
+		if x.val.IsInt() {
+			return makeInt(x.val.Num())
+		}
+	case floatVal:
+		if smallFloat(x.val) {
+			i := newInt()
+			if _, acc := x.val.Int(i); acc == big.Exact {
+				return makeInt(i)
+			}
+			// rounding the last bits of the mantissa up or down may give an integer
+			const delta = 4
+			var t big.Float
+			t.SetPrec(prec - delta)
+			t.SetMode(big.ToZero)
+			t.Set(x.val)
+			if _, acc := t.Int(i); acc == big.Exact {
+				return makeInt(i)
+			}
+			t.SetMode(big.AwayFromZero)
+			t.Set(x.val)
+			if _, acc := t.Int(i); acc == big.Exact {
+				return makeInt(i)
+			}
+		}
+	case complexVal:
+		if re := ToFloat(x); re.Kind() == Float {
+			return ToInt(re)
+		}
+	}
+	return unknownVal{}
+}
+
+// ToFloat returns x as a Float if it is an integer, a float or a complex number without an
+// imaginary part; otherwise the result is unknown
+func ToFloat(x Value) Value {
+	switch x := x.(type) {
+	case intVal:
+		if smallInt(x.val) {
+			return i2r(x)
+		}
+		return i2f(x)
+	case ratVal, floatVal:
+		return x
+	case complexVal:
+		if Sign(x.im) == 0 {
+			return ToFloat(x.re)
+		}
+	}
+	return unknownVal{}
+}
+
+// ToComplex returns x as a Complex if it is numeric; otherwise the result is unknown
+func ToComplex(x Value) Value {
+	switch x := x.(type) {
+	case intVal, ratVal, floatVal:
+		return vtoc(x)
+	case complexVal:
+		return x
+	}
+	return unknownVal{}
+}
+
+// ord orders the numeric representations; an operation on two values converts the lower
+// one to the representation of the higher one
+func ord(x Value) int {
+	switch x.(type) {
+	case intVal:
+		return 2
+	case ratVal:
+		return 3
+	case floatVal:
+		return 4
+	case complexVal:
+		return 5
+	case unknownVal:
+		return 0
+	}
+	return 1 // bool and string
+}
+
+// match returns x and y in the same representation
+func match(x, y Value) (Value, Value) {
+	switch ox, oy := ord(x), ord(y); {
+	case ox < oy:
+		x, y = match0(x, y)
+	case ox > oy:
+		y, x = match0(y, x)
+	}
+	return x, y
+}
+
+// match0 converts x to the representation of y; ord(x) < ord(y)
+func match0(x, y Value) (Value, Value) {
+	switch y.(type) {
+	case ratVal:
+		if x, isInt := x.(intVal); isInt {
+			return i2r(x), y
+		}
+	case floatVal:
+		switch x := x.(type) {
+		case intVal:
+			return i2f(x), y
+		case ratVal:
+			return r2f(x), y
+		}
+	case complexVal:
+		switch x.(type) {
+		case intVal, ratVal, floatVal:
+			return vtoc(x), y
+		}
+	}
+	// x is unknown, or the kinds cannot be matched
+	return x, x
+}
+
+// UnaryOp returns the result of the unary expression op y, with op ADD, SUB, XOR or NOT.
+// The result of ^ on an unsigned integer type of prec bits keeps prec bits; prec is 0 for
+// the other types
+func UnaryOp(op tokens.TokenType, y Value, prec uint) Value {
+	switch op {
+	case tokens.ADD:
+		switch y.(type) {
+		case unknownVal, intVal, ratVal, floatVal, complexVal:
+			return y
+		}
+
+	case tokens.SUB:
+		switch y := y.(type) {
+		case unknownVal:
+			return y
+		case intVal:
+			return makeInt(newInt().Neg(y.val))
+		case ratVal:
+			return makeRat(newRat().Neg(y.val))
+		case floatVal:
+			return makeFloat(newFloat().Neg(y.val))
+		case complexVal:
+			return makeComplex(UnaryOp(tokens.SUB, y.re, 0), UnaryOp(tokens.SUB, y.im, 0))
+		}
+
+	case tokens.XOR:
+		z := newInt()
+		switch y := y.(type) {
+		case unknownVal:
+			return y
+		case intVal:
+			z.Not(y.val)
+		default:
+			goto Error
+		}
+		if prec > 0 {
+			// z &^= -1 << prec
+			z.AndNot(z, newInt().Lsh(big.NewInt(-1), prec))
+		}
+		return makeInt(z)
+
+	case tokens.NOT:
+		switch y := y.(type) {
+		case unknownVal:
+			return y
+		case boolVal:
+			return !y
+		}
+	}
+
+Error:
+	panic(fmt.Sprintf("invalid unary operation %s%v", op, y))
+}
+
+// BinaryOp returns the result of the binary expression x op y. The division of integers
+// gives a rational; QUO_ASSIGN selects the truncated integer division instead. The
+// comparisons are made by Compare and the shifts by Shift
+func BinaryOp(x_ Value, op tokens.TokenType, y_ Value) Value {
+	x, y := match(x_, y_)
+
+	switch x := x.(type) {
+	case unknownVal:
+		return x
+
+	case boolVal:
+		y := y.(boolVal)
+		switch op {
+		case tokens.LAND:
+			return x && y
+		case tokens.LOR:
+			return x || y
+		}
+
+	case intVal:
+		a := x.val
+		b := y.(intVal).val
+		c := newInt()
+		switch op {
+		case tokens.ADD:
+			c.Add(a, b)
+		case tokens.SUB:
+			c.Sub(a, b)
+		case tokens.MUL:
+			c.Mul(a, b)
+		case tokens.QUO:
+			return makeRat(newRat().SetFrac(a, b))
+		case tokens.QUO_ASSIGN:
+			c.Quo(a, b)
+		case tokens.REM:
+			c.Rem(a, b)
+		case tokens.AND:
+			c.And(a, b)
+		case tokens.OR:
+			c.Or(a, b)
+		case tokens.XOR:
+			c.Xor(a, b)
+		case tokens.AND_NOT:
+			c.AndNot(a, b)
+		default:
+			goto Error
+		}
+		return makeInt(c)
+
+	case ratVal:
+		a := x.val
+		b := y.(ratVal).val
+		c := newRat()
+		switch op {
+		case tokens.ADD:
+			c.Add(a, b)
+		case tokens.SUB:
+			c.Sub(a, b)
+		case tokens.MUL:
+			c.Mul(a, b)
+		case tokens.QUO:
+			c.Quo(a, b)
+		default:
+			goto Error
+		}
+		return makeRat(c)
+
+	case floatVal:
+		a := x.val
+		b := y.(floatVal).val
+		c := newFloat()
+		switch op {
+		case tokens.ADD:
+			c.Add(a, b)
+		case tokens.SUB:
+			c.Sub(a, b)
+		case tokens.MUL:
+			c.Mul(a, b)
+		case tokens.QUO:
+			c.Quo(a, b)
+		default:
+			goto Error
+		}
+		return makeFloat(c)
+
+	case complexVal:
+		y := y.(complexVal)
+		a, b := x.re, x.im
+		c, d := y.re, y.im
+		var re, im Value
+		switch op {
+		case tokens.ADD:
+			// (a+c) + i(b+d)
+			re = add(a, c)
+			im = add(b, d)
+		case tokens.SUB:
+			// (a-c) + i(b-d)
+			re = sub(a, c)
+			im = sub(b, d)
+		case tokens.MUL:
+			// (ac-bd) + i(bc+ad)
+			re = sub(mul(a, c), mul(b, d))
+			im = add(mul(b, c), mul(a, d))
+		case tokens.QUO:
+			// ((ac+bd) + i(bc-ad)) / (cc+dd)
+			s := add(mul(c, c), mul(d, d))
+			re = quo(add(mul(a, c), mul(b, d)), s)
+			im = quo(sub(mul(b, c), mul(a, d)), s)
+		default:
+			goto Error
+		}
+		return makeComplex(re, im)
+
+	case stringVal:
+		if op == tokens.ADD {
+			return x + y.(stringVal)
+		}
+	}
+
+Error:
+	panic(fmt.Sprintf("invalid binary operation %v %s %v", x_, op, y_))
+}
+
+func add(x, y Value) Value { return BinaryOp(x, tokens.ADD, y) }
+func sub(x, y Value) Value { return BinaryOp(x, tokens.SUB, y) }
+func mul(x, y Value) Value { return BinaryOp(x, tokens.MUL, y) }
+func quo(x, y Value) Value { return BinaryOp(x, tokens.QUO, y) }
+
+// Shift returns the result of the shift x op s of an Int or Unknown x, with op SHL or SHR
+func Shift(x Value, op tokens.TokenType, s uint) Value {
+	switch x := x.(type) {
+	case unknownVal:
+		return x
+	case intVal:
+		if s == 0 {
+			return x
+		}
+		switch op {
+		case tokens.SHL:
+			return makeInt(newInt().Lsh(x.val, s))
+		case tokens.SHR:
+			return makeInt(newInt().Rsh(x.val, s))
+		}
+	}
+	panic(fmt.Sprintf("invalid shift %v %s %d", x, op, s))
+}
+
+// cmpZero reports whether x op 0 holds
+func cmpZero(x int, op tokens.TokenType) bool {
+	switch op {
+	case tokens.EQL:
+		return x == 0
+	case tokens.NEQ:
+		return x != 0
+	case tokens.LSS:
+		return x < 0
+	case tokens.LEQ:
+		return x <= 0
+	case tokens.GTR:
+		return x > 0
+	case tokens.GEQ:
+		return x >= 0
+	}
+	panic(fmt.Sprintf("invalid comparison %s", op))
+}
+
+// Compare returns the result of the comparison x op y; a comparison with an unknown
+// value is false
+func Compare(x_ Value, op tokens.TokenType, y_ Value) bool {
+	x, y := match(x_, y_)
+
+	switch x := x.(type) {
+	case unknownVal:
+		return false
+
+	case boolVal:
+		y := y.(boolVal)
+		switch op {
+		case tokens.EQL:
+			return x == y
+		case tokens.NEQ:
+			return x != y
+		}
+
+	case intVal:
+		return cmpZero(x.val.Cmp(y.(intVal).val), op)
+
+	case ratVal:
+		return cmpZero(x.val.Cmp(y.(ratVal).val), op)
+
+	case floatVal:
+		return cmpZero(x.val.Cmp(y.(floatVal).val), op)
+
+	case complexVal:
+		y := y.(complexVal)
+		re := Compare(x.re, tokens.EQL, y.re)
+		im := Compare(x.im, tokens.EQL, y.im)
+		switch op {
+		case tokens.EQL:
+			return re && im
+		case tokens.NEQ:
+			return !re || !im
+		}
+
+	case stringVal:
+		return cmpZero(strings.Compare(string(x), string(y.(stringVal))), op)
+	}
+
+	panic(fmt.Sprintf("invalid comparison %v %s %v", x_, op, y_))
+}
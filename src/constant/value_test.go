@@ -0,0 +1,144 @@
+package constant_test
+
+import (
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+	"testing"
+)
+
+func TestLiterals(t *testing.T) {
+	for _, test := range []struct {
+		lit   string
+		tok   tokens.TokenType
+		kind  constant.Kind
+		exact string
+	}{
+		{"0", tokens.INT, constant.Int, "0"},
+		{"1_000_000", tokens.INT, constant.Int, "1000000"},
+		{"0x_ff", tokens.INT, constant.Int, "255"},
+		{"0o17", tokens.INT, constant.Int, "15"},
+		{"017", tokens.INT, constant.Int, "15"},
+		{"0b101", tokens.INT, constant.Int, "5"},
+		{"123456789012345678901234567890", tokens.INT, constant.Int, "123456789012345678901234567890"},
+		{"1.5", tokens.FLOAT, constant.Float, "3/2"},
+		{"1e3", tokens.FLOAT, constant.Float, "1000"},
+		{"0x1p-2", tokens.FLOAT, constant.Float, "1/4"},
+		{"1_0.2_5", tokens.FLOAT, constant.Float, "41/4"},
+		{"2i", tokens.IMAG, constant.Complex, "(0 + 2i)"},
+		{"1.5i", tokens.IMAG, constant.Complex, "(0 + 3/2i)"},
+		{"'a'", tokens.CHAR, constant.Int, "97"},
+		{`'\n'`, tokens.CHAR, constant.Int, "10"},
+		{`'\xff'`, tokens.CHAR, constant.Int, "255"},
+		{`'本'`, tokens.CHAR, constant.Int, "26412"},
+		{`"a\tb"`, tokens.STRING, constant.String, `"a\tb"`},
+		{"`a\\tb`", tokens.STRING, constant.String, `"a\\tb"`},
+		{"1x", tokens.INT, constant.Unknown, "unknown"},
+	} {
+		x := constant.MakeFromLiteral(test.lit, test.tok)
+		if x.Kind() != test.kind || x.ExactString() != test.exact {
+			t.Errorf("%s: expected %s %s, got %s %s", test.lit, test.kind, test.exact, x.Kind(), x.ExactString())
+		}
+	}
+}
+
+func val(lit string) constant.Value {
+	switch {
+	case lit[len(lit)-1] == 'i':
+		return constant.MakeFromLiteral(lit, tokens.IMAG)
+	case lit[0] == '"':
+		return constant.MakeFromLiteral(lit, tokens.STRING)
+	case lit == "true" || lit == "false":
+		return constant.MakeBool(lit == "true")
+	}
+	for _, c := range lit {
+		if c == '.' || c == 'e' {
+			return constant.MakeFromLiteral(lit, tokens.FLOAT)
+		}
+	}
+	return constant.MakeFromLiteral(lit, tokens.INT)
+}
+
+func TestOperations(t *testing.T) {
+	for _, test := range []struct {
+		x   string
+		op  tokens.TokenType
+		y   string
+		res string
+	}{
+		{"1", tokens.ADD, "2", "3"},
+		{"1", tokens.SUB, "2", "-1"},
+		{"6", tokens.MUL, "7", "42"},
+		{"7", tokens.QUO, "2", "3.5"},
+		{"7", tokens.QUO_ASSIGN, "2", "3"},
+		{"-7", tokens.QUO_ASSIGN, "2", "-3"},
+		{"-7", tokens.REM, "2", "-1"},
+		{"12", tokens.AND, "10", "8"},
+		{"12", tokens.OR, "10", "14"},
+		{"12", tokens.XOR, "10", "6"},
+		{"12", tokens.AND_NOT, "10", "4"},
+		{"1", tokens.ADD, "0.5", "1.5"},
+		{"0.1", tokens.ADD, "0.2", "0.3"},
+		{"1", tokens.QUO, "3", "0.333333"},
+		{"1e1000", tokens.MUL, "1e1000", "1e+2000"},
+		{"1", tokens.ADD, "2i", "(1 + 2i)"},
+		{"1i", tokens.MUL, "1i", "(-1 + 0i)"},
+		{"1i", tokens.QUO, "2i", "(0.5 + 0i)"},
+		{`"a"`, tokens.ADD, `"b"`, `"ab"`},
+		{"true", tokens.LAND, "false", "false"},
+		{"true", tokens.LOR, "false", "true"},
+	} {
+		res := constant.BinaryOp(val(test.x), test.op, val(test.y))
+		if res.String() != test.res {
+			t.Errorf("%s %s %s: expected %s, got %s", test.x, test.op, test.y, test.res, res)
+		}
+	}
+}
+
+func TestShiftAndCompare(t *testing.T) {
+	big := constant.Shift(constant.MakeInt64(1), tokens.SHL, 100)
+	if big.String() != "1267650600228229401496703205376" || constant.BitLen(big) != 101 {
+		t.Errorf("1 << 100 = %s", big)
+	}
+	if small := constant.Shift(big, tokens.SHR, 98); small.String() != "4" {
+		t.Errorf("1 << 100 >> 98 = %s", small)
+	}
+	if _, exact := constant.Int64Val(big); exact {
+		t.Errorf("1 << 100 must not fit in an int64")
+	}
+	if !constant.Compare(val("1"), tokens.LSS, val("1.5")) || constant.Compare(val("2"), tokens.EQL, val("2.5")) {
+		t.Errorf("mixed comparisons failed")
+	}
+	if !constant.Compare(val(`"a"`), tokens.LSS, val(`"b"`)) || !constant.Compare(val("1i"), tokens.NEQ, val("2i")) {
+		t.Errorf("string or complex comparisons failed")
+	}
+	if constant.Compare(constant.MakeUnknown(), tokens.EQL, val("1")) {
+		t.Errorf("unknown values must not compare equal")
+	}
+}
+
+func TestUnaryAndConversions(t *testing.T) {
+	if x := constant.UnaryOp(tokens.XOR, constant.MakeInt64(1), 8); x.String() != "254" {
+		t.Errorf("^uint8(1) = %s", x)
+	}
+	if x := constant.UnaryOp(tokens.XOR, constant.MakeInt64(1), 0); x.String() != "-2" {
+		t.Errorf("^1 = %s", x)
+	}
+	if x := constant.UnaryOp(tokens.SUB, val("2i"), 0); x.String() != "(0 + -2i)" {
+		t.Errorf("-2i = %s", x)
+	}
+	if x := constant.ToInt(val("3.0")); x.Kind() != constant.Int || x.String() != "3" {
+		t.Errorf("ToInt(3.0) = %s", x)
+	}
+	if x := constant.ToInt(val("3.5")); x.Kind() != constant.Unknown {
+		t.Errorf("ToInt(3.5) = %s", x)
+	}
+	if x := constant.ToFloat(constant.BinaryOp(val("1"), tokens.ADD, val("0i"))); x.Kind() != constant.Float {
+		t.Errorf("ToFloat(1 + 0i) = %s", x)
+	}
+	if f, exact := constant.Float64Val(val("0.1")); f != 0.1 || exact {
+		t.Errorf("Float64Val(0.1) = %v, %v", f, exact)
+	}
+	if x := constant.Real(val("2i")); x.String() != "0" {
+		t.Errorf("real(2i) = %s", x)
+	}
+}
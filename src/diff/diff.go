@@ -0,0 +1,230 @@
+// Package diff computes the line differences of two texts and prints them in the unified format
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const context = 3 // number of unchanged lines around the changes of a hunk
+
+// an edit is a line of the unified format: an unchanged, deleted or inserted line
+type edit struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// Unified returns the differences of old and new in the unified format, or nil if the texts are equal
+func Unified(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	edits := lineEdits(splitLines(old), splitLines(new))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "diff %s %s\n", oldName, newName)
+	fmt.Fprintf(&out, "--- %s\n", oldName)
+	fmt.Fprintf(&out, "+++ %s\n", newName)
+
+	// oldLine[i] and newLine[i] are the numbers of the lines before edits[i]
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.kind != '+' {
+			oldLine[i+1]++
+		}
+		if e.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+		// a hunk starts with the context of the change at i and extends
+		// over the changes separated by less than twice the context
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits) && j <= end+2*context+1; j++ {
+			if edits[j].kind != ' ' {
+				end = j
+			}
+		}
+		end += context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start]))
+		for _, e := range edits[start:end] {
+			out.WriteByte(e.kind)
+			out.WriteString(e.text)
+			if !strings.HasSuffix(e.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+// hunkRange returns the range of n lines after line start in a hunk header
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// splitLines splits text after each newline
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEdits returns a shortest edit script turning the lines a into the lines b, computed with the
+// linear space variant of the algorithm of E. Myers, "An O(ND) Difference Algorithm and Its Variations".
+// Within a run of changes the deletions come before the insertions, as in the output of diff -u
+func lineEdits(a, b []string) []edit {
+	var edits []edit
+	compare(&edits, a, b)
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(edits) && edits[j].kind != ' ' {
+			j++
+		}
+		run := edits[i:j]
+		sort.SliceStable(run, func(x, y int) bool { return run[x].kind == '-' && run[y].kind == '+' })
+		i = j
+	}
+	return edits
+}
+
+// compare appends to edits the script turning a into b, divided at a point of a shortest path
+// found by bisect
+func compare(edits *[]edit, a, b []string) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, line := range a[:prefix] {
+		*edits = append(*edits, edit{' ', line})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if x, y, found := bisect(ma, mb); found {
+		compare(edits, ma[:x], mb[:y])
+		compare(edits, ma[x:], mb[y:])
+	} else {
+		for _, line := range ma {
+			*edits = append(*edits, edit{'-', line})
+		}
+		for _, line := range mb {
+			*edits = append(*edits, edit{'+', line})
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		*edits = append(*edits, edit{' ', line})
+	}
+}
+
+// bisect searches a shortest path turning a into b from both ends at once, and returns the point
+// where the forward and the reverse paths overlap. It reports false if the path consists only of
+// deletions and insertions, so that there is nothing to divide. The first and the last lines
+// of a and b must differ
+func bisect(a, b []string) (x, y int, found bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	max := (n + m + 1) / 2
+	// vf[max+k] is the furthest x reached on diagonal k from the start,
+	// vr[max+k] the furthest distance from the end on diagonal n-m-k
+	vf := make([]int, 2*max+2)
+	vr := make([]int, 2*max+2)
+	for i := range vf {
+		vf[i], vr[i] = -1, -1
+	}
+	vf[max+1], vr[max+1] = 0, 0
+
+	delta := n - m
+	front := delta%2 != 0 // the forward path meets the reverse one, otherwise the reverse one meets it
+	// the diagonals beyond the edges of the edit graph are skipped
+	fStart, fEnd, rStart, rEnd := 0, 0, 0, 0
+	for d := 0; d < max; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || k != d && vf[max+k-1] < vf[max+k+1] {
+				x = vf[max+k+1] // down: insertion
+			} else {
+				x = vf[max+k-1] + 1 // right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[max+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				if i := max + delta - k; i >= 0 && i < len(vr) && vr[i] != -1 && x >= n-vr[i] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + rStart; k <= d-rEnd; k += 2 {
+			var x int
+			if k == -d || k != d && vr[max+k-1] < vr[max+k+1] {
+				x = vr[max+k+1]
+			} else {
+				x = vr[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vr[max+k] = x
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !front:
+				if i := max + delta - k; i >= 0 && i < len(vf) && vf[i] != -1 && vf[i] >= n-x {
+					return vf[i], vf[i] - (i - max), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
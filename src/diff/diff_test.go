@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	for _, test := range []struct{ old, new, expected string }{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\nc\n", "a\nx\nc\n", `diff old new
+--- old
++++ new
+@@ -1,3 +1,3 @@
+ a
+-b
++x
+ c
+`},
+		{"", "a\n", `diff old new
+--- old
++++ new
+@@ -0,0 +1 @@
++a
+`},
+		{"a\n", "a", `diff old new
+--- old
++++ new
+@@ -1 +1 @@
+-a
++a
+\ No newline at end of file
+`},
+		// two hunks with a gap of more than twice the context
+		{"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n", `diff old new
+--- old
++++ new
+@@ -1,4 +1,4 @@
+-1
++x
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++y
+`},
+		// one hunk for changes separated by twice the context
+		{"1\n2\n3\n4\n5\n6\n7\n8\n", "x\n2\n3\n4\n5\n6\n7\ny\n", `diff old new
+--- old
++++ new
+@@ -1,8 +1,8 @@
+-1
++x
+ 2
+ 3
+ 4
+ 5
+ 6
+ 7
+-8
++y
+`},
+		// the deletions of a change come before its insertions
+		{"a\na\na\nb\n", "c\nc\na\nc\n", `diff old new
+--- old
++++ new
+@@ -1,4 +1,4 @@
+-a
+-a
++c
++c
+ a
+-b
++c
+`},
+	} {
+		result := string(Unified("old", "new", []byte(test.old), []byte(test.new)))
+		if result != test.expected {
+			t.Errorf("%q -> %q: expected\n%s\ngot\n%s", test.old, test.new, test.expected, result)
+		}
+	}
+}
+
+func TestLineEdits(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	edits := lineEdits(a, b)
+	var x, y []string
+	changes := 0
+	for _, e := range edits {
+		if e.kind != '+' {
+			x = append(x, e.text)
+		}
+		if e.kind != '-' {
+			y = append(y, e.text)
+		}
+		if e.kind != ' ' {
+			changes++
+		}
+	}
+	if strings.Join(x, " ") != strings.Join(a, " ") || strings.Join(y, " ") != strings.Join(b, " ") {
+		t.Errorf("the edits %v do not turn %v into %v", edits, a, b)
+	}
+	if changes != 5 {
+		t.Errorf("expected 5 changes, got %d", changes)
+	}
+}
+
+func TestLineEditsLarge(t *testing.T) {
+	// the texts share no line, so every line is an edit
+	const n = 5000
+	a := make([]string, n)
+	b := make([]string, n)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d\n", i)
+		b[i] = fmt.Sprintf("b%d\n", i)
+	}
+	edits := lineEdits(a, b)
+	if len(edits) != 2*n {
+		t.Fatalf("expected %d edits, got %d", 2*n, len(edits))
+	}
+	for i, e := range edits {
+		if i < n && (e.kind != '-' || e.text != a[i]) || i >= n && (e.kind != '+' || e.text != b[i-n]) {
+			t.Fatalf("unexpected edit %d: %c%q", i, e.kind, e.text)
+		}
+	}
+}
@@ -89,9 +89,14 @@ func (l *Lexer) Lex() (tokens.Position, tokens.TokenType, any, string) {
 			if err == nil && r2 == '=' {
 				return startPos, tokens.DEFINE, ":=", ":="
 			}
+			if err == nil {
+				l.backup()
+			}
 			return startPos, tokens.COLON, ":", string(r)
 		case ';':
 			return l.position, tokens.SEMICOLON, ";", string(r)
+		case '~':
+			return l.position, tokens.TILDE, "~", string(r)
 		case '+':
 			startPos := l.position
 			token, lex, lit := l.lexPlus()
@@ -119,6 +124,9 @@ func (l *Lexer) Lex() (tokens.Position, tokens.TokenType, any, string) {
 			if err == nil && r2 == '=' {
 				return startPos, tokens.EQL, "==", "=="
 			}
+			if err == nil {
+				l.backup()
+			}
 			return startPos, tokens.ASSIGN, "=", string(r)
 		case '\'':
 			startPos := l.position
@@ -565,6 +573,7 @@ func (l *Lexer) lexColon() (tokens.TokenType, string, string) {
 	if r == '=' {
 		return tokens.OR_ASSIGN, "|=", "|="
 	}
+	l.backup()
 	return tokens.OR, "|", "|"
 }
 
@@ -573,6 +582,9 @@ func (l *Lexer) lexXor() (tokens.TokenType, string, string) {
 	if err == nil && r == '=' {
 		return tokens.XOR_ASSIGN, "^=", "^="
 	}
+	if err == nil {
+		l.backup()
+	}
 	return tokens.XOR, "^", "^"
 }
 
@@ -581,6 +593,9 @@ func (l *Lexer) lexRem() (tokens.TokenType, string, string) {
 	if err == nil && r == '=' {
 		return tokens.REM_ASSIGN, "%=", "%="
 	}
+	if err == nil {
+		l.backup()
+	}
 	return tokens.REM, "%", "%"
 }
 
@@ -589,6 +604,9 @@ func (l *Lexer) lexNot() (tokens.TokenType, string, string) {
 	if err == nil && r == '=' {
 		return tokens.NEQ, "!=", "!="
 	}
+	if err == nil {
+		l.backup()
+	}
 	return tokens.NOT, "!", "!"
 }
 
@@ -602,10 +620,14 @@ func (l *Lexer) lexArrowR() (tokens.TokenType, string, string) {
 		if err == nil && r == '=' {
 			return tokens.SHR_ASSIGN, ">>=", ">>="
 		}
+		if err == nil {
+			l.backup()
+		}
 		return tokens.SHR, ">>", ">>"
 	} else if r == '=' {
 		return tokens.GEQ, ">=", ">="
 	}
+	l.backup()
 	return tokens.GTR, ">", ">"
 }
 
@@ -619,12 +641,16 @@ func (l *Lexer) lexArrowL() (tokens.TokenType, string, string) {
 		if err == nil && r == '=' {
 			return tokens.SHL_ASSIGN, "<<=", "<<="
 		}
+		if err == nil {
+			l.backup()
+		}
 		return tokens.SHL, "<<", "<<"
 	} else if r == '-' {
 		return tokens.ARROW, "<-", "<-"
 	} else if r == '=' {
 		return tokens.LEQ, "<=", "<="
 	}
+	l.backup()
 	return tokens.LSS, "<", "<"
 }
 
@@ -268,3 +268,19 @@ func TestHelloWorld(t *testing.T) {
 	input := readInput("../tests/lexer/test8.txt")
 	performTest(t, input, expected[:])
 }
+
+// TestOperators checks that the rune read after a single-rune operator is lexed again
+func TestOperators(t *testing.T) {
+	ops := []tokens.TokenType{tokens.REM, tokens.NOT, tokens.XOR, tokens.LSS, tokens.GTR, tokens.OR, tokens.COLON, tokens.ASSIGN, tokens.SHR, tokens.SHL}
+	var expected []tokens.Token
+	input := "a"
+	for i, op := range ops {
+		column := len(input) + 1
+		input += op.String() + string(rune('b'+i))
+		expected = append(expected,
+			tokens.Token{Pos: tokens.Position{Line: 1, Column: column}, Tok: op, Lex: op.String(), Lit: op.String()},
+			tokens.Token{Pos: tokens.Position{Line: 1, Column: len(input)}, Tok: tokens.IDENT, Lex: string(rune('b' + i)), Lit: string(rune('b' + i))})
+	}
+	expected = append([]tokens.Token{{Pos: tokens.Position{Line: 1, Column: 1}, Tok: tokens.IDENT, Lex: "a", Lit: "a"}}, expected...)
+	performTest(t, input, expected)
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+	"gocompiler/src/tokens"
+	"sort"
+)
+
+// Error is a syntax error at a position in the source
+type Error struct {
+	Filename string // may be empty
+	Pos      tokens.Position
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	if e.Filename != "" {
+		return e.Filename + ":" + e.Pos.ToString() + ": " + e.Msg
+	}
+	return e.Pos.ToString() + ": " + e.Msg
+}
+
+// ErrorList is a list of syntax errors
+type ErrorList []*Error
+
+// Add appends an error with the given position and message to the list
+func (l *ErrorList) Add(pos tokens.Position, msg string) {
+	*l = append(*l, &Error{Pos: pos, Msg: msg})
+}
+
+func (l ErrorList) Len() int      { return len(l) }
+func (l ErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+func (l ErrorList) Less(i, j int) bool {
+	if l[i].Filename != l[j].Filename {
+		return l[i].Filename < l[j].Filename
+	}
+	a, b := l[i].Pos, l[j].Pos
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// Sort orders the list by file name and position; errors at the same position keep the order they were reported in
+func (l ErrorList) Sort() {
+	sort.Stable(l)
+}
+
+// RemoveMultiples sorts the list and keeps only the first error of every line
+func (l *ErrorList) RemoveMultiples() {
+	l.Sort()
+	i := 0
+	for _, e := range *l {
+		if i == 0 || e.Pos.Line != (*l)[i-1].Pos.Line {
+			(*l)[i] = e
+			i++
+		}
+	}
+	*l = (*l)[:i]
+}
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err returns the list as an error, or nil if it is empty
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"gocompiler/src/ast"
+	"gocompiler/src/lexer"
+	"gocompiler/src/tokens"
+	"io"
+	"os"
+	"strings"
+)
+
+// Mode is a set of flags controlling ParseFile
+type Mode uint
+
+const (
+	ParseComments     Mode = 1 << iota // collect the comments of the file in ast.File.Comments
+	ImportsOnly                        // stop parsing after the import declarations
+	PackageClauseOnly                  // stop parsing after the package clause
+	AllErrors                          // report all errors, not just the first 10 on different lines
+	Trace                              // print a trace of the parsed productions to standard output
+)
+
+// readSource returns the source of the file: src if it is not nil, the file contents otherwise.
+// src may be a string, []byte, *bytes.Buffer or io.Reader
+func readSource(filename string, src any) ([]byte, error) {
+	if src == nil {
+		return os.ReadFile(filename)
+	}
+	switch s := src.(type) {
+	case string:
+		return []byte(s), nil
+	case []byte:
+		return s, nil
+	case *bytes.Buffer:
+		if s != nil {
+			return s.Bytes(), nil
+		}
+	case io.Reader:
+		return io.ReadAll(s)
+	}
+	return nil, errors.New("invalid source")
+}
+
+// tokenize lexes src up to EOF or the first illegal token, after which the lexer cannot continue
+func tokenize(src []byte) (list []tokens.Token) {
+	l := lexer.NewLexer(bytes.NewReader(src))
+	for {
+		pos, tok, lex, lit := l.Lex()
+		if tok == tokens.EOF {
+			return
+		}
+		list = append(list, tokens.Token{Pos: pos, Tok: tok, Lex: lex, Lit: strings.ReplaceAll(lit, "\r", "")})
+		if tok == tokens.ILLEGAL {
+			return
+		}
+	}
+}
+
+// ParseFile parses the source of a single file and returns its AST. The source is read from
+// src if it is not nil and from filename otherwise; the file is added to fset.
+// On syntax errors the returned file still holds everything that could be parsed, with Bad
+// nodes in place of the erroneous parts, and the error is an ErrorList sorted by position
+func ParseFile(fset *tokens.FileSet, filename string, src any, mode Mode) (*ast.File, error) {
+	if fset == nil {
+		panic("parser.ParseFile: no tokens.FileSet provided (fset == nil)")
+	}
+	text, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	fset.AddFile(filename, text)
+	p := newParser(filename, tokenize(text), mode)
+	f := p.parseFile()
+	return f, p.errors.Err()
+}
+
+// ParseExpr parses the single expression x
+func ParseExpr(x string) (expr ast.Expression, err error) {
+	p := newParser("", tokenize([]byte(x)), 0)
+	defer func() {
+		if e := recover(); e != nil {
+			if _, isBailout := e.(bailout); !isBailout {
+				panic(e)
+			}
+		}
+		p.errors.RemoveMultiples()
+		err = p.errors.Err()
+	}()
+	expr = p.parseExpression()
+	if p.token.Tok == tokens.SEMICOLON && p.token.Lit == "\n" {
+		p.next()
+	}
+	p.expect(tokens.EOF)
+	return
+}
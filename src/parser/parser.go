@@ -1,63 +1,226 @@
 package parser
 
 import (
+	"gocompiler/src/ast"
 	"gocompiler/src/tokens"
+	"io"
+	"os"
 )
 
 type Parser struct {
-	tokens  []tokens.Token
-	current int
-	token   tokens.Token
+	tokens   []tokens.Token
+	current  int
+	token    tokens.Token
+	exprLev  int // < 0: in control clause, >= 0: in expression
+	mode     Mode
+	filename string
+	errors   ErrorList
+	comments []*ast.Comment
+
+	// tracing, see SetTrace
+	trace    bool
+	traceOut io.Writer
+	indent   int
+
+	// state of the last resynchronization, see advance
+	syncPos int
+	syncCnt int
 }
 
 func NewParser(tokens []tokens.Token) *Parser {
-	return &Parser{
-		tokens:  tokens,
-		current: 1,
-		token:   tokens[0],
+	return newParser("", tokens, 0)
+}
+
+func newParser(filename string, tokens []tokens.Token, mode Mode) *Parser {
+	p := &Parser{tokens: tokens, mode: mode, filename: filename}
+	p.next()
+	if mode&Trace != 0 {
+		p.SetTrace(os.Stdout)
 	}
+	return p
 }
 
-func (p *Parser) Parse() (nodes []Node) {
-	for p.current < len(p.tokens) {
-		node := p.parseTopLevelDeclaration()
-		nodes = append(nodes, node)
+// Parse parses the top level declarations of the token stream. Syntax errors do not stop it:
+// they are collected in Errors and the erroneous parts are represented by Bad nodes
+func (p *Parser) Parse() (nodes []ast.Node) {
+	for _, decl := range p.parseFile().Decls {
+		nodes = append(nodes, decl)
 	}
 	return
 }
 
-func (p *Parser) next() {
-	if p.current < len(p.tokens) {
-		p.token = p.tokens[p.current]
+// Errors returns the syntax errors met by Parse sorted by position, at most one per line
+// unless the parser runs in AllErrors mode
+func (p *Parser) Errors() ErrorList {
+	return p.errors
+}
+
+// bailout is the panic value that stops parsing after too many errors
+type bailout struct{}
+
+func (p *Parser) parseFile() (f *ast.File) {
+	if p.trace {
+		defer un(trace(p, "File"))
 	}
-	p.current++
-	if p.token.Tok == tokens.COMMENT {
+
+	f = &ast.File{}
+	defer func() {
+		if e := recover(); e != nil {
+			if _, isBailout := e.(bailout); !isBailout {
+				panic(e)
+			}
+		}
+		if p.mode&AllErrors == 0 {
+			p.errors.RemoveMultiples()
+		} else {
+			p.errors.Sort()
+		}
+		f.Comments = p.comments
+	}()
+
+	if p.token.Tok == tokens.PACKAGE {
+		f.Package = p.token.Pos
 		p.next()
+		f.Name = p.parseIdent()
+		if f.Name.Name == "_" {
+			p.error(f.Name.Pos(), "invalid package name _")
+		}
+		p.topLevelSemi()
 	}
+	if p.mode&PackageClauseOnly != 0 {
+		return
+	}
+
+	for p.token.Tok == tokens.IMPORT {
+		decl := p.parseGenericDeclaration(tokens.IMPORT)
+		for _, spec := range decl.Specs {
+			f.Imports = append(f.Imports, spec.(*ast.ImportSpec))
+		}
+		f.Decls = append(f.Decls, decl)
+		p.topLevelSemi()
+	}
+	if p.mode&ImportsOnly != 0 {
+		return
+	}
+
+	for p.token.Tok != tokens.EOF {
+		decl := p.parseTopLevelDeclaration()
+		f.Decls = append(f.Decls, decl)
+		if gen, isGen := decl.(*ast.GenericDeclaration); isGen && gen.Token == tokens.IMPORT {
+			for _, spec := range gen.Specs {
+				f.Imports = append(f.Imports, spec.(*ast.ImportSpec))
+			}
+		}
+		if _, bad := decl.(*ast.BadDeclaration); !bad {
+			p.topLevelSemi()
+		}
+	}
+	return
 }
 
-func (p *Parser) parseLiteral() (node *BasicLiteral) {
+// topLevelSemi consumes the semicolon terminating a top level declaration
+// and resynchronizes at the next declaration if it is missing
+func (p *Parser) topLevelSemi() {
 	switch p.token.Tok {
-	case tokens.INT, tokens.FLOAT, tokens.STRING, tokens.CHAR:
-		node = &BasicLiteral{Pos: p.token.Pos, Type: p.token.Tok, Value: p.token}
+	case tokens.EOF:
+	case tokens.SEMICOLON:
 		p.next()
 	default:
-		panic("expected integer or string literal")
+		p.errorExpected(";")
+		p.advance(declStart)
+	}
+}
+
+// next advances to the next non-comment token. Like the Go scanner it yields a semicolon
+// at the end of a line whose last token may terminate a statement
+func (p *Parser) next() {
+	p.next0()
+	if p.trace {
+		p.traceToken()
+	}
+}
+
+// next0 moves to the next token without tracing it
+func (p *Parser) next0() {
+	if p.atLineEnd() {
+		p.token = tokens.Token{Pos: p.token.End(), Tok: tokens.SEMICOLON, Lex: "\n", Lit: "\n"}
+		return
+	}
+	for {
+		if p.current < len(p.tokens) {
+			p.token = p.tokens[p.current]
+		} else {
+			pos := p.token.End()
+			if !pos.IsValid() {
+				pos = tokens.Position{Line: 1, Column: 1}
+			}
+			p.token = tokens.Token{Pos: pos, Tok: tokens.EOF}
+		}
+		p.current++
+		switch p.token.Tok {
+		case tokens.ILLEGAL:
+			p.error(p.token.Pos, p.token.LexString())
+		case tokens.COMMENT:
+			if p.mode&ParseComments != 0 {
+				p.comments = append(p.comments, &ast.Comment{Slash: p.token.Pos, Text: p.token.Lit})
+			}
+			continue
+		}
+		return
+	}
+}
+
+// atLineEnd reports whether a semicolon has to be inserted after the current token
+func (p *Parser) atLineEnd() bool {
+	switch p.token.Tok {
+	case tokens.IDENT, tokens.INT, tokens.FLOAT, tokens.IMAG, tokens.CHAR, tokens.STRING,
+		tokens.BREAK, tokens.CONTINUE, tokens.FALLTHROUGH, tokens.RETURN,
+		tokens.INC, tokens.DEC, tokens.RPAREN, tokens.RBRACK, tokens.RBRACE:
+	default:
+		return false
+	}
+	i := p.current
+	for i < len(p.tokens) && p.tokens[i].Tok == tokens.COMMENT {
+		i++
+	}
+	return i >= len(p.tokens) || p.tokens[i].Tok == tokens.EOF || p.tokens[i].Pos.Line > p.token.End().Line
+}
+
+func (p *Parser) parseLiteral() (node *ast.BasicLiteral) {
+	if p.trace {
+		defer un(trace(p, "Literal"))
+	}
+
+	node = &ast.BasicLiteral{ValuePos: p.token.Pos, Type: p.token.Tok, Value: p.token}
+	switch p.token.Tok {
+	case tokens.INT, tokens.FLOAT, tokens.IMAG, tokens.STRING, tokens.CHAR:
+		p.next()
+	default:
+		p.expect(tokens.STRING)
 	}
 	return
 }
 
-func (p *Parser) parseIdent() (node *Ident) {
+func (p *Parser) parseIdent() (node *ast.Ident) {
+	if p.trace {
+		defer un(trace(p, "Ident"))
+	}
+
 	if p.token.Tok == tokens.IDENT {
-		node = &Ident{Pos: p.token.Pos, Name: p.token.Lex.(string), Obj: p.token}
+		node = &ast.Ident{NamePos: p.token.Pos, Name: p.token.Lex.(string)}
 		p.next()
 	} else {
-		panic("expected identifier")
+		node = &ast.Ident{NamePos: p.token.Pos, Name: "_"}
+		p.expect(tokens.IDENT)
 	}
 	return
 }
 
-func (p *Parser) parseIdentList() (node []*Ident) {
+func (p *Parser) parseIdentList() (node []*ast.Ident) {
+	if p.trace {
+		defer un(trace(p, "IdentList"))
+	}
+
 	node = append(node, p.parseIdent())
 	for p.token.Tok == tokens.COMMA {
 		p.next()
@@ -66,150 +229,557 @@ func (p *Parser) parseIdentList() (node []*Ident) {
 	return
 }
 
-func (p *Parser) parseParamsList() (params []*Field) {
-	params = append(params, p.parseParamDecl())
-	for p.token.Tok == tokens.COMMA {
+// parseParamsList parses a parameter list, in which either all parameters are named or none is.
+// Each entry is a lone identifier or type, or a name followed by a type; in a named list
+// lone identifiers are names grouped with the type of the next entry
+func (p *Parser) parseParamsList(variadicOk bool) (params []*ast.Field) {
+	if p.trace {
+		defer un(trace(p, "ParamsList"))
+	}
+
+	type entry struct {
+		pos  tokens.Position
+		name *ast.Ident
+		typ  ast.Expression
+	}
+	var entries []entry
+	named := false
+	for {
+		pos := p.token.Pos
+		name, typ := p.parseParamEntry(variadicOk)
+		entries = append(entries, entry{pos: pos, name: name, typ: typ})
+		named = named || name != nil
+		if p.token.Tok != tokens.COMMA {
+			break
+		}
 		p.next()
-		params = append(params, p.parseParamDecl())
+		if p.token.Tok == tokens.RPAREN || p.token.Tok == tokens.RBRACK {
+			break
+		}
+	}
+
+	if named {
+		var names []*ast.Ident
+		for _, e := range entries {
+			if e.name == nil {
+				ident, isIdent := e.typ.(*ast.Ident)
+				if !isIdent {
+					p.error(e.pos, "mixed named and unnamed parameters")
+					ident = &ast.Ident{NamePos: e.pos, Name: "_"}
+				}
+				names = append(names, ident)
+				continue
+			}
+			params = append(params, &ast.Field{Names: append(names, e.name), Type: e.typ})
+			names = nil
+		}
+		if len(names) > 0 {
+			p.error(names[0].Pos(), "mixed named and unnamed parameters")
+		}
+	} else {
+		for _, e := range entries {
+			params = append(params, &ast.Field{Type: e.typ})
+		}
+	}
+
+	for i, param := range params {
+		if ellipsis, isVariadic := param.Type.(*ast.Ellipsis); isVariadic && (i != len(params)-1 || len(param.Names) > 1) {
+			p.error(ellipsis.Ellipsis, "can only use ... with final parameter in list")
+		}
 	}
 	return
 }
 
-func (p *Parser) parseParameters(acceptTypeParams bool) (typeParams, params *FieldList) {
+func (p *Parser) parseParamEntry(variadicOk bool) (name *ast.Ident, typ ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "ParamEntry"))
+	}
+
+	switch p.token.Tok {
+	case tokens.IDENT:
+		ident := p.parseIdent()
+		switch p.token.Tok {
+		case tokens.COMMA, tokens.RPAREN, tokens.RBRACK:
+			return nil, ident
+		case tokens.PERIOD:
+			return nil, p.parseTypeName(ident)
+		case tokens.LBRACK:
+			names, typ := p.parseArrayFieldOrTypeInstance(ident)
+			if len(names) == 0 {
+				return nil, typ
+			}
+			return names[0], typ
+		case tokens.ELLIPSIS:
+			if variadicOk {
+				return ident, p.parseVariadicType()
+			}
+		}
+		return ident, p.parseType()
+	case tokens.ELLIPSIS:
+		if variadicOk {
+			return nil, p.parseVariadicType()
+		}
+	}
+	return nil, p.parseType()
+}
+
+func (p *Parser) parseParameters(acceptTypeParams bool) (typeParams, params *ast.FieldList) {
+	if p.trace {
+		defer un(trace(p, "Parameters"))
+	}
+
 	if acceptTypeParams && p.token.Tok == tokens.LBRACK {
 		opening := p.token.Pos
 		p.next()
-		list := p.parseParamsList()
-		closing := p.expect(tokens.RBRACK).Pos
-		typeParams = &FieldList{Opening: opening, List: list, Closing: closing}
+		typeParams = p.parseTypeParams(opening, nil)
 	}
 	opening := p.expect(tokens.LPAREN)
-	var fields []*Field
+	var fields []*ast.Field
 	if p.token.Tok != tokens.RPAREN {
-		fields = p.parseParamsList()
+		fields = p.parseParamsList(true)
 	}
 	closing := p.expect(tokens.RPAREN)
-	params = &FieldList{Opening: opening.Pos, List: fields, Closing: closing.Pos}
+	params = &ast.FieldList{Opening: opening.Pos, List: fields, Closing: closing.Pos}
 	return
 }
 
-func (p *Parser) parseResults() (node *FieldList) {
+// parseTypeParams parses the type parameters after the "[" at opening up to the closing "]";
+// the name of the first parameter may be parsed already. Each group of names is followed by
+// a constraint, a type element
+func (p *Parser) parseTypeParams(opening tokens.Position, name *ast.Ident) *ast.FieldList {
+	if p.trace {
+		defer un(trace(p, "TypeParams"))
+	}
+
+	var list []*ast.Field
+	for name != nil || p.token.Tok != tokens.RBRACK && p.token.Tok != tokens.EOF {
+		if name == nil {
+			name = p.parseIdent()
+		}
+		names := []*ast.Ident{name}
+		name = nil
+		for p.token.Tok == tokens.COMMA {
+			p.next()
+			names = append(names, p.parseIdent())
+		}
+		var constraint ast.Expression
+		if p.token.Tok == tokens.RBRACK {
+			p.error(p.token.Pos, "missing type constraint")
+			constraint = &ast.BadExpression{From: p.token.Pos, To: p.token.Pos}
+		} else {
+			constraint = p.parseTypeElem()
+		}
+		list = append(list, &ast.Field{Names: names, Type: constraint})
+		if p.token.Tok != tokens.COMMA {
+			break
+		}
+		p.next()
+	}
+	closing := p.expect(tokens.RBRACK).Pos
+	if len(list) == 0 {
+		p.error(closing, "empty type parameter list")
+	}
+	return &ast.FieldList{Opening: opening, List: list, Closing: closing}
+}
+
+// parseTypeElem parses a union of type terms, a ~T term being a UnaryExpression
+func (p *Parser) parseTypeElem() ast.Expression {
+	if p.trace {
+		defer un(trace(p, "TypeElem"))
+	}
+
+	x := p.parseTypeTerm()
+	for p.token.Tok == tokens.OR {
+		pos := p.token.Pos
+		p.next()
+		x = &ast.BinaryExpression{OpPos: pos, Operator: tokens.OR, LeftX: x, RightX: p.parseTypeTerm()}
+	}
+	return x
+}
+
+func (p *Parser) parseTypeTerm() ast.Expression {
+	if p.trace {
+		defer un(trace(p, "TypeTerm"))
+	}
+
+	if p.token.Tok == tokens.TILDE {
+		pos := p.token.Pos
+		p.next()
+		return &ast.UnaryExpression{OpPos: pos, Operator: tokens.TILDE, X: p.parseType()}
+	}
+	return p.parseType()
+}
+
+func (p *Parser) parseResults() (node *ast.FieldList) {
+	if p.trace {
+		defer un(trace(p, "Results"))
+	}
+
 	if p.token.Tok == tokens.LPAREN {
 		_, params := p.parseParameters(false)
 		return params
 	}
+	if !p.isTypeStart() {
+		return &ast.FieldList{}
+	}
 	typ := p.parseType()
-	list := make([]*Field, 1)
-	list[0] = &Field{Type: typ}
-	return &FieldList{List: list}
+	list := make([]*ast.Field, 1)
+	list[0] = &ast.Field{Type: typ}
+	return &ast.FieldList{List: list}
 }
 
-func (p *Parser) parseFunctionType() *FunctionType {
-	p.expect(tokens.FUNC)
+func (p *Parser) parseFunctionType() *ast.FunctionType {
+	if p.trace {
+		defer un(trace(p, "FunctionType"))
+	}
+
+	pos := p.expect(tokens.FUNC).Pos
 	typeParams, params := p.parseParameters(true)
 	results := p.parseResults()
-	return &FunctionType{Pos: p.token.Pos, TypeParams: typeParams, Params: params, Results: results}
+	return &ast.FunctionType{Func: pos, TypeParams: typeParams, Params: params, Results: results}
 }
 
-func (p *Parser) parseArrayType() (node *ArrayType) {
+func (p *Parser) parseArrayType(lbrack tokens.Position) (node *ast.ArrayType) {
+	if p.trace {
+		defer un(trace(p, "ArrayType"))
+	}
+
 	length := p.parseExpression()
 	p.expect(tokens.RBRACK)
 	typ := p.parseType()
-	return &ArrayType{Len: length, ElementType: typ, Post: p.token.Pos}
+	return &ast.ArrayType{Len: length, ElementType: typ, Lbrack: lbrack}
 }
 
-func (p *Parser) parseStructType() (node *StructType) {
-	p.expect(tokens.STRUCT)
-	p.expect(tokens.LBRACE)
-	var list []*Field
-	for p.token.Tok == tokens.IDENT || p.token.Tok == tokens.LPAREN {
-		list = append(list, p.parseParamDecl())
+func (p *Parser) parseStructType() (node *ast.StructType) {
+	if p.trace {
+		defer un(trace(p, "StructType"))
+	}
+
+	pos := p.expect(tokens.STRUCT).Pos
+	lbrace := p.expect(tokens.LBRACE).Pos
+	var list []*ast.Field
+	for p.token.Tok == tokens.IDENT || p.token.Tok == tokens.MUL || p.token.Tok == tokens.LPAREN {
+		list = append(list, p.parseFieldDecl())
+		p.optionalSemi()
 	}
-	p.expect(tokens.RBRACE)
+	rbrace := p.expect(tokens.RBRACE).Pos
 
-	return &StructType{Pos: p.token.Pos, Fields: list}
+	return &ast.StructType{Struct: pos, Fields: &ast.FieldList{Opening: lbrace, List: list, Closing: rbrace}}
 }
 
-func (p *Parser) parseParamDecl() *Field {
-	params := p.parseIdentList()
-	typ := p.parseType()
-	return &Field{Names: params, Type: typ}
+func (p *Parser) parseInterfaceType() *ast.InterfaceType {
+	if p.trace {
+		defer un(trace(p, "InterfaceType"))
+	}
+
+	pos := p.expect(tokens.INTERFACE).Pos
+	lbrace := p.expect(tokens.LBRACE).Pos
+	var list []*ast.Field
+	for p.token.Tok == tokens.IDENT || p.token.Tok == tokens.TILDE || p.token.Tok == tokens.LPAREN ||
+		p.token.Tok == tokens.LBRACK || p.token.Tok == tokens.MUL || p.token.Tok == tokens.STRUCT ||
+		p.token.Tok == tokens.FUNC || p.token.Tok == tokens.INTERFACE {
+		list = append(list, p.parseInterfaceElem())
+		p.optionalSemi()
+	}
+	rbrace := p.expect(tokens.RBRACE).Pos
+
+	return &ast.InterfaceType{Interface: pos, Methods: &ast.FieldList{Opening: lbrace, List: list, Closing: rbrace}}
+}
+
+// parseInterfaceElem parses a method or an embedded type element of an interface
+func (p *Parser) parseInterfaceElem() *ast.Field {
+	if p.trace {
+		defer un(trace(p, "InterfaceElem"))
+	}
+
+	if p.token.Tok == tokens.IDENT {
+		name := p.parseIdent()
+		if p.token.Tok == tokens.LPAREN {
+			_, params := p.parseParameters(false)
+			results := p.parseResults()
+			return &ast.Field{Names: []*ast.Ident{name}, Type: &ast.FunctionType{Params: params, Results: results}}
+		}
+		x := p.parseTypeName(name)
+		for p.token.Tok == tokens.OR {
+			pos := p.token.Pos
+			p.next()
+			x = &ast.BinaryExpression{OpPos: pos, Operator: tokens.OR, LeftX: x, RightX: p.parseTypeTerm()}
+		}
+		return &ast.Field{Type: x}
+	}
+	return &ast.Field{Type: p.parseTypeElem()}
 }
 
-func (p *Parser) parseType() Expression {
+func (p *Parser) parseFieldDecl() *ast.Field {
+	if p.trace {
+		defer un(trace(p, "FieldDecl"))
+	}
+
+	var names []*ast.Ident
+	var typ ast.Expression
 	switch p.token.Tok {
 	case tokens.IDENT:
-		return p.parseIdent()
+		name := p.parseIdent()
+		switch p.token.Tok {
+		case tokens.PERIOD, tokens.STRING, tokens.SEMICOLON, tokens.RBRACE:
+			typ = p.parseTypeName(name)
+		case tokens.LBRACK:
+			names, typ = p.parseArrayFieldOrTypeInstance(name)
+		default:
+			names = append(names, name)
+			for p.token.Tok == tokens.COMMA {
+				p.next()
+				names = append(names, p.parseIdent())
+			}
+			typ = p.parseType()
+		}
+	case tokens.MUL:
+		star := p.token.Pos
+		p.next()
+		if p.token.Tok == tokens.LPAREN {
+			p.error(p.token.Pos, "cannot parenthesize embedded type")
+			typ = &ast.StarExpression{Star: star, X: p.parseType()}
+		} else {
+			typ = &ast.StarExpression{Star: star, X: p.parseTypeName(p.parseIdent())}
+		}
+	default:
+		p.error(p.token.Pos, "cannot parenthesize embedded type")
+		typ = p.parseType()
+	}
+
+	var tag *ast.BasicLiteral
+	if p.token.Tok == tokens.STRING {
+		tag = p.parseLiteral()
+	}
+	return &ast.Field{Names: names, Type: typ, Tag: tag}
+}
+
+// parseTypeName parses the rest of a type name starting with the identifier name:
+// an optional package qualifier followed by optional type arguments
+func (p *Parser) parseTypeName(name *ast.Ident) ast.Expression {
+	if p.trace {
+		defer un(trace(p, "TypeName"))
+	}
+
+	var typ ast.Expression = name
+	if p.token.Tok == tokens.PERIOD {
+		p.next()
+		typ = &ast.SelectorExpression{X: name, Selector: p.parseIdent()}
+	}
+	if p.token.Tok == tokens.LBRACK {
+		typ = p.parseTypeInstance(typ)
+	}
+	return typ
+}
+
+func (p *Parser) parseTypeInstance(typ ast.Expression) ast.Expression {
+	if p.trace {
+		defer un(trace(p, "TypeInstance"))
+	}
+
+	lbrack := p.expect(tokens.LBRACK).Pos
+	p.exprLev++
+	var list []ast.Expression
+	for p.token.Tok != tokens.RBRACK && p.token.Tok != tokens.EOF {
+		list = append(list, p.parseType())
+		if p.token.Tok != tokens.COMMA {
+			break
+		}
+		p.next()
+	}
+	p.exprLev--
+	rbrack := p.expect(tokens.RBRACK).Pos
+	switch len(list) {
+	case 0:
+		p.error(rbrack, "expected type argument list")
+		return &ast.IndexExpression{X: typ, LBracketPos: lbrack, RBracketPos: rbrack, Index: &ast.BadExpression{From: lbrack, To: rbrack}}
+	case 1:
+		return &ast.IndexExpression{X: typ, LBracketPos: lbrack, RBracketPos: rbrack, Index: list[0]}
+	default:
+		return &ast.IndexExpressions{X: typ, Lbrack: lbrack, Rbrack: rbrack, Indices: list}
+	}
+}
+
+// parseArrayFieldOrTypeInstance decides between a field "name [N]T" and
+// an embedded generic type "name[T]": only the former is followed by an element type
+func (p *Parser) parseArrayFieldOrTypeInstance(name *ast.Ident) ([]*ast.Ident, ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "ArrayFieldOrTypeInstance"))
+	}
+
+	lbrack := p.expect(tokens.LBRACK).Pos
+	if p.token.Tok == tokens.RBRACK {
+		p.next()
+		return []*ast.Ident{name}, &ast.ArrayType{Len: nil, ElementType: p.parseType(), Lbrack: lbrack}
+	}
+	p.exprLev++
+	args := p.parseExpressionList()
+	p.exprLev--
+	if p.token.Tok == tokens.COMMA {
+		p.next()
+	}
+	rbrack := p.expect(tokens.RBRACK).Pos
+	if len(args) == 1 && p.isTypeStart() {
+		return []*ast.Ident{name}, &ast.ArrayType{Len: args[0], ElementType: p.parseType(), Lbrack: lbrack}
+	}
+	if len(args) == 1 {
+		return nil, &ast.IndexExpression{X: name, LBracketPos: lbrack, RBracketPos: rbrack, Index: args[0]}
+	}
+	return nil, &ast.IndexExpressions{X: name, Lbrack: lbrack, Rbrack: rbrack, Indices: args}
+}
+
+func (p *Parser) isTypeStart() bool {
+	switch p.token.Tok {
+	case tokens.IDENT, tokens.LBRACK, tokens.STRUCT, tokens.INTERFACE, tokens.FUNC, tokens.MUL, tokens.LPAREN:
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseVariadicType() *ast.Ellipsis {
+	if p.trace {
+		defer un(trace(p, "VariadicType"))
+	}
+
+	pos := p.expect(tokens.ELLIPSIS).Pos
+	elt := p.parseType()
+	return &ast.Ellipsis{Ellipsis: pos, Elt: elt}
+}
+
+func (p *Parser) parseType() ast.Expression {
+	if p.trace {
+		defer un(trace(p, "Type"))
+	}
+
+	switch p.token.Tok {
+	case tokens.IDENT:
+		return p.parseTypeName(p.parseIdent())
+	case tokens.LPAREN:
+		lparen := p.token.Pos
+		p.next()
+		typ := p.parseType()
+		rparen := p.expect(tokens.RPAREN).Pos
+		return &ast.ParenExpression{LParenPos: lparen, X: typ, RParenPos: rparen}
 	case tokens.STRUCT:
 		return p.parseStructType()
+	case tokens.INTERFACE:
+		return p.parseInterfaceType()
 	case tokens.LBRACK:
+		lbrack := p.token.Pos
 		p.next()
 		if p.token.Tok == tokens.RBRACK {
 			p.next()
 			typ := p.parseType()
-			return &ArrayType{Len: nil, Post: p.token.Pos, ElementType: typ}
+			return &ast.ArrayType{Len: nil, Lbrack: lbrack, ElementType: typ}
 		}
-		return p.parseArrayType()
+		return p.parseArrayType(lbrack)
 	case tokens.FUNC:
 		return p.parseFunctionType()
+	case tokens.MUL:
+		star := p.token.Pos
+		p.next()
+		return &ast.StarExpression{Star: star, X: p.parseType()}
 	default:
-		return nil
+		pos := p.token.Pos
+		p.errorExpected("type")
+		p.advance(exprEnd)
+		return &ast.BadExpression{From: pos, To: p.token.Pos}
 	}
 }
 
-func (p *Parser) parseCall(function Expression) *CallExpression {
+func (p *Parser) parseCall(function ast.Expression) *ast.CallExpression {
+	if p.trace {
+		defer un(trace(p, "Call"))
+	}
+
 	lpos := p.expect(tokens.LPAREN).Pos
-	var list []Expression
-	for p.token.Tok != tokens.RPAREN && p.token.Tok != tokens.EOF {
+	p.exprLev++
+	var list []ast.Expression
+	var ellipsis tokens.Position
+	for p.token.Tok != tokens.RPAREN && p.token.Tok != tokens.EOF && !ellipsis.IsValid() {
 		list = append(list, p.parseExpression())
-		if p.token.Tok == tokens.RPAREN && p.token.Tok != tokens.EOF {
+		if p.token.Tok == tokens.ELLIPSIS {
+			ellipsis = p.token.Pos
+			p.next()
+		}
+		if p.token.Tok != tokens.COMMA {
 			break
 		}
 		p.next()
 	}
+	p.exprLev--
 	rpos := p.expect(tokens.RPAREN).Pos
-	return &CallExpression{Function: function, LParenPos: lpos, RParenPos: rpos, Arguments: list}
+	return &ast.CallExpression{Function: function, LParenPos: lpos, Ellipsis: ellipsis, RParenPos: rpos, Arguments: list}
 }
 
-func (p *Parser) parseValue() Expression {
+func (p *Parser) parseValue() ast.Expression {
+	if p.trace {
+		defer un(trace(p, "Value"))
+	}
+
 	if p.token.Tok == tokens.LBRACE {
 		return p.parseLiteralValue(nil)
 	}
 	return p.parseExpression()
 }
 
-func (p *Parser) parseElement() Expression {
+func (p *Parser) parseElement() ast.Expression {
+	if p.trace {
+		defer un(trace(p, "Element"))
+	}
+
 	key := p.parseValue()
 	if p.token.Tok == tokens.COLON {
 		colon := p.token.Pos
 		p.next()
-		key = &KeyValueExpression{Key: key, ColonPos: colon, Value: p.parseValue()}
+		key = &ast.KeyValueExpression{Key: key, ColonPos: colon, Value: p.parseValue()}
 	}
 	return key
 }
 
-func (p *Parser) parseElementList() (list []Expression) {
+func (p *Parser) parseElementList() (list []ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "ElementList"))
+	}
+
 	for p.token.Tok != tokens.RBRACE && p.token.Tok != tokens.EOF {
 		list = append(list, p.parseElement())
-		if p.token.Tok == tokens.RBRACE && p.token.Tok != tokens.EOF {
+		if p.token.Tok == tokens.RBRACE {
 			break
 		}
+		if p.token.Tok != tokens.COMMA {
+			msg := "missing ','"
+			if p.token.Tok == tokens.SEMICOLON && p.token.Lit == "\n" {
+				msg += " before newline"
+			}
+			p.error(p.token.Pos, msg+" in composite literal")
+		}
 		p.next()
 	}
 	return
 }
 
-func (p *Parser) parseLiteralValue(typ Expression) Expression {
+func (p *Parser) parseLiteralValue(typ ast.Expression) ast.Expression {
+	if p.trace {
+		defer un(trace(p, "LiteralValue"))
+	}
+
 	lpos := p.expect(tokens.LBRACE).Pos
-	var elements []Expression
+	var elements []ast.Expression
+	p.exprLev++
 	if p.token.Tok != tokens.RBRACE {
 		elements = p.parseElementList()
 	}
+	p.exprLev--
 	rpos := p.expect(tokens.RBRACE).Pos
-	return &CompositeLiteral{Type: typ, LbracePos: lpos, RbracePos: rpos, Elements: elements}
+	return &ast.CompositeLiteral{Type: typ, LbracePos: lpos, RbracePos: rpos, Elements: elements}
 }
 
-func (p *Parser) parsePrimaryExpression(expr Expression) (node Expression) {
+func (p *Parser) parsePrimaryExpression(expr ast.Expression) (node ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "PrimaryExpression"))
+	}
+
 	if expr == nil {
 		expr = p.parseOperand()
 	}
@@ -221,9 +791,14 @@ func (p *Parser) parsePrimaryExpression(expr Expression) (node Expression) {
 			switch p.token.Tok {
 			case tokens.IDENT:
 				name := p.parseIdent()
-				expr = &SelectorExpression{X: expr, Selector: name}
+				expr = &ast.SelectorExpression{X: expr, Selector: name}
 			default:
-				panic("exptected selector or type assertion")
+				pos := p.token.Pos
+				p.errorExpected("selector or type assertion")
+				if p.token.Tok != tokens.RBRACE {
+					p.next()
+				}
+				expr = &ast.SelectorExpression{X: expr, Selector: &ast.Ident{NamePos: pos, Name: "_"}}
 			}
 		case tokens.LPAREN:
 			expr = p.parseCall(expr)
@@ -231,9 +806,12 @@ func (p *Parser) parsePrimaryExpression(expr Expression) (node Expression) {
 			expr = p.parseIndexOrInstance(expr)
 		case tokens.LBRACE:
 			switch expr.(type) {
-			case *ArrayType, *StructType:
+			case *ast.ArrayType, *ast.StructType:
 				expr = p.parseLiteralValue(expr)
-			case *Ident, *SelectorExpression:
+			case *ast.Ident, *ast.SelectorExpression, *ast.IndexExpression, *ast.IndexExpressions:
+				if p.exprLev < 0 {
+					return expr // the block of a control clause
+				}
 				expr = p.parseLiteralValue(expr)
 			default:
 				return expr
@@ -244,13 +822,21 @@ func (p *Parser) parsePrimaryExpression(expr Expression) (node Expression) {
 	}
 }
 
-func (p *Parser) parseIndexOrInstance(expr Expression) Expression {
+func (p *Parser) parseIndexOrInstance(expr ast.Expression) ast.Expression {
+	if p.trace {
+		defer un(trace(p, "IndexOrInstance"))
+	}
+
 	lpos := p.expect(tokens.LBRACK).Pos
 	if p.token.Tok == tokens.RBRACK {
-		panic("empty index, slice or index expressions are not permitted")
+		p.errorExpected("operand")
+		rpos := p.token.Pos
+		p.next()
+		return &ast.IndexExpression{X: expr, LBracketPos: lpos, RBracketPos: rpos, Index: &ast.BadExpression{From: rpos, To: rpos}}
 	}
 
-	var args []Expression
+	var args []ast.Expression
+	p.exprLev++
 	index := p.parseExpression()
 
 	if p.token.Tok == tokens.COMMA {
@@ -262,27 +848,34 @@ func (p *Parser) parseIndexOrInstance(expr Expression) Expression {
 			}
 		}
 	}
+	p.exprLev--
 	rpos := p.expect(tokens.RBRACK).Pos
 
 	switch len(args) {
 	case 0:
-		return &IndexExpression{X: expr, LBracketPos: lpos, RBracketPos: rpos, Index: index}
+		return &ast.IndexExpression{X: expr, LBracketPos: lpos, RBracketPos: rpos, Index: index}
 	case 1:
-		return &IndexExpression{X: expr, LBracketPos: lpos, RBracketPos: rpos, Index: args[0]}
+		return &ast.IndexExpression{X: expr, LBracketPos: lpos, RBracketPos: rpos, Index: args[0]}
 	default:
-		return &IndexExpressions{X: expr, Lbrack: lpos, Rbrack: rpos, Indices: args}
+		return &ast.IndexExpressions{X: expr, Lbrack: lpos, Rbrack: rpos, Indices: args}
 	}
 }
 
-func (p *Parser) parseGenericDeclaration(keyword tokens.TokenType) *GenericDeclaration {
+func (p *Parser) parseGenericDeclaration(keyword tokens.TokenType) *ast.GenericDeclaration {
+	if p.trace {
+		defer un(trace(p, "GenericDeclaration"))
+	}
+
 	pos := p.expect(keyword).Pos
 	var lpos, rpos tokens.Position
-	var list []Spec
+	var list []ast.Spec
 	if p.token.Tok == tokens.LPAREN {
 		lpos = p.token.Pos
 		p.next()
 		for p.token.Tok != tokens.RPAREN && p.token.Tok != tokens.EOF {
 			switch keyword {
+			case tokens.IMPORT:
+				list = append(list, p.parseImportSpec())
 			case tokens.VAR:
 				list = append(list, p.parseVarSpec())
 			case tokens.TYPE:
@@ -290,10 +883,13 @@ func (p *Parser) parseGenericDeclaration(keyword tokens.TokenType) *GenericDecla
 			case tokens.CONST:
 				list = append(list, p.parseConstSpec())
 			}
+			p.optionalSemi()
 		}
 		rpos = p.expect(tokens.RPAREN).Pos
 	} else {
 		switch keyword {
+		case tokens.IMPORT:
+			list = append(list, p.parseImportSpec())
 		case tokens.VAR:
 			list = append(list, p.parseVarSpec())
 		case tokens.TYPE:
@@ -303,24 +899,31 @@ func (p *Parser) parseGenericDeclaration(keyword tokens.TokenType) *GenericDecla
 		}
 	}
 
-	return &GenericDeclaration{
+	return &ast.GenericDeclaration{
 		Token:     keyword,
-		Pos:       pos,
+		TokPos:    pos,
 		LParenPos: lpos,
 		RParenPos: rpos,
 		Specs:     list,
 	}
 }
 
-func (p *Parser) parseOperand() (node Expression) {
+func (p *Parser) parseOperand() (node ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "Operand"))
+	}
+
 	switch p.token.Tok {
 	case tokens.IDENT:
 		return p.parseIdent()
 	case tokens.LPAREN:
+		lparen := p.token.Pos
 		p.next()
-		node = p.parseExpression()
-		p.expect(tokens.RPAREN)
-		return
+		p.exprLev++
+		x := p.parseExpression()
+		p.exprLev--
+		rparen := p.expect(tokens.RPAREN).Pos
+		return &ast.ParenExpression{LParenPos: lparen, X: x, RParenPos: rparen}
 	case tokens.INT, tokens.FLOAT, tokens.STRING, tokens.CHAR:
 		return p.parseLiteral()
 	case tokens.FUNC:
@@ -328,61 +931,138 @@ func (p *Parser) parseOperand() (node Expression) {
 		if p.token.Tok != tokens.LBRACE {
 			return typ
 		}
+		p.exprLev++
 		body := p.parseBlockStatement()
-		return &FunctionLiteral{Type: typ, Body: body}
-	case tokens.STRUCT:
-		return p.parseStructType()
-	case tokens.LBRACK:
-		p.expect(tokens.LBRACK)
-		return p.parseArrayType()
+		p.exprLev--
+		return &ast.FunctionLiteral{Type: typ, Body: body}
+	case tokens.STRUCT, tokens.INTERFACE, tokens.LBRACK:
+		return p.parseType()
 	}
 
-	return nil
+	pos := p.token.Pos
+	p.errorExpected("operand")
+	p.advance(exprEnd)
+	return &ast.BadExpression{From: pos, To: p.token.Pos}
 }
 
-func (p *Parser) parseStatement() Statement {
+func (p *Parser) parseStatement() ast.Statement {
+	if p.trace {
+		defer un(trace(p, "Statement"))
+	}
+
 	switch p.token.Tok {
 	case tokens.IF:
 		return p.parseIfStatement()
 	case tokens.FOR:
 		return p.parseForStatement()
+	case tokens.SWITCH:
+		return p.parseSwitchStatement()
 	case tokens.RETURN:
 		return p.parseReturnStatement()
+	case tokens.BREAK, tokens.CONTINUE, tokens.GOTO, tokens.FALLTHROUGH:
+		return p.parseBranchStatement()
 	case tokens.CONST, tokens.VAR, tokens.TYPE:
-		return &DeclarationStatement{Decl: p.parseGenericDeclaration(p.token.Tok)}
+		return &ast.DeclarationStatement{Decl: p.parseGenericDeclaration(p.token.Tok)}
 	case tokens.LBRACE:
 		return p.parseBlockStatement()
+	case tokens.IDENT, tokens.INT, tokens.FLOAT, tokens.IMAG, tokens.CHAR, tokens.STRING, tokens.FUNC, tokens.LPAREN,
+		tokens.LBRACK, tokens.STRUCT, tokens.MAP, tokens.CHAN, tokens.INTERFACE,
+		tokens.ADD, tokens.SUB, tokens.MUL, tokens.AND, tokens.XOR, tokens.ARROW, tokens.NOT:
+		s := p.parseSimpleStatement(false)
+		if x, isExpr := s.(*ast.ExpressionStatement); isExpr && p.token.Tok == tokens.COLON {
+			if label, isIdent := x.X.(*ast.Ident); isIdent {
+				return p.parseLabeledStatement(label)
+			}
+		}
+		return s
 	default:
-		return p.parseSimpleStatement()
+		pos := p.token.Pos
+		p.errorExpected("statement")
+		p.advance(stmtStart)
+		return &ast.BadStatement{From: pos, To: p.token.Pos}
 	}
 }
 
-func (p *Parser) parseSimpleStatement() Statement {
+// parseSimpleStatement parses a simple statement; with rangeOk set it also accepts
+// a range clause, returned as an assignment whose right side is a unary "range" expression
+func (p *Parser) parseSimpleStatement(rangeOk bool) ast.Statement {
+	if p.trace {
+		defer un(trace(p, "SimpleStatement"))
+	}
+
+	if rangeOk && p.token.Tok == tokens.RANGE {
+		pos := p.token.Pos
+		p.next()
+		y := []ast.Expression{&ast.UnaryExpression{OpPos: pos, Operator: tokens.RANGE, X: p.parseExpression()}}
+		return &ast.AssignStatement{Rhs: y}
+	}
 	expr := p.parseExpressionList()
 	switch {
-	case p.token.Tok == tokens.DEFINE, p.token.Tok >= tokens.ADD_ASSIGN && p.token.Tok < tokens.AND_NOT_ASSIGN:
+	case p.token.Tok == tokens.DEFINE, p.token.Tok == tokens.ASSIGN, p.token.Tok >= tokens.ADD_ASSIGN && p.token.Tok <= tokens.AND_NOT_ASSIGN:
 		current := p.token
 		p.next()
-		y := p.parseExpressionList()
-		return &AssignStatement{Lhs: expr, TokPos: current.Pos, Tok: current, Rhs: y}
+		var y []ast.Expression
+		if rangeOk && p.token.Tok == tokens.RANGE && (current.Tok == tokens.DEFINE || current.Tok == tokens.ASSIGN) {
+			pos := p.token.Pos
+			p.next()
+			y = []ast.Expression{&ast.UnaryExpression{OpPos: pos, Operator: tokens.RANGE, X: p.parseExpression()}}
+		} else {
+			y = p.parseExpressionList()
+		}
+		return &ast.AssignStatement{Lhs: expr, TokPos: current.Pos, Tok: current, Rhs: y}
 	}
 	switch p.token.Tok {
 	case tokens.INC, tokens.DEC:
-		statement := &IncDecStatement{X: expr[0], Pos: p.token.Pos, Tok: p.token}
+		statement := &ast.IncDecStatement{X: expr[0], TokPos: p.token.Pos, Tok: p.token}
 		p.next()
 		return statement
 	}
-	return &ExpressionStatement{X: expr[0]}
+	return &ast.ExpressionStatement{X: expr[0]}
 }
 
-func (p *Parser) parseIfStatement() *IfStatement {
-	pos := p.expect(tokens.IF).Pos
+// parseHeader parses the clause between the keyword of an if or switch statement and its block:
+// an optional simple statement followed by ";" and an optional expression
+func (p *Parser) parseHeader() (init ast.Statement, cond ast.Statement) {
+	if p.trace {
+		defer un(trace(p, "Header"))
+	}
+
 	if p.token.Tok == tokens.LBRACE {
-		panic("missing condition in if statement")
+		return
+	}
+	prevLev := p.exprLev
+	p.exprLev = -1
+	if p.token.Tok != tokens.SEMICOLON {
+		cond = p.parseSimpleStatement(false)
+	}
+	if p.token.Tok == tokens.SEMICOLON {
+		p.next()
+		init = cond
+		cond = nil
+		if p.token.Tok != tokens.LBRACE {
+			cond = p.parseSimpleStatement(false)
+		}
+	}
+	p.exprLev = prevLev
+	return
+}
+
+func (p *Parser) parseIfStatement() *ast.IfStatement {
+	if p.trace {
+		defer un(trace(p, "IfStatement"))
+	}
+
+	pos := p.expect(tokens.IF).Pos
+	init, cond := p.parseHeader()
+	var exp ast.Expression
+	if cond == nil {
+		p.error(p.token.Pos, "missing condition in if statement")
+		exp = &ast.BadExpression{From: p.token.Pos, To: p.token.Pos}
+	} else {
+		exp = p.toExpr(cond, "boolean expression")
 	}
-	exp := p.parseExpression()
 	body := p.parseBlockStatement()
-	var _else Statement
+	var _else ast.Statement
 	if p.token.Tok == tokens.ELSE {
 		p.next()
 		switch p.token.Tok {
@@ -391,71 +1071,218 @@ func (p *Parser) parseIfStatement() *IfStatement {
 		case tokens.LBRACE:
 			_else = p.parseBlockStatement()
 		default:
-			panic("expected if statement of block")
+			p.errorExpected("if statement or block")
+			_else = &ast.BadStatement{From: p.token.Pos, To: p.token.Pos}
 		}
 	}
-	return &IfStatement{Pos: pos, Cond: exp, Body: body, Else: _else}
+	return &ast.IfStatement{If: pos, Init: init, Cond: exp, Body: body, Else: _else}
+}
+
+func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
+	if p.trace {
+		defer un(trace(p, "SwitchStatement"))
+	}
+
+	pos := p.expect(tokens.SWITCH).Pos
+	init, tag := p.parseHeader()
+	lbrace := p.expect(tokens.LBRACE).Pos
+	var list []ast.Statement
+	for p.token.Tok == tokens.CASE || p.token.Tok == tokens.DEFAULT {
+		list = append(list, p.parseCaseClause())
+	}
+	rbrace := p.expect(tokens.RBRACE).Pos
+	body := &ast.BlockStatement{LbracePos: lbrace, List: list, RbracePos: rbrace}
+	return &ast.SwitchStatement{Switch: pos, Init: init, Tag: p.toExpr(tag, "switch expression"), Body: body}
 }
 
-func (p *Parser) parseForStatement() *ForStatement {
+func (p *Parser) parseCaseClause() *ast.CaseClause {
+	if p.trace {
+		defer un(trace(p, "CaseClause"))
+	}
+
+	pos := p.token.Pos
+	var list []ast.Expression
+	if p.token.Tok == tokens.CASE {
+		p.next()
+		list = p.parseExpressionList()
+	} else {
+		p.expect(tokens.DEFAULT)
+	}
+	colon := p.expect(tokens.COLON).Pos
+	body := p.parseStatementList()
+	return &ast.CaseClause{Case: pos, List: list, Colon: colon, Body: body}
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	if p.trace {
+		defer un(trace(p, "ForStatement"))
+	}
+
 	pos := p.expect(tokens.FOR).Pos
-	var stmt1, stmt2, stmt3 Statement
+	prevLev := p.exprLev
+	p.exprLev = -1
+	var stmt1, stmt2, stmt3 ast.Statement
+	isRange := false
 	if p.token.Tok != tokens.LBRACE {
 		if p.token.Tok != tokens.SEMICOLON {
-			stmt2 = p.parseSimpleStatement()
+			stmt2 = p.parseSimpleStatement(true)
+			isRange = isRangeClause(stmt2)
 		}
-		if p.token.Tok == tokens.SEMICOLON {
+		if !isRange && p.token.Tok == tokens.SEMICOLON {
 			p.next()
 			stmt1 = stmt2
 			stmt2 = nil
 			if p.token.Tok != tokens.SEMICOLON {
-				stmt2 = p.parseSimpleStatement()
+				stmt2 = p.parseSimpleStatement(false)
 			}
 			p.optionalSemi()
 			if p.token.Tok != tokens.LBRACE {
-				stmt3 = p.parseSimpleStatement()
+				stmt3 = p.parseSimpleStatement(false)
 			}
 		}
 	}
+	p.exprLev = prevLev
 	body := p.parseBlockStatement()
-	return &ForStatement{Pos: pos, Init: stmt1, Cond: p.toExpr(stmt2, "boolean expression"), Post: stmt3, Body: body}
+	if isRange {
+		return p.toRangeStatement(pos, stmt2.(*ast.AssignStatement), body)
+	}
+	return &ast.ForStatement{For: pos, Init: stmt1, Cond: p.toExpr(stmt2, "boolean expression"), Post: stmt3, Body: body}
 }
 
-func (p *Parser) parseReturnStatement() *ReturnStatement {
+func isRangeClause(s ast.Statement) bool {
+	if assign, isAssign := s.(*ast.AssignStatement); isAssign && len(assign.Rhs) == 1 {
+		if unary, isUnary := assign.Rhs[0].(*ast.UnaryExpression); isUnary {
+			return unary.Operator == tokens.RANGE
+		}
+	}
+	return false
+}
+
+func (p *Parser) toRangeStatement(pos tokens.Position, clause *ast.AssignStatement, body *ast.BlockStatement) *ast.RangeStatement {
+	stmt := &ast.RangeStatement{For: pos, TokPos: clause.TokPos, Tok: clause.Tok, X: clause.Rhs[0].(*ast.UnaryExpression).X, Body: body}
+	switch len(clause.Lhs) {
+	case 0:
+	case 1:
+		stmt.Key = clause.Lhs[0]
+	case 2:
+		stmt.Key, stmt.Value = clause.Lhs[0], clause.Lhs[1]
+	default:
+		p.error(clause.TokPos, "range clause permits at most two iteration variables")
+	}
+	return stmt
+}
+
+func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	if p.trace {
+		defer un(trace(p, "ReturnStatement"))
+	}
+
 	pos := p.expect(tokens.RETURN).Pos
-	var expr []Expression
+	var expr []ast.Expression
 	if p.token.Tok != tokens.SEMICOLON && p.token.Tok != tokens.RBRACE {
 		expr = p.parseExpressionList()
 	}
-	return &ReturnStatement{Return: pos, Results: expr}
+	return &ast.ReturnStatement{Return: pos, Results: expr}
 }
 
-func (p *Parser) parseStatementList() (list []Statement) {
-	for p.token.Tok != tokens.RBRACE {
-		list = append(list, p.parseStatement())
+func (p *Parser) parseBranchStatement() *ast.BranchStatement {
+	if p.trace {
+		defer un(trace(p, "BranchStatement"))
+	}
+
+	tok := p.token
+	p.next()
+	var label *ast.Ident
+	if tok.Tok != tokens.FALLTHROUGH && p.token.Tok == tokens.IDENT {
+		label = p.parseIdent()
+	}
+	return &ast.BranchStatement{TokPos: tok.Pos, Tok: tok, Label: label}
+}
+
+// parseLabeledStatement parses the statement labeled by label, after the label; a label
+// before the end of a block or an empty statement labels no statement
+func (p *Parser) parseLabeledStatement(label *ast.Ident) *ast.LabeledStatement {
+	if p.trace {
+		defer un(trace(p, "LabeledStatement"))
+	}
+
+	colon := p.expect(tokens.COLON).Pos
+	var stmt ast.Statement
+	if p.token.Tok != tokens.RBRACE && p.token.Tok != tokens.SEMICOLON {
+		stmt = p.parseStatement()
+	}
+	return &ast.LabeledStatement{Label: label, Colon: colon, Stmt: stmt}
+}
+
+func (p *Parser) parseStatementList() (list []ast.Statement) {
+	if p.trace {
+		defer un(trace(p, "StatementList"))
+	}
+
+	for p.token.Tok != tokens.RBRACE && p.token.Tok != tokens.CASE && p.token.Tok != tokens.DEFAULT && p.token.Tok != tokens.EOF {
+		if p.token.Tok == tokens.SEMICOLON {
+			p.next()
+			continue
+		}
+		s := p.parseStatement()
+		list = append(list, s)
+		switch {
+		case p.token.Tok == tokens.CASE || p.token.Tok == tokens.DEFAULT:
+		case p.token.Tok == tokens.LBRACE && isControlStatement(s):
+			// a composite literal is not parsed in a control clause, so the block of a bare
+			// literal there ends the statement; the next block is parsed as a statement
+			p.error(p.token.Pos, "expected ; but found { (missing parentheses around composite literal?)")
+		default:
+			p.optionalSemi()
+		}
 	}
 	return
 }
 
-func (p *Parser) parseBlockStatement() *BlockStatement {
+func isControlStatement(s ast.Statement) bool {
+	switch s.(type) {
+	case *ast.IfStatement, *ast.ForStatement, *ast.RangeStatement, *ast.SwitchStatement:
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	if p.trace {
+		defer un(trace(p, "BlockStatement"))
+	}
+
 	begin := p.expect(tokens.LBRACE)
 	list := p.parseStatementList()
 	end := p.expect(tokens.RBRACE)
-	return &BlockStatement{LbracePos: begin.Pos, List: list, RbracePos: end.Pos}
+	return &ast.BlockStatement{LbracePos: begin.Pos, List: list, RbracePos: end.Pos}
 }
 
-func (p *Parser) parseUnaryExpression() (node Expression) {
+func (p *Parser) parseUnaryExpression() (node ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "UnaryExpression"))
+	}
+
 	switch p.token.Tok {
-	case tokens.ADD, tokens.SUB, tokens.NOT:
+	case tokens.ADD, tokens.SUB, tokens.NOT, tokens.XOR:
 		op := p.token
 		p.next()
-		return &UnaryExpression{Pos: op.Pos, Operator: op.Tok, X: p.parseExpression()}
+		return &ast.UnaryExpression{OpPos: op.Pos, Operator: op.Tok, X: p.parseUnaryExpression()}
+	case tokens.MUL:
+		star := p.token.Pos
+		p.next()
+		return &ast.StarExpression{Star: star, X: p.parseUnaryExpression()}
 	default:
 		return p.parsePrimaryExpression(nil)
 	}
 }
 
-func (p *Parser) parseBinaryExpression(expr Expression, prec tokens.TokenType) (node Expression) {
+// parseBinaryExpression parses operands joined by operators of precedence prec1 or higher,
+// so that operators of equal precedence associate to the left
+func (p *Parser) parseBinaryExpression(expr ast.Expression, prec1 int) (node ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "BinaryExpression"))
+	}
 
 	if expr == nil {
 		expr = p.parseUnaryExpression()
@@ -463,22 +1290,30 @@ func (p *Parser) parseBinaryExpression(expr Expression, prec tokens.TokenType) (
 
 	for {
 		operand := p.token
-		if operand.Tok < prec || operand.Tok >= tokens.DEFINE {
-			break
+		prec := operand.Tok.Precedence()
+		if prec < prec1 {
+			return expr
 		}
 		p.next()
 
-		right := p.parseBinaryExpression(nil, operand.Tok+1)
-		expr = &BinaryExpression{Pos: operand.Pos, Operator: operand.Tok, LeftX: expr, RightX: right}
+		right := p.parseBinaryExpression(nil, prec+1)
+		expr = &ast.BinaryExpression{OpPos: operand.Pos, Operator: operand.Tok, LeftX: expr, RightX: right}
 	}
-	return expr
 }
 
-func (p *Parser) parseExpression() (node Expression) {
-	return p.parseBinaryExpression(nil, tokens.ADD)
+func (p *Parser) parseExpression() (node ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "Expression"))
+	}
+
+	return p.parseBinaryExpression(nil, tokens.LowestPrec+1)
 }
 
-func (p *Parser) parseExpressionList() (list []Expression) {
+func (p *Parser) parseExpressionList() (list []ast.Expression) {
+	if p.trace {
+		defer un(trace(p, "ExpressionList"))
+	}
+
 	list = append(list, p.parseExpression())
 	for p.token.Tok == tokens.COMMA {
 		p.next()
@@ -487,11 +1322,42 @@ func (p *Parser) parseExpressionList() (list []Expression) {
 	return
 }
 
-func (p *Parser) parseConstSpec() *ValueSpec {
+func (p *Parser) parseImportSpec() *ast.ImportSpec {
+	if p.trace {
+		defer un(trace(p, "ImportSpec"))
+	}
+
+	spec := &ast.ImportSpec{}
+	switch p.token.Tok {
+	case tokens.IDENT:
+		spec.Name = p.parseIdent()
+	case tokens.PERIOD:
+		spec.Name = &ast.Ident{NamePos: p.token.Pos, Name: "."}
+		p.next()
+	}
+	spec.Path = &ast.BasicLiteral{ValuePos: p.token.Pos, Type: tokens.STRING, Value: p.token}
+	switch p.token.Tok {
+	case tokens.STRING:
+		p.next()
+	case tokens.INT, tokens.FLOAT, tokens.IMAG, tokens.CHAR:
+		p.error(p.token.Pos, "import path must be a string")
+		p.next()
+	default:
+		p.error(p.token.Pos, "missing import path")
+		p.advance(exprEnd)
+	}
+	return spec
+}
+
+func (p *Parser) parseConstSpec() *ast.ValueSpec {
+	if p.trace {
+		defer un(trace(p, "ConstSpec"))
+	}
+
 	idents := p.parseIdentList()
-	var typ Expression
-	var values []Expression
-	if p.token.Tok != tokens.EOF && p.token.Tok != tokens.RPAREN {
+	var typ ast.Expression
+	var values []ast.Expression
+	if p.token.Tok != tokens.EOF && p.token.Tok != tokens.RPAREN && p.token.Tok != tokens.SEMICOLON {
 		if p.token.Tok != tokens.ASSIGN {
 			typ = p.parseType()
 		}
@@ -500,13 +1366,17 @@ func (p *Parser) parseConstSpec() *ValueSpec {
 			values = p.parseExpressionList()
 		}
 	}
-	return &ValueSpec{Names: idents, Type: typ, Values: values}
+	return &ast.ValueSpec{Names: idents, Type: typ, Values: values}
 }
 
-func (p *Parser) parseVarSpec() *ValueSpec {
+func (p *Parser) parseVarSpec() *ast.ValueSpec {
+	if p.trace {
+		defer un(trace(p, "VarSpec"))
+	}
+
 	idents := p.parseIdentList()
-	var typ Expression
-	var values []Expression
+	var typ ast.Expression
+	var values []ast.Expression
 	if p.token.Tok != tokens.ASSIGN {
 		typ = p.parseType()
 	}
@@ -514,24 +1384,28 @@ func (p *Parser) parseVarSpec() *ValueSpec {
 		p.next()
 		values = p.parseExpressionList()
 	}
-	return &ValueSpec{Names: idents, Type: typ, Values: values}
+	return &ast.ValueSpec{Names: idents, Type: typ, Values: values}
 }
 
-func (p *Parser) parseFunctionDeclaration() *FunctionDeclaration {
+func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
+	if p.trace {
+		defer un(trace(p, "FunctionDeclaration"))
+	}
+
 	pos := p.expect(tokens.FUNC).Pos
 
 	ident := p.parseIdent()
-	typeParams, params := p.parseParameters(false)
+	typeParams, params := p.parseParameters(true)
 	results := p.parseResults()
-	var body *BlockStatement
+	var body *ast.BlockStatement
 	if p.token.Tok == tokens.LBRACE {
 		body = p.parseBlockStatement()
 	}
 
-	return &FunctionDeclaration{
+	return &ast.FunctionDeclaration{
 		Name: ident,
-		Type: &FunctionType{
-			Pos:        pos,
+		Type: &ast.FunctionType{
+			Func:       pos,
 			TypeParams: typeParams,
 			Params:     params,
 			Results:    results,
@@ -540,64 +1414,190 @@ func (p *Parser) parseFunctionDeclaration() *FunctionDeclaration {
 	}
 }
 
-func (p *Parser) parseTypeSpec() (node Spec) {
+func (p *Parser) parseTypeSpec() (node ast.Spec) {
+	if p.trace {
+		defer un(trace(p, "TypeSpec"))
+	}
 
 	name := p.parseIdent()
-	spec := &TypeSpec{Name: name}
+	spec := &ast.TypeSpec{Name: name}
 
 	if p.token.Tok == tokens.LBRACK {
+		// a type parameter list or an array length
+		lbrack := p.token.Pos
 		p.next()
-		spec.Type = p.parseArrayType()
-	} else {
-		if p.token.Tok == tokens.ASSIGN {
-			spec.AssignPos = p.token.Pos
+		if p.token.Tok == tokens.IDENT {
+			x := p.parseIdent()
+			switch p.token.Tok {
+			case tokens.IDENT, tokens.TILDE, tokens.COMMA, tokens.INTERFACE, tokens.LBRACK, tokens.FUNC, tokens.STRUCT:
+				spec.TypeParams = p.parseTypeParams(lbrack, x)
+			default:
+				p.exprLev++
+				length := p.parseBinaryExpression(p.parsePrimaryExpression(x), tokens.LowestPrec+1)
+				p.exprLev--
+				p.expect(tokens.RBRACK)
+				spec.Type = &ast.ArrayType{Lbrack: lbrack, Len: length, ElementType: p.parseType()}
+				return spec
+			}
+		} else if p.token.Tok == tokens.RBRACK {
 			p.next()
+			spec.Type = &ast.ArrayType{Lbrack: lbrack, ElementType: p.parseType()}
+			return spec
+		} else {
+			spec.Type = p.parseArrayType(lbrack)
+			return spec
 		}
-		spec.Type = p.parseType()
 	}
+
+	if p.token.Tok == tokens.ASSIGN {
+		spec.AssignPos = p.token.Pos
+		p.next()
+	}
+	spec.Type = p.parseType()
 	return spec
 }
 
-func (p *Parser) parseTopLevelDeclaration() (node Node) {
+func (p *Parser) parseTopLevelDeclaration() (node ast.Declaration) {
+	if p.trace {
+		defer un(trace(p, "TopLevelDeclaration"))
+	}
+
 	switch p.token.Tok {
 	case tokens.CONST, tokens.VAR, tokens.TYPE:
 		node = p.parseGenericDeclaration(p.token.Tok)
 	case tokens.FUNC:
 		node = p.parseFunctionDeclaration()
+	case tokens.IMPORT:
+		p.error(p.token.Pos, "imports must appear before other declarations")
+		node = p.parseGenericDeclaration(tokens.IMPORT)
+	default:
+		pos := p.token.Pos
+		p.errorExpected("declaration")
+		p.advance(declStart)
+		node = &ast.BadDeclaration{From: pos, To: p.token.Pos}
 	}
 	return
 }
 
+func (p *Parser) error(pos tokens.Position, msg string) {
+	if p.mode&AllErrors == 0 {
+		n := len(p.errors)
+		if n > 0 && p.errors[n-1].Pos.Line == pos.Line {
+			return // discard likely spurious errors on the same line
+		}
+		if n > 10 {
+			panic(bailout{})
+		}
+	}
+	p.errors = append(p.errors, &Error{Filename: p.filename, Pos: pos, Msg: msg})
+}
+
+func (p *Parser) errorExpected(what string) {
+	switch {
+	case p.token.Tok == tokens.ILLEGAL:
+		// the lexer error has been reported by next already
+	case p.token.Tok == tokens.SEMICOLON && p.token.Lit == "\n":
+		p.error(p.token.Pos, "expected "+what+" but found newline")
+	default:
+		p.error(p.token.Pos, "expected "+what+" but found "+p.token.Tok.String())
+	}
+}
+
+// expect reports an error if the current token is not tok; it advances in any case
+// so that the parser always makes progress
 func (p *Parser) expect(tok tokens.TokenType) tokens.Token {
+	node := p.token
 	if p.token.Tok != tok {
-		panic(p.token.Pos.ToString() + " expected " + tok.String() + " but found " + p.token.Tok.String())
+		p.errorExpected(tok.String())
 	}
-	node := p.token
 	p.next()
 	return node
 }
 
 func (p *Parser) optionalSemi() {
-	if p.token.Tok != tokens.RPAREN && p.token.Tok != tokens.RBRACE {
-		if p.token.Tok == tokens.SEMICOLON {
-			p.next()
-		} else {
-			panic(p.token.Pos.ToString() + " expected ;")
+	switch p.token.Tok {
+	case tokens.RPAREN, tokens.RBRACE, tokens.EOF:
+		// the enclosing production reports a missing closing token
+	case tokens.SEMICOLON:
+		p.next()
+	case tokens.COMMA:
+		// permit a ',' instead of a ';' but complain
+		p.errorExpected(";")
+		p.next()
+	default:
+		p.errorExpected(";")
+		p.advance(stmtStart)
+	}
+}
+
+// tokens the parser resynchronizes at after an error
+var (
+	stmtStart = map[tokens.TokenType]bool{
+		tokens.BREAK:       true,
+		tokens.CONST:       true,
+		tokens.CONTINUE:    true,
+		tokens.DEFER:       true,
+		tokens.FALLTHROUGH: true,
+		tokens.FOR:         true,
+		tokens.GO:          true,
+		tokens.GOTO:        true,
+		tokens.IF:          true,
+		tokens.RETURN:      true,
+		tokens.SELECT:      true,
+		tokens.SWITCH:      true,
+		tokens.TYPE:        true,
+		tokens.VAR:         true,
+	}
+
+	declStart = map[tokens.TokenType]bool{
+		tokens.CONST:  true,
+		tokens.FUNC:   true,
+		tokens.IMPORT: true,
+		tokens.TYPE:   true,
+		tokens.VAR:    true,
+	}
+
+	exprEnd = map[tokens.TokenType]bool{
+		tokens.COMMA:     true,
+		tokens.COLON:     true,
+		tokens.SEMICOLON: true,
+		tokens.RPAREN:    true,
+		tokens.RBRACK:    true,
+		tokens.RBRACE:    true,
+	}
+)
+
+// advance consumes tokens until the current token is in the 'to' set or EOF.
+// Resynchronizing at the same position more than a few times in a row means the
+// caller loops without consuming anything, so the token is skipped then
+func (p *Parser) advance(to map[tokens.TokenType]bool) {
+	for ; p.token.Tok != tokens.EOF; p.next() {
+		if !to[p.token.Tok] {
+			continue
+		}
+		if p.current == p.syncPos && p.syncCnt < 10 {
+			p.syncCnt++
+			return
+		}
+		if p.current > p.syncPos {
+			p.syncPos = p.current
+			p.syncCnt = 0
+			return
 		}
 	}
 }
 
-func (p *Parser) toExpr(s Statement, expected string) Expression {
+func (p *Parser) toExpr(s ast.Statement, expected string) ast.Expression {
 	if s == nil {
 		return nil
 	}
-	if expr, isExpr := s.(*ExpressionStatement); isExpr {
+	if expr, isExpr := s.(*ast.ExpressionStatement); isExpr {
 		return expr.X
 	}
-	if _, isAssign := s.(*AssignStatement); isAssign {
-		panic(p.token.Pos.ToString() + " expected " + expected + "but found assignment")
+	if _, isAssign := s.(*ast.AssignStatement); isAssign {
+		p.error(p.token.Pos, "expected "+expected+" but found assignment")
 	} else {
-		panic(p.token.Pos.ToString() + " expected " + expected + "but found simple statement")
+		p.error(p.token.Pos, "expected "+expected+" but found simple statement")
 	}
-	//return &BadExpression{From: p.token.Pos, To: p.token.Pos}
+	return &ast.BadExpression{From: p.token.Pos, To: p.token.Pos}
 }
@@ -1,11 +1,15 @@
 package parser
 
 import (
+	"flag"
 	"fmt"
-	"gocompiler/src/lexer"
+	"gocompiler/src/ast"
+	"gocompiler/src/astjson"
 	"gocompiler/src/tokens"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -22,22 +26,10 @@ func readInput(filename string) string {
 	return strings.ReplaceAll(string(b), "\r", "")
 }
 
-func performTest(t *testing.T, input string, expect string) {
-	lexerInstance := lexer.NewLexer(strings.NewReader(input))
-	var tokenList []tokens.Token
-	for {
-		pos, tok, lex, lit := lexerInstance.Lex()
-		tokenList = append(tokenList, tokens.Token{Pos: pos, Tok: tok, Lex: lex, Lit: lit})
-		if tok == tokens.EOF || tok == tokens.ILLEGAL {
-			break
-		}
-	}
-	parserInstance := NewParser(tokenList)
-	astTree := parserInstance.Parse()
-	result := PrintAST(astTree)
-	if result != expect {
-		t.Errorf("expected %s got %s", expect, result)
-	}
+func parseInput(input string) ([]ast.Node, ErrorList) {
+	parserInstance := NewParser(tokenize([]byte(input)))
+	nodes := parserInstance.Parse()
+	return nodes, parserInstance.Errors()
 }
 
 func testPath(path string, input bool) string {
@@ -47,42 +39,428 @@ func testPath(path string, input bool) string {
 		return "../tests/parser/output/" + path + "/test"
 	}
 }
-func runTestFolder(t *testing.T, path string, amount int) {
+
+var update = flag.Bool("update", false, "rewrite the structure golden files from the parser output")
+
+// clearPositions zeroes the positions in the tree at v, so that its encoding records the structure only
+func clearPositions(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			clearPositions(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			clearPositions(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(tokens.Position{}) {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() && field.Name != "Obj" {
+				clearPositions(v.Field(i))
+			}
+		}
+	}
+}
+
+// runStructureFolder decodes the trees stored as JSON in src/tests/parser/structure and compares
+// them with the trees parsed from the inputs of the folder, so that changes of the tree drawing do not matter
+func runStructureFolder(t *testing.T, path string, amount int) {
+	for i := 1; i <= amount; i++ {
+		input := readInput(testPath(path, true) + fmt.Sprint(i) + ".txt")
+		f, err := ParseFile(tokens.NewFileSet(), "", input, 0)
+		if err != nil {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		golden := "../tests/parser/structure/" + path + "/test" + fmt.Sprint(i) + ".json"
+		if *update {
+			stripped := ast.Clone(f)
+			clearPositions(reflect.ValueOf(stripped))
+			b, err := astjson.MarshalIndent(stripped, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, append(b, '\n'), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := astjson.Unmarshal([]byte(readInput(golden)))
+		if err != nil {
+			t.Errorf("test %d: %v", i, err)
+			continue
+		}
+		if !ast.Equal(f, expected, ast.IgnorePositions) {
+			t.Errorf("test %d: expected\n%s got\n%s", i, ast.Sprint(expected, ast.IgnorePositions), ast.Sprint(f, ast.IgnorePositions))
+		}
+	}
+}
+
+// runErrorFolder checks that every input of the folder produces exactly the listed errors, one per line
+func runErrorFolder(t *testing.T, path string, amount int) {
 	for i := 1; i <= amount; i++ {
 		input := readInput(testPath(path, true) + fmt.Sprint(i) + ".txt")
 		expected := readInput(testPath(path, false) + fmt.Sprint(i) + ".txt")
-		performTest(t, input, expected)
+		_, errs := parseInput(input)
+		var result strings.Builder
+		for _, err := range errs {
+			result.WriteString(err.Error() + "\n")
+		}
+		if result.String() != expected {
+			t.Errorf("test %d: expected errors\n%s got\n%s", i, expected, result.String())
+		}
 	}
 }
 
 func TestFunctions(t *testing.T) {
-	const testAmount = 4
+	const testAmount = 6
 	const path = "functions"
-	runTestFolder(t, path, testAmount)
+	runStructureFolder(t, path, testAmount)
 }
 
 func TestVarDeclarations(t *testing.T) {
-	runTestFolder(t, "var", 3)
+	runStructureFolder(t, "var", 3)
 }
 
 func TestStructs(t *testing.T) {
-	const testAmount = 3
+	const testAmount = 5
 	const path = "structs"
-	runTestFolder(t, path, testAmount)
+	runStructureFolder(t, path, testAmount)
+}
+
+func TestStructTags(t *testing.T) {
+	nodes, _ := parseInput(readInput(testPath("structs", true) + "4.txt"))
+	fields := nodes[0].(*ast.GenericDeclaration).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields
+	expected := []struct {
+		key, value string
+		ok         bool
+	}{
+		{"json", "", false},
+		{"json", "", false},
+		{"json", "", false},
+		{"json", "", false},
+		{"json", "id", true},
+		{"json", "name,omitempty", true},
+		{"db", "tags", true},
+	}
+	for i, field := range fields.List {
+		value, ok := field.StructTag().Lookup(expected[i].key)
+		if value != expected[i].value || ok != expected[i].ok {
+			t.Errorf("field %d: expected %q %v for key %s, got %q %v", i, expected[i].value, expected[i].ok, expected[i].key, value, ok)
+		}
+	}
+	tag := ast.StructTag(`json:"a\"b" xml:"-"  broken`)
+	if got := tag.Get("json"); got != `a"b` {
+		t.Errorf("expected escaped value, got %q", got)
+	}
+	if got, ok := tag.Lookup("xml"); got != "-" || !ok {
+		t.Errorf("expected xml value, got %q", got)
+	}
+	if _, ok := tag.Lookup("broken"); ok {
+		t.Errorf("malformed key must not be found")
+	}
+}
+
+func TestTypes(t *testing.T) {
+	runStructureFolder(t, "types", 3)
+}
+
+func TestMissingType(t *testing.T) {
+	nodes, errs := parseInput("var x\n")
+	if errs.Error() != "1:6: expected type but found newline" {
+		t.Errorf("expected missing type diagnostic, got %q", errs.Error())
+	}
+	if _, bad := nodes[0].(*ast.GenericDeclaration).Specs[0].(*ast.ValueSpec).Type.(*ast.BadExpression); !bad {
+		t.Errorf("expected bad expression in place of the type")
+	}
+}
+
+func TestSemicolons(t *testing.T) {
+	runStructureFolder(t, "semicolons", 2)
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	for _, test := range []struct{ src, expected string }{
+		{"var a = []int{1 2}", "1:17: missing ',' in composite literal"},
+		{"func main() {\n    use(Point{x\n    })\n}", "2:16: missing ',' before newline in composite literal"},
+		{"x := 1", "1:1: expected declaration but found IDENT"},
+	} {
+		if _, errs := parseInput(test.src); errs.Error() != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.src, test.expected, errs.Error())
+		}
+	}
 }
 
 func TestArrays(t *testing.T) {
-	runTestFolder(t, "arrays", 2)
+	runStructureFolder(t, "arrays", 2)
 }
 
 func TestIfStatements(t *testing.T) {
-	runTestFolder(t, "if_statements", 2)
+	runStructureFolder(t, "if_statements", 4)
+}
+
+func TestBareCompositeLiteralInHeader(t *testing.T) {
+	for _, test := range []struct{ src, expected string }{
+		{"func main() {\n    if p == Point{1, 2} {\n    }\n}", "2:25: expected ; but found { (missing parentheses around composite literal?)"},
+		{"func main() {\n    for _, v := range T{1} {\n    }\n}", "2:28: expected ; but found { (missing parentheses around composite literal?)"},
+		{"func main() {\n    switch T{} {\n    }\n}", "2:16: expected ; but found { (missing parentheses around composite literal?)"},
+		{"func main() {\n    if x {} {}\n}", "2:13: expected ; but found { (missing parentheses around composite literal?)"},
+	} {
+		if _, errs := parseInput(test.src); errs.Error() != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.src, test.expected, errs.Error())
+		}
+	}
+	nodes, _ := parseInput("func main() {\n    if x {} {}\n}")
+	body := nodes[0].(*ast.FunctionDeclaration).Body.List
+	if len(body) != 2 {
+		t.Fatalf("expected the if statement and a block, got %d statements", len(body))
+	}
+	if _, isIdent := body[0].(*ast.IfStatement).Cond.(*ast.Ident); !isIdent {
+		t.Errorf("expected the condition x, got %T", body[0].(*ast.IfStatement).Cond)
+	}
+}
+
+func TestSwitchStatements(t *testing.T) {
+	runStructureFolder(t, "switch_statements", 3)
 }
 
 func TestForStatements(t *testing.T) {
-	runTestFolder(t, "for_statements", 5)
+	runStructureFolder(t, "for_statements", 7)
 }
 
 func TestEpxressions(t *testing.T) {
-	runTestFolder(t, "expressions", 4)
+	runStructureFolder(t, "expressions", 4)
+}
+
+func TestPrecedence(t *testing.T) {
+	runStructureFolder(t, "precedence", 8)
+}
+
+func TestErrors(t *testing.T) {
+	runErrorFolder(t, "errors", 4)
+}
+
+const fileSource = `package main
+
+// imports
+import "fmt"
+import (
+	str "strings"
+	. "math"
+)
+
+/* entry
+point */
+func main() {
+	fmt.Println(str.ToUpper("a"), Pi) // trailing
+}
+`
+
+func TestParseFile(t *testing.T) {
+	fset := tokens.NewFileSet()
+	f, err := ParseFile(fset, "main.go", fileSource, ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name.Name != "main" || f.Package != (tokens.Position{Line: 1, Column: 1}) {
+		t.Errorf("unexpected package clause %v at %v", f.Name, f.Package)
+	}
+	paths := []string{"fmt", "strings", "math"}
+	names := []string{"", "str", "."}
+	if len(f.Imports) != len(paths) {
+		t.Fatalf("expected %d imports, got %d", len(paths), len(f.Imports))
+	}
+	for i, spec := range f.Imports {
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if spec.Path.Value.LexString() != paths[i] || name != names[i] {
+			t.Errorf("import %d: expected %s %q, got %s %q", i, names[i], paths[i], name, spec.Path.Value.LexString())
+		}
+	}
+	if len(f.Decls) != 3 || len(f.Comments) != 3 {
+		t.Errorf("expected 3 declarations and 3 comments, got %d and %d", len(f.Decls), len(f.Comments))
+	}
+	if file := fset.File("main.go"); file == nil || file.LineCount() != 15 {
+		t.Errorf("file is not registered in the file set")
+	}
+
+	f, _ = ParseFile(fset, "main.go", fileSource, PackageClauseOnly)
+	if f.Name.Name != "main" || len(f.Decls) != 0 || len(f.Comments) != 0 {
+		t.Errorf("expected only the package clause, got %d declarations", len(f.Decls))
+	}
+	f, _ = ParseFile(fset, "main.go", fileSource, ImportsOnly)
+	if len(f.Decls) != 2 || len(f.Imports) != 3 {
+		t.Errorf("expected only the imports, got %d declarations", len(f.Decls))
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	fset := tokens.NewFileSet()
+	if _, err := ParseFile(fset, "missing.go", nil, 0); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+	_, err := ParseFile(fset, "bad.go", "var x = )\nimport \"fmt\"\n", 0)
+	expected := "bad.go:1:9: expected operand but found ) (and 1 more errors)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	src := strings.Repeat("var = 1\n", 15)
+	_, err = ParseFile(fset, "many.go", src, 0)
+	if n := err.(ErrorList).Len(); n != 11 {
+		t.Errorf("expected parsing to stop after 11 errors, got %d", n)
+	}
+	_, err = ParseFile(fset, "many.go", src, AllErrors)
+	if n := err.(ErrorList).Len(); n != 30 {
+		t.Errorf("expected all 30 errors, got %d", n)
+	}
+
+	if f, err := ParseFile(fset, "empty.go", "", 0); err != nil || f.Name != nil || len(f.Decls) != 0 {
+		t.Errorf("expected an empty file, got %v", err)
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	expr, err := ParseExpr("a + b*c[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := ParseFile(tokens.NewFileSet(), "", "var _ = a + b*c[1]", 0)
+	expected := f.Decls[0].(*ast.GenericDeclaration).Specs[0].(*ast.ValueSpec).Values[0]
+	if !ast.Equal(expr, expected, ast.IgnorePositions) {
+		t.Errorf("expected\n%s got\n%s", ast.Sprint(expected, ast.IgnorePositions), ast.Sprint(expr, ast.IgnorePositions))
+	}
+	if _, err := ParseExpr("a + b }"); err == nil || err.Error() != "1:7: expected EOF but found }" {
+		t.Errorf("expected trailing token error, got %v", err)
+	}
+	if _, err := ParseExpr(""); err == nil || err.Error() != "1:1: expected operand but found EOF" {
+		t.Errorf("expected missing operand error for empty input, got %v", err)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var out strings.Builder
+	p := NewParser(tokenize([]byte("var x = -1")))
+	p.SetTrace(&out)
+	p.Parse()
+	expected := `    1:  1: "var"
+    1:  1: File (
+    1:  1: . TopLevelDeclaration (
+    1:  1: . . GenericDeclaration (
+    1:  5: . . . IDENT x
+    1:  5: . . . VarSpec (
+    1:  5: . . . . IdentList (
+    1:  5: . . . . . Ident (
+    1:  7: . . . . . . =
+    1:  7: . . . . . )
+    1:  7: . . . . )
+    1:  9: . . . . -
+    1:  9: . . . . ExpressionList (
+    1:  9: . . . . . Expression (
+    1:  9: . . . . . . BinaryExpression (
+    1:  9: . . . . . . . UnaryExpression (
+    1: 10: . . . . . . . . INT 1
+    1: 10: . . . . . . . . UnaryExpression (
+    1: 10: . . . . . . . . . PrimaryExpression (
+    1: 10: . . . . . . . . . . Operand (
+    1: 10: . . . . . . . . . . . Literal (
+    1: 11: . . . . . . . . . . . . ; newline
+    1: 11: . . . . . . . . . . . )
+    1: 11: . . . . . . . . . . )
+    1: 11: . . . . . . . . . )
+    1: 11: . . . . . . . . )
+    1: 11: . . . . . . . )
+    1: 11: . . . . . . )
+    1: 11: . . . . . )
+    1: 11: . . . . )
+    1: 11: . . . )
+    1: 11: . . )
+    1: 11: . )
+    2:  1: . EOF
+    2:  1: )
+`
+	if out.String() != expected {
+		t.Errorf("expected trace\n%s got\n%s", expected, out.String())
+	}
+}
+
+// sourceText returns the text of src between the positions from and to
+func sourceText(src string, from, to tokens.Position) string {
+	offset := func(pos tokens.Position) int {
+		lines := strings.SplitAfter(src, "\n")
+		n := 0
+		for _, line := range lines[:pos.Line-1] {
+			n += len(line)
+		}
+		return n + len(string([]rune(lines[pos.Line-1])[:pos.Column-1]))
+	}
+	return src[offset(from):offset(to)]
+}
+
+func TestPositions(t *testing.T) {
+	const src = `package main
+
+type Point struct {
+	x, y int ` + "`json:\"x\"`" + `
+}
+
+func dist(q ...Point) (int, error) {
+	a := []int{1, 2}
+	if x := a[0]; x > 0 {
+		x++
+	} else {
+		return -x, nil
+	}
+	for i := range a {
+		f(i, g(a...))
+	}
+	switch {
+	case true:
+	}
+	return *p + (q), nil
+}
+`
+	f, err := ParseFile(tokens.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := f.Decls[0].(*ast.GenericDeclaration)
+	fn := f.Decls[1].(*ast.FunctionDeclaration)
+	body := fn.Body.List
+	ifStmt := body[1].(*ast.IfStatement)
+	forStmt := body[2].(*ast.RangeStatement)
+	ret := body[4].(*ast.ReturnStatement)
+	expected := []struct {
+		node ast.Node
+		text string
+	}{
+		{f, src[:len(src)-1]},
+		{typ, "type Point struct {\n\tx, y int `json:\"x\"`\n}"},
+		{typ.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields.List[0], "x, y int `json:\"x\"`"},
+		{fn.Type, "func dist(q ...Point) (int, error)"},
+		{fn.Type.Params, "(q ...Point)"},
+		{body[0], "a := []int{1, 2}"},
+		{ifStmt.Init, "x := a[0]"},
+		{ifStmt.Body.List[0], "x++"},
+		{ifStmt.Else, "{\n\t\treturn -x, nil\n\t}"},
+		{forStmt.X, "a"},
+		{forStmt.Body.List[0], "f(i, g(a...))"},
+		{body[3].(*ast.SwitchStatement).Body.List[0], "case true:"},
+		{ret, "return *p + (q), nil"},
+		{ret.Results[0].(*ast.BinaryExpression).LeftX, "*p"},
+	}
+	for i, e := range expected {
+		if text := sourceText(src, e.node.Pos(), e.node.End()); text != e.text {
+			t.Errorf("node %d: expected span %q, got %q", i, e.text, text)
+		}
+	}
 }
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"fmt"
+	"gocompiler/src/tokens"
+	"io"
+	"strings"
+)
+
+// SetTrace makes the parser print the productions it enters and leaves, and the tokens it moves to, to w,
+// starting with the current token. A nil w turns tracing off
+func (p *Parser) SetTrace(w io.Writer) {
+	p.trace = w != nil
+	p.traceOut = w
+	if p.trace {
+		p.traceToken()
+	}
+}
+
+// printTrace prints the position of the current token followed by the arguments indented by the nesting level
+func (p *Parser) printTrace(a ...any) {
+	pos := p.token.Pos
+	fmt.Fprintf(p.traceOut, "%5d:%3d: %s", pos.Line, pos.Column, strings.Repeat(". ", p.indent))
+	fmt.Fprintln(p.traceOut, a...)
+}
+
+// trace is used as "if p.trace { defer un(trace(p, "Production")) }"
+func trace(p *Parser, msg string) *Parser {
+	p.printTrace(msg, "(")
+	p.indent++
+	return p
+}
+
+func un(p *Parser) {
+	p.indent--
+	p.printTrace(")")
+}
+
+// traceToken prints the token the parser has just moved to
+func (p *Parser) traceToken() {
+	tok := p.token.Tok
+	switch {
+	case tok == tokens.SEMICOLON && p.token.Lit == "\n":
+		p.printTrace(tok.String(), "newline")
+	case tok.IsLiteral(), tok == tokens.ILLEGAL, tok == tokens.COMMENT:
+		p.printTrace(tok.String(), p.token.Lit)
+	case tok.IsKeyword():
+		p.printTrace(`"` + tok.String() + `"`)
+	default:
+		p.printTrace(tok.String())
+	}
+}
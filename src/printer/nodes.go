@@ -0,0 +1,1117 @@
+package printer
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+	"math"
+)
+
+// files and declarations
+
+func (p *printer) file(f *ast.File) {
+	if f.Name != nil {
+		p.print(f.Package, "package")
+		p.blank()
+		p.expr(f.Name)
+	}
+	p.declList(f.Decls)
+	p.flush(tokens.Position{Line: math.MaxInt})
+	if p.output.Len() > 0 {
+		p.output.WriteByte('\n')
+	}
+}
+
+// declToken returns the token of a generic declaration, FUNC for function declarations
+func declToken(d ast.Declaration) tokens.TokenType {
+	switch d := d.(type) {
+	case *ast.GenericDeclaration:
+		return d.Token
+	case *ast.FunctionDeclaration:
+		return tokens.FUNC
+	}
+	return tokens.ILLEGAL
+}
+
+// hasDoc reports whether the unprinted comments before d end on the line before d
+func (p *printer) hasDoc(d ast.Declaration) bool {
+	end := 0
+	for _, c := range p.comments {
+		if !before(c.Slash, d.Pos()) {
+			break
+		}
+		end = c.End().Line
+	}
+	return end > 0 && end == d.Pos().Line-1
+}
+
+func (p *printer) declList(list []ast.Declaration) {
+	tok := tokens.ILLEGAL
+	for _, d := range list {
+		prev := tok
+		tok = declToken(d)
+		if p.output.Len() > 0 {
+			min := 1
+			if prev != tok || p.hasDoc(d) {
+				min = 2
+			}
+			p.linebreak(d.Pos().Line, min, tok == tokens.FUNC && d.End().Line > d.Pos().Line)
+		}
+		p.decl(d)
+	}
+}
+
+func (p *printer) decl(d ast.Declaration) {
+	switch d := d.(type) {
+	case *ast.BadDeclaration:
+		p.print(d.From, "BadDecl")
+	case *ast.GenericDeclaration:
+		p.genDecl(d)
+	case *ast.FunctionDeclaration:
+		p.funcDecl(d)
+	default:
+		panic(fmt.Sprintf("printer: unexpected declaration %T", d))
+	}
+}
+
+func (p *printer) genDecl(d *ast.GenericDeclaration) {
+	p.print(d.TokPos, d.Token.String())
+	p.blank()
+	if !d.LParenPos.IsValid() {
+		if len(d.Specs) > 0 {
+			p.spec(d.Specs[0], 1)
+		}
+		return
+	}
+	p.print(d.LParenPos, "(")
+	if n := len(d.Specs); n > 0 {
+		p.indent++
+		p.newlines = 1
+		p.section = true
+		line := 0
+		var keepType []bool
+		if n > 1 && (d.Token == tokens.CONST || d.Token == tokens.VAR) {
+			keepType = keepTypeColumn(d.Specs)
+		}
+		for i, s := range d.Specs {
+			if i > 0 {
+				p.linebreak(s.Pos().Line, 1, p.linesFrom(line) > 0)
+			}
+			line = p.recordLine()
+			if keepType != nil {
+				p.valueSpec(s.(*ast.ValueSpec), keepType[i])
+			} else {
+				p.spec(s, n)
+			}
+		}
+		p.flush(d.RParenPos)
+		p.indent--
+		p.linebreak(d.RParenPos.Line, 1, true)
+	}
+	p.print(d.RParenPos, ")")
+}
+
+// keepTypeColumn reports for each spec of a const or var group whether the column for types
+// is kept: in a run of specs with values the column is kept if any spec of the run has a type
+func keepTypeColumn(specs []ast.Spec) []bool {
+	m := make([]bool, len(specs))
+	populate := func(i, j int, keepType bool) {
+		if keepType {
+			for ; i < j; i++ {
+				m[i] = true
+			}
+		}
+	}
+	i0 := -1 // if i0 >= 0 we are in a run and i0 is the start of the run
+	var keepType bool
+	for i, s := range specs {
+		t := s.(*ast.ValueSpec)
+		if t.Values != nil {
+			if i0 < 0 {
+				// start of a run of specs with values
+				i0 = i
+				keepType = false
+			}
+		} else {
+			if i0 >= 0 {
+				// end of a run
+				populate(i0, i, keepType)
+				i0 = -1
+			}
+		}
+		if t.Type != nil {
+			keepType = true
+		}
+	}
+	if i0 >= 0 {
+		populate(i0, len(specs), keepType)
+	}
+	return m
+}
+
+// valueSpec prints a spec of a const or var group in columns of names, types and values
+func (p *printer) valueSpec(s *ast.ValueSpec, keepType bool) {
+	p.identList(s.Names)
+	extraTabs := 3
+	if s.Type != nil || keepType {
+		p.vtab()
+		extraTabs--
+	}
+	if s.Type != nil {
+		p.expr(s.Type)
+	}
+	if s.Values != nil {
+		p.vtab()
+		p.print(tokens.Position{}, "=")
+		p.blank()
+		p.exprList(tokens.Position{}, s.Values, 1, 0, tokens.Position{})
+		extraTabs--
+	}
+	if p.hasLineComment(s.End().Line) {
+		for ; extraTabs > 0; extraTabs-- {
+			p.vtab()
+		}
+	}
+}
+
+// spec prints a spec of a declaration with n specs
+func (p *printer) spec(s ast.Spec, n int) {
+	switch s := s.(type) {
+	case *ast.ImportSpec:
+		if s.Name != nil {
+			p.expr(s.Name)
+			p.blank()
+		}
+		p.expr(s.Path)
+
+	case *ast.ValueSpec:
+		p.identList(s.Names)
+		if s.Type != nil {
+			p.blank()
+			p.expr(s.Type)
+		}
+		if s.Values != nil {
+			p.blank()
+			p.print(tokens.Position{}, "=")
+			p.blank()
+			p.exprList(tokens.Position{}, s.Values, 1, 0, tokens.Position{})
+		}
+
+	case *ast.TypeSpec:
+		p.expr(s.Name)
+		if s.TypeParams != nil {
+			p.parameters(s.TypeParams, "[", "]")
+		}
+		if n == 1 {
+			p.blank()
+		} else {
+			p.vtab()
+		}
+		if s.AssignPos.IsValid() {
+			p.print(s.AssignPos, "=")
+			p.blank()
+		}
+		p.expr(s.Type)
+
+	default:
+		panic(fmt.Sprintf("printer: unexpected spec %T", s))
+	}
+}
+
+func (p *printer) funcDecl(d *ast.FunctionDeclaration) {
+	p.print(d.Type.Func, "func")
+	p.blank()
+	p.expr(d.Name)
+	p.signature(d.Type)
+	if d.Body != nil {
+		header := &ast.FunctionDeclaration{Name: d.Name, Type: d.Type}
+		p.funcBody(p.nodeSize(header, math.MaxInt32), true, d.Body)
+	}
+}
+
+// funcBody prints the body of a function after a header of headerSize characters; a small body
+// written on one line stays on one line. Bodies of function declarations are aligned with vtab
+func (p *printer) funcBody(headerSize int, vtab bool, b *ast.BlockStatement) {
+	if b == nil {
+		return
+	}
+	const maxSize = 100
+	if headerSize+p.bodySize(b, maxSize) <= maxSize {
+		if vtab {
+			p.vtab()
+		} else {
+			p.blank()
+		}
+		p.print(b.LbracePos, "{")
+		if len(b.List) > 0 {
+			p.blank()
+			for i, s := range b.List {
+				if i > 0 {
+					p.print(tokens.Position{}, ";")
+					p.blank()
+				}
+				p.stmt(s, i == len(b.List)-1)
+			}
+			p.blank()
+		}
+		p.print(b.RbracePos, "}")
+		return
+	}
+	p.blank()
+	p.block(b, 1)
+}
+
+// bodySize estimates the size of the body b printed on one line; it is larger than maxSize
+// if the body does not fit or spans several lines in the source
+func (p *printer) bodySize(b *ast.BlockStatement, maxSize int) int {
+	if b.LbracePos.Line != b.RbracePos.Line || len(b.List) > 5 {
+		return maxSize + 1
+	}
+	size := p.commentSizeBefore(b.RbracePos)
+	for i, s := range b.List {
+		if size > maxSize {
+			break
+		}
+		if i > 0 {
+			size += 2 // space for a semicolon and blank
+		}
+		size += p.nodeSize(s, maxSize)
+	}
+	return size
+}
+
+// statements
+
+func (p *printer) block(b *ast.BlockStatement, nindent int) {
+	p.print(b.LbracePos, "{")
+	p.stmtList(b.List, nindent, b.RbracePos, false)
+	p.linebreak(b.RbracePos.Line, 1, true)
+	p.print(b.RbracePos, "}")
+}
+
+// stmtList prints the statements of a block or case clause indented by nindent levels,
+// followed by the comments before next, the position of the closing brace or the following
+// case. A comment on a line of its own aligned with the following case belongs to the case
+func (p *printer) stmtList(list []ast.Statement, nindent int, next tokens.Position, nextIsCase bool) {
+	p.indent += nindent
+	line := 0
+	for i, s := range list {
+		p.linebreak(s.Pos().Line, 1, i == 0 || nindent == 0 || p.linesFrom(line) > 0)
+		line = p.recordLine()
+		p.stmt(s, i == len(list)-1)
+	}
+	for p.commentBefore(next) {
+		if c := p.comments[0]; nextIsCase && c.Slash.Line > p.last && c.Slash.Column == next.Column {
+			break
+		}
+		p.comment(next)
+	}
+	p.indent -= nindent
+}
+
+func (p *printer) stmt(s ast.Statement, nextIsRBrace bool) {
+	switch s := s.(type) {
+	case *ast.BadStatement:
+		p.print(s.From, "BadStmt")
+
+	case *ast.DeclarationStatement:
+		p.decl(s.Decl)
+
+	case *ast.ExpressionStatement:
+		p.expr0(s.X, 1)
+
+	case *ast.IncDecStatement:
+		p.expr0(s.X, 2)
+		p.print(s.TokPos, s.Tok.Tok.String())
+
+	case *ast.AssignStatement:
+		depth := 1
+		if len(s.Lhs) > 1 && len(s.Rhs) > 1 {
+			depth++
+		}
+		p.exprList(s.Pos(), s.Lhs, depth, 0, s.TokPos)
+		p.blank()
+		p.print(s.TokPos, s.Tok.Tok.String())
+		p.blank()
+		p.exprList(s.TokPos, s.Rhs, depth, 0, tokens.Position{})
+
+	case *ast.ReturnStatement:
+		p.print(s.Return, "return")
+		if s.Results != nil {
+			p.blank()
+			p.exprList(tokens.Position{}, s.Results, 1, 0, tokens.Position{})
+		}
+
+	case *ast.BranchStatement:
+		p.print(s.TokPos, s.Tok.Tok.String())
+		if s.Label != nil {
+			p.blank()
+			p.expr(s.Label)
+		}
+
+	case *ast.LabeledStatement:
+		// the label is outdented by one level, the statement starts on the next line
+		p.indent--
+		p.expr(s.Label)
+		p.print(s.Colon, ":")
+		p.indent++
+		if s.Stmt != nil {
+			p.linebreak(s.Stmt.Pos().Line, 1, true)
+			p.stmt(s.Stmt, nextIsRBrace)
+		}
+
+	case *ast.BlockStatement:
+		p.block(s, 1)
+
+	case *ast.IfStatement:
+		p.print(s.If, "if")
+		p.controlClause(false, s.Init, s.Cond, nil)
+		p.block(s.Body, 1)
+		if s.Else != nil {
+			p.blank()
+			p.print(tokens.Position{}, "else")
+			p.blank()
+			p.stmt(s.Else, nextIsRBrace)
+		}
+
+	case *ast.CaseClause:
+		p.caseClause(s, tokens.Position{}, false)
+
+	case *ast.SwitchStatement:
+		p.print(s.Switch, "switch")
+		p.controlClause(false, s.Init, s.Tag, nil)
+		p.switchBody(s.Body)
+
+	case *ast.ForStatement:
+		p.print(s.For, "for")
+		p.controlClause(true, s.Init, s.Cond, s.Post)
+		p.block(s.Body, 1)
+
+	case *ast.RangeStatement:
+		p.print(s.For, "for")
+		p.blank()
+		if s.Key != nil {
+			p.expr(s.Key)
+			if s.Value != nil {
+				p.print(tokens.Position{}, ",")
+				p.blank()
+				p.expr(s.Value)
+			}
+			p.blank()
+			p.print(s.TokPos, s.Tok.Tok.String())
+			p.blank()
+		}
+		p.print(tokens.Position{}, "range")
+		p.blank()
+		p.expr(stripParens(s.X))
+		p.blank()
+		p.block(s.Body, 1)
+
+	default:
+		panic(fmt.Sprintf("printer: unexpected statement %T", s))
+	}
+}
+
+// switchBody prints the case clauses of a switch statement at the indentation of the switch
+func (p *printer) switchBody(b *ast.BlockStatement) {
+	p.print(b.LbracePos, "{")
+	for i, s := range b.List {
+		p.linebreak(s.Pos().Line, 1, true)
+		if i+1 < len(b.List) {
+			p.caseClause(s.(*ast.CaseClause), b.List[i+1].Pos(), true)
+		} else {
+			p.caseClause(s.(*ast.CaseClause), b.RbracePos, false)
+		}
+	}
+	p.flush(b.RbracePos)
+	p.linebreak(b.RbracePos.Line, 1, true)
+	p.print(b.RbracePos, "}")
+}
+
+// caseClause prints a case clause followed by the comments of its body before next, see stmtList
+func (p *printer) caseClause(s *ast.CaseClause, next tokens.Position, nextIsCase bool) {
+	if s.List != nil {
+		p.print(s.Case, "case")
+		p.blank()
+		p.exprList(s.Pos(), s.List, 1, 0, s.Colon)
+	} else {
+		p.print(s.Case, "default")
+	}
+	p.print(s.Colon, ":")
+	p.stmtList(s.Body, 1, next, nextIsCase)
+}
+
+func (p *printer) controlClause(isForStmt bool, init ast.Statement, expr ast.Expression, post ast.Statement) {
+	p.blank()
+	needsBlank := false
+	if init == nil && post == nil {
+		// no semicolons required
+		if expr != nil {
+			p.expr(stripParens(expr))
+			needsBlank = true
+		}
+	} else {
+		// all semicolons required
+		if init != nil {
+			p.stmt(init, false)
+		}
+		p.print(tokens.Position{}, ";")
+		p.blank()
+		if expr != nil {
+			p.expr(stripParens(expr))
+			needsBlank = true
+		}
+		if isForStmt {
+			p.print(tokens.Position{}, ";")
+			p.blank()
+			needsBlank = false
+			if post != nil {
+				p.stmt(post, false)
+				needsBlank = true
+			}
+		}
+	}
+	if needsBlank {
+		p.blank()
+	}
+}
+
+// stripParens removes the parentheses around a condition unless they protect a composite literal
+func stripParens(x ast.Expression) ast.Expression {
+	if px, strip := x.(*ast.ParenExpression); strip {
+		ast.Inspect(px.X, func(node ast.Node) bool {
+			switch x := node.(type) {
+			case *ast.ParenExpression:
+				// parentheses protect enclosed composite literals
+				return false
+			case *ast.CompositeLiteral:
+				if isTypeName(x.Type) {
+					strip = false // do not strip parentheses
+				}
+				return false
+			}
+			return true
+		})
+		if strip {
+			return stripParens(px.X)
+		}
+	}
+	return x
+}
+
+func isTypeName(x ast.Expression) bool {
+	switch t := x.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpression:
+		return isTypeName(t.X)
+	}
+	return false
+}
+
+// expressions
+
+func (p *printer) identList(list []*ast.Ident) {
+	for i, x := range list {
+		if i > 0 {
+			p.print(tokens.Position{}, ",")
+			p.blank()
+		}
+		p.expr(x)
+	}
+}
+
+type exprListMode uint
+
+const (
+	commaTerm exprListMode = 1 << iota // list is optionally terminated by a comma
+	noIndent                           // no extra indentation in multi-line lists
+)
+
+// exprList prints a list of expressions between the positions prev and next of the surrounding
+// tokens; line breaks of the source between the expressions are kept
+func (p *printer) exprList(prev tokens.Position, list []ast.Expression, depth int, mode exprListMode, next tokens.Position) {
+	if len(list) == 0 {
+		return
+	}
+	line := list[0].Pos().Line
+	endLine := list[len(list)-1].End().Line
+
+	if prev.IsValid() && prev.Line == line && line == endLine {
+		// all list entries on a single line
+		for i, x := range list {
+			if i > 0 {
+				p.print(tokens.Position{}, ",")
+				p.blank()
+			}
+			p.expr0(x, depth)
+		}
+		return
+	}
+
+	// list entries span multiple lines; use source code positions to guide line breaks
+	indented := mode&noIndent != 0
+
+	// the first linebreak is always a formfeed since this section must not depend on any previous formatting
+	prevBreak := -1 // index of last expression that was followed by a linebreak
+	if prev.IsValid() && prev.Line < line {
+		if !indented {
+			p.indent++
+		}
+		if p.linebreak(line, 0, true) > 0 {
+			indented = true
+			prevBreak = 0
+		} else if mode&noIndent == 0 {
+			p.indent--
+		}
+	}
+
+	// the size of the expression or key is zero if it does not fit on a single line
+	size := 0
+
+	// the ratio between the geometric mean of the previous key sizes and the current size
+	// determines whether the alignment is broken
+	lnsum := 0.0
+	count := 0
+
+	prevLine := prev.Line
+	for i, x := range list {
+		line = x.Pos().Line
+
+		// the next linebreak needs a formfeed unless the sizes of the previous and the current expression or key are alike
+		useFF := true
+		prevSize := size
+		const infinity = 1e6 // larger than any source line
+		size = p.nodeSize(x, infinity)
+		pair, isPair := x.(*ast.KeyValueExpression)
+		if size <= infinity && prev.IsValid() && next.IsValid() {
+			// x fits on a single line
+			if isPair {
+				size = p.nodeSize(pair.Key, infinity)
+			}
+		} else {
+			size = 0
+		}
+		if prevSize > 0 && size > 0 {
+			const smallSize = 40
+			if count == 0 || prevSize <= smallSize && size <= smallSize {
+				useFF = false
+			} else {
+				const r = 2.5 // threshold
+				geomean := math.Exp(lnsum / float64(count))
+				ratio := float64(size) / geomean
+				useFF = r*ratio <= 1 || r <= ratio
+			}
+		}
+
+		needsLinebreak := 0 < prevLine && prevLine < line
+		if i > 0 {
+			p.print(tokens.Position{}, ",")
+			needsBlank := true
+			if needsLinebreak {
+				if !indented {
+					p.indent++
+				}
+				nbreaks := p.linebreak(line, 0, useFF || prevBreak+1 < i)
+				if nbreaks > 0 {
+					indented = true
+					prevBreak = i
+					needsBlank = false // we got a line break instead
+				} else if !indented {
+					p.indent--
+				}
+				// a new section or an empty line starts a new group of elements
+				if nbreaks > 1 || useFF || prevBreak+1 < i {
+					lnsum = 0
+					count = 0
+				}
+			}
+			if needsBlank {
+				p.blank()
+			}
+		}
+
+		if len(list) > 1 && isPair && size > 0 && needsLinebreak {
+			// a key:value expression that fits onto one line on a line of its own:
+			// use a column for the key such that consecutive entries can align
+			p.expr(pair.Key)
+			p.print(pair.ColonPos, ":")
+			p.vtab()
+			p.expr(pair.Value)
+		} else {
+			p.expr0(x, depth)
+		}
+
+		if size > 0 {
+			lnsum += math.Log(float64(size))
+			count++
+		}
+
+		prevLine = x.End().Line
+	}
+
+	if mode&commaTerm != 0 && next.IsValid() && p.line < next.Line {
+		// print a terminating comma if the next token is on a new line
+		p.print(tokens.Position{}, ",")
+		if indented {
+			p.flush(next)
+		}
+		if indented && mode&noIndent == 0 {
+			p.indent--
+		}
+		p.linebreak(next.Line, 1, true)
+		return
+	}
+	if indented && mode&noIndent == 0 {
+		p.indent--
+	}
+}
+
+// parameters prints a parameter list. A parameter starting on a later line than the end of
+// the previous one, or the opening parenthesis, keeps its line break, and the continuation
+// lines are indented once
+func (p *printer) parameters(fields *ast.FieldList, open, close string) {
+	p.print(fields.Opening, open)
+	indented := false
+	prevLine := fields.Opening.Line
+	for i, par := range fields.List {
+		if i > 0 {
+			p.print(tokens.Position{}, ",")
+		}
+		if 0 < prevLine && prevLine < par.Pos().Line && p.linebreak(par.Pos().Line, 0, true) > 0 {
+			if !indented {
+				p.indent++
+				indented = true
+			}
+		} else if i > 0 {
+			p.blank()
+		}
+		if len(par.Names) > 0 {
+			p.identList(par.Names)
+			p.blank()
+		}
+		p.expr(stripParensAlways(par.Type))
+		prevLine = par.Type.Pos().Line
+	}
+	// a closing parenthesis on a line of its own follows a comma
+	if len(fields.List) > 0 && 0 < prevLine && prevLine < fields.Closing.Line {
+		p.print(tokens.Position{}, ",")
+		p.linebreak(fields.Closing.Line, 0, true)
+	}
+	if indented {
+		p.indent--
+	}
+	p.print(fields.Closing, close)
+}
+
+func stripParensAlways(x ast.Expression) ast.Expression {
+	if x, ok := x.(*ast.ParenExpression); ok {
+		return stripParensAlways(x.X)
+	}
+	return x
+}
+
+func (p *printer) signature(sig *ast.FunctionType) {
+	if sig.TypeParams != nil {
+		p.parameters(sig.TypeParams, "[", "]")
+	}
+	if sig.Params != nil {
+		p.parameters(sig.Params, "(", ")")
+	} else {
+		p.print(tokens.Position{}, "()")
+	}
+	res := sig.Results
+	if n := res.NumFields(); n > 0 {
+		p.blank()
+		if n == 1 && res.List[0].Names == nil {
+			// single anonymous result; no ()'s
+			p.expr(stripParensAlways(res.List[0].Type))
+			return
+		}
+		p.parameters(res, "(", ")")
+	}
+}
+
+// fieldList prints the fields of a struct type or the methods and embedded elements of an interface
+// type; a single small field written on one line stays on one line
+func (p *printer) fieldList(fields *ast.FieldList, isStruct, incomplete bool) {
+	lbrace := fields.Opening
+	list := fields.List
+	rbrace := fields.Closing
+	hasComments := incomplete || p.commentBefore(rbrace)
+	srcIsOneLine := lbrace.IsValid() && rbrace.IsValid() && lbrace.Line == rbrace.Line
+
+	if !hasComments && srcIsOneLine {
+		if len(list) == 0 {
+			p.print(lbrace, "{")
+			p.print(rbrace, "}")
+			return
+		} else if p.isOneLineFieldList(list) {
+			p.print(lbrace, "{")
+			p.blank()
+			f := list[0]
+			if !isStruct && len(f.Names) > 0 {
+				// a method
+				p.expr(f.Names[0])
+				p.signature(f.Type.(*ast.FunctionType))
+				p.blank()
+				p.print(rbrace, "}")
+				return
+			}
+			for i, x := range f.Names {
+				if i > 0 {
+					p.print(tokens.Position{}, ",")
+					p.blank()
+				}
+				p.expr(x)
+			}
+			if len(f.Names) > 0 {
+				p.blank()
+			}
+			p.expr(f.Type)
+			p.blank()
+			p.print(rbrace, "}")
+			return
+		}
+	}
+
+	p.blank()
+	p.print(lbrace, "{")
+	p.indent++
+	if hasComments || len(list) > 0 {
+		p.newlines = 1
+		p.section = true
+	}
+	sep := p.vtab
+	if len(list) == 1 {
+		sep = p.blank
+	}
+	line := 0
+	for i, f := range list {
+		if i > 0 {
+			p.linebreak(f.Pos().Line, 1, p.linesFrom(line) > 0)
+		}
+		extraTabs := 0
+		line = p.recordLine()
+		if !isStruct && len(f.Names) > 0 {
+			// a method
+			p.expr(f.Names[0])
+			p.signature(f.Type.(*ast.FunctionType))
+			extraTabs = 1
+		} else if len(f.Names) > 0 {
+			// named fields
+			p.identList(f.Names)
+			sep()
+			p.expr(f.Type)
+			extraTabs = 1
+		} else {
+			// anonymous field
+			p.expr(f.Type)
+			extraTabs = 2
+		}
+		if f.Tag != nil {
+			if len(f.Names) > 0 && len(list) > 1 {
+				sep()
+			}
+			sep()
+			p.expr(f.Tag)
+			extraTabs = 0
+		}
+		if p.hasLineComment(f.End().Line) {
+			for ; extraTabs > 0; extraTabs-- {
+				sep()
+			}
+		}
+	}
+	p.flush(rbrace)
+	p.indent--
+	p.linebreak(rbrace.Line, 1, true)
+	p.print(rbrace, "}")
+}
+
+func (p *printer) isOneLineFieldList(list []*ast.Field) bool {
+	if len(list) != 1 {
+		return false // allow only one field
+	}
+	f := list[0]
+	if f.Tag != nil {
+		return false // don't allow tags
+	}
+	// only name(s) and type
+	const maxSize = 30 // adjust as appropriate, this is an approximate value
+	namesSize := 0
+	for i, x := range f.Names {
+		if i > 0 {
+			namesSize += 2 // ", "
+		}
+		namesSize += len(x.Name)
+	}
+	if namesSize > 0 {
+		namesSize = 1 // blank between names and types
+	}
+	typeSize := p.nodeSize(f.Type, maxSize)
+	return namesSize+typeSize <= maxSize
+}
+
+// binary expressions
+
+// walkBinary reports whether the tree of binary expressions at e has operators of precedence 4
+// and 5 and the lowest precedence which needs blanks around the operators to avoid confusion
+func walkBinary(e *ast.BinaryExpression) (has4, has5 bool, maxProblem int) {
+	switch e.Operator.Precedence() {
+	case 4:
+		has4 = true
+	case 5:
+		has5 = true
+	}
+
+	switch l := e.LeftX.(type) {
+	case *ast.BinaryExpression:
+		if l.Operator.Precedence() < e.Operator.Precedence() {
+			// parens will be inserted; pretend this is a paren expression and do nothing
+			break
+		}
+		h4, h5, mp := walkBinary(l)
+		has4 = has4 || h4
+		has5 = has5 || h5
+		if maxProblem < mp {
+			maxProblem = mp
+		}
+	}
+
+	switch r := e.RightX.(type) {
+	case *ast.BinaryExpression:
+		if r.Operator.Precedence() <= e.Operator.Precedence() {
+			// parens will be inserted; pretend this is a paren expression and do nothing
+			break
+		}
+		h4, h5, mp := walkBinary(r)
+		has4 = has4 || h4
+		has5 = has5 || h5
+		if maxProblem < mp {
+			maxProblem = mp
+		}
+
+	case *ast.StarExpression:
+		if e.Operator == tokens.QUO { // `*/`
+			maxProblem = 5
+		}
+
+	case *ast.UnaryExpression:
+		switch e.Operator.String() + r.Operator.String() {
+		case "/*", "&&", "&^":
+			maxProblem = 5
+		case "++", "--":
+			if maxProblem < 4 {
+				maxProblem = 4
+			}
+		}
+	}
+	return
+}
+
+// cutoff returns the precedence below which binary operators of e are surrounded by blanks
+func cutoff(e *ast.BinaryExpression, depth int) int {
+	has4, has5, maxProblem := walkBinary(e)
+	if maxProblem > 0 {
+		return maxProblem + 1
+	}
+	if has4 && has5 {
+		if depth == 1 {
+			return 5
+		}
+		return 4
+	}
+	if depth == 1 {
+		return 6
+	}
+	return 4
+}
+
+func diffPrec(expr ast.Expression, prec int) int {
+	x, ok := expr.(*ast.BinaryExpression)
+	if !ok || prec != x.Operator.Precedence() {
+		return 1
+	}
+	return 0
+}
+
+func reduceDepth(depth int) int {
+	depth--
+	if depth < 1 {
+		depth = 1
+	}
+	return depth
+}
+
+// binaryExpr prints a binary expression; the formatting depends on the depth of the expression
+// in the tree: operators of precedence less than cutoff are surrounded by blanks, so that
+//
+//	x + y*z
+//	(a + b) * c
+//	f(x*y, z)
+func (p *printer) binaryExpr(x *ast.BinaryExpression, prec1, cutoff, depth int) {
+	prec := x.Operator.Precedence()
+	if prec < prec1 {
+		// parenthesis needed; the parser inserts a paren expression, thus this
+		// case can only occur if the tree is created in a different way
+		p.print(tokens.Position{}, "(")
+		p.expr0(x, reduceDepth(depth)) // parentheses undo one level of depth
+		p.print(tokens.Position{}, ")")
+		return
+	}
+
+	printBlank := prec < cutoff
+
+	indented := false
+	p.expr1(x.LeftX, prec, depth+diffPrec(x.LeftX, prec))
+	if printBlank {
+		p.blank()
+	}
+	xline := p.line // before the operator (it may be on the next line!)
+	yline := x.RightX.Pos().Line
+	p.print(x.OpPos, x.Operator.String())
+	if xline != yline && xline > 0 && yline > 0 {
+		// at least one line break, but respect an extra empty line in the source
+		p.indent++
+		if p.linebreak(yline, 1, true) > 0 {
+			indented = true
+			printBlank = false // no blank after line break
+		} else {
+			p.indent--
+		}
+	}
+	if printBlank {
+		p.blank()
+	}
+	p.expr1(x.RightX, prec+1, depth+1)
+	if indented {
+		p.indent--
+	}
+}
+
+func (p *printer) expr(x ast.Expression) {
+	p.expr1(x, tokens.LowestPrec, 1)
+}
+
+func (p *printer) expr0(x ast.Expression, depth int) {
+	p.expr1(x, tokens.LowestPrec, depth)
+}
+
+func (p *printer) expr1(expr ast.Expression, prec1, depth int) {
+	switch x := expr.(type) {
+	case *ast.BadExpression:
+		p.print(x.From, "BadExpr")
+
+	case *ast.Ident:
+		p.print(x.NamePos, x.Name)
+
+	case *ast.BinaryExpression:
+		p.binaryExpr(x, prec1, cutoff(x, depth), depth)
+
+	case *ast.KeyValueExpression:
+		p.expr(x.Key)
+		p.print(x.ColonPos, ":")
+		p.blank()
+		p.expr(x.Value)
+
+	case *ast.StarExpression:
+		p.print(x.Star, "*")
+		p.expr(x.X)
+
+	case *ast.UnaryExpression:
+		p.print(x.OpPos, x.Operator.String())
+		p.expr1(x.X, tokens.UnaryPrec, depth)
+
+	case *ast.BasicLiteral:
+		p.printLiteral(x.ValuePos, x.Value.Lit)
+
+	case *ast.FunctionLiteral:
+		p.expr(x.Type)
+		p.funcBody(p.nodeSize(x.Type, math.MaxInt32), false, x.Body)
+
+	case *ast.ParenExpression:
+		if _, hasParens := x.X.(*ast.ParenExpression); hasParens {
+			// don't print parentheses around an already parenthesized expression
+			p.expr0(x.X, depth)
+		} else {
+			p.print(x.LParenPos, "(")
+			p.expr0(x.X, reduceDepth(depth)) // parentheses undo one level of depth
+			p.print(x.RParenPos, ")")
+		}
+
+	case *ast.SelectorExpression:
+		p.expr1(x.X, tokens.HighestPrec, depth)
+		p.print(tokens.Position{}, ".")
+		p.expr(x.Selector)
+
+	case *ast.IndexExpression:
+		p.expr1(x.X, tokens.HighestPrec, 1)
+		p.print(x.LBracketPos, "[")
+		p.expr0(x.Index, depth+1)
+		p.print(x.RBracketPos, "]")
+
+	case *ast.IndexExpressions:
+		p.expr1(x.X, tokens.HighestPrec, 1)
+		p.print(x.Lbrack, "[")
+		p.exprList(x.Lbrack, x.Indices, depth+1, commaTerm, x.Rbrack)
+		p.print(x.Rbrack, "]")
+
+	case *ast.CallExpression:
+		if len(x.Arguments) > 1 {
+			depth++
+		}
+		p.expr1(x.Function, tokens.HighestPrec, depth)
+		p.print(x.LParenPos, "(")
+		if x.Ellipsis.IsValid() {
+			p.exprList(x.LParenPos, x.Arguments, depth, 0, x.Ellipsis)
+			p.print(x.Ellipsis, "...")
+			if x.RParenPos.IsValid() && x.Ellipsis.Line < x.RParenPos.Line {
+				p.print(tokens.Position{}, ",")
+				p.linebreak(x.RParenPos.Line, 1, true)
+			}
+		} else {
+			p.exprList(x.LParenPos, x.Arguments, depth, commaTerm, x.RParenPos)
+		}
+		p.print(x.RParenPos, ")")
+
+	case *ast.CompositeLiteral:
+		// composite literal elements that are composite literals themselves may have the type omitted
+		if x.Type != nil {
+			p.expr1(x.Type, tokens.HighestPrec, depth)
+		}
+		p.print(x.LbracePos, "{")
+		p.exprList(x.LbracePos, x.Elements, 1, commaTerm, x.RbracePos)
+		p.flushIndented(x.RbracePos)
+		p.print(x.RbracePos, "}")
+
+	case *ast.Ellipsis:
+		p.print(x.Ellipsis, "...")
+		if x.Elt != nil {
+			p.expr(x.Elt)
+		}
+
+	case *ast.ArrayType:
+		p.print(x.Lbrack, "[")
+		if x.Len != nil {
+			p.expr(x.Len)
+		}
+		p.print(tokens.Position{}, "]")
+		p.expr(x.ElementType)
+
+	case *ast.StructType:
+		p.print(x.Struct, "struct")
+		p.fieldList(x.Fields, true, x.Incomplete)
+
+	case *ast.InterfaceType:
+		p.print(x.Interface, "interface")
+		p.fieldList(x.Methods, false, x.Incomplete)
+
+	case *ast.FunctionType:
+		p.print(x.Func, "func")
+		p.signature(x)
+
+	default:
+		panic(fmt.Sprintf("printer: unexpected expression %T", x))
+	}
+}
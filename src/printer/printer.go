@@ -0,0 +1,339 @@
+// Package printer implements printing of syntax trees as Go source in the layout of gofmt
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+const maxNewlines = 2 // max. number of newlines between source text
+
+// printer writes the text of the nodes to output with indentation tabs and with vertical
+// tabs separating the cells of aligned columns; the text is formatted by a tabwriter
+type printer struct {
+	output bytes.Buffer
+	indent int // current indentation
+	raw    bool
+
+	// pending whitespace, written before the next text
+	newlines int    // number of pending newlines
+	min      int    // minimum number of pending newlines requested, kept before comments
+	section  bool   // the pending newlines start a new section of aligned columns
+	ws       []byte // pending blanks and vertical tabs
+	lastText string // last text written; used to separate tokens that would combine
+
+	line    int // source line of the current output line, estimated for text without positions
+	last    int // source line of the last text written with a position
+	outLine int // number of newlines written
+
+	comments  []*ast.Comment // comments not yet printed
+	nodeSizes map[ast.Node]int
+}
+
+// Fprint "pretty-prints" node to w in the layout of gofmt. The node may be a *ast.File,
+// a declaration, a statement or an expression; comments are printed for files only
+func Fprint(w io.Writer, node ast.Node) error {
+	p := &printer{nodeSizes: map[ast.Node]int{}}
+	if err := p.printNode(node); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.DiscardEmptyColumns|tabwriter.TabIndent|tabwriter.StripEscape)
+	if _, err := tw.Write(p.output.Bytes()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// Source returns the source of node printed by Fprint
+func Source(node ast.Node) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Fprint(&b, node); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (p *printer) printNode(node ast.Node) error {
+	p.line = node.Pos().Line
+	switch n := node.(type) {
+	case *ast.File:
+		p.comments = n.Comments
+		p.file(n)
+	case ast.Declaration:
+		p.decl(n)
+	case ast.Statement:
+		p.stmt(n, false)
+	case ast.Expression:
+		p.expr(n)
+	default:
+		return fmt.Errorf("printer: unsupported node type %T", node)
+	}
+	return nil
+}
+
+// nodeSize returns the size of n printed on a single line, or a value larger than maxSize
+// if n does not fit on a single line of maxSize characters
+func (p *printer) nodeSize(n ast.Node, maxSize int) int {
+	if size, found := p.nodeSizes[n]; found {
+		return size
+	}
+	size := maxSize + 1 // assume n doesn't fit
+	p.nodeSizes[n] = size
+	q := &printer{raw: true, nodeSizes: p.nodeSizes}
+	q.printNode(n)
+	text := q.output.String()
+	if !strings.ContainsAny(text, "\n\f") {
+		if s := utf8.RuneCountInString(strings.ReplaceAll(text, string(tabwriter.Escape), "")); s <= maxSize {
+			size = s
+			p.nodeSizes[n] = size
+		}
+	}
+	return size
+}
+
+// whitespace
+
+func (p *printer) blank() { p.ws = append(p.ws, ' ') }
+func (p *printer) vtab()  { p.ws = append(p.ws, '\v') }
+
+// linebreak schedules at least min line breaks before the next text, or more if the source
+// line is further away, and returns their number. A new section stops the alignment of columns
+// across the breaks
+func (p *printer) linebreak(line, min int, newSection bool) int {
+	n := line - p.line
+	if n > maxNewlines {
+		n = maxNewlines
+	}
+	if n < min {
+		n = min
+	}
+	if min > p.min {
+		p.min = min
+	}
+	if n > 0 {
+		if n > p.newlines {
+			p.newlines = n
+		}
+		p.section = p.section || newSection
+	}
+	return n
+}
+
+// recordLine returns the output line at which the next text will be written
+func (p *printer) recordLine() int {
+	return p.outLine + p.newlines
+}
+
+// linesFrom returns the number of output lines written since line
+func (p *printer) linesFrom(line int) int {
+	return p.outLine - line
+}
+
+// writeWhitespace writes the pending whitespace followed by the indentation if there are pending newlines
+func (p *printer) writeWhitespace() {
+	if p.newlines > 0 {
+		if p.output.Len() > 0 {
+			for i := 0; i < p.newlines; i++ {
+				if p.section && !p.raw {
+					p.output.WriteByte('\f')
+				} else {
+					p.output.WriteByte('\n')
+				}
+			}
+			p.outLine += p.newlines
+		}
+		p.line += p.newlines
+		for i := 0; i < p.indent; i++ {
+			p.output.WriteByte('\t')
+		}
+		p.newlines = 0
+		p.min = 0
+		p.section = false
+		p.ws = p.ws[:0]
+		p.lastText = ""
+		return
+	}
+	if len(p.ws) > 0 {
+		p.output.Write(p.ws)
+		p.ws = p.ws[:0]
+		p.lastText = ""
+	}
+}
+
+// mayCombine reports whether the text next written after prev would combine with it to a different token
+func mayCombine(prev, next string) bool {
+	if next == "" {
+		return false
+	}
+	switch prev {
+	case "+":
+		return next[0] == '+'
+	case "-":
+		return next[0] == '-'
+	case "/":
+		return next[0] == '*'
+	case "<":
+		return next[0] == '-' || next[0] == '<'
+	case "&":
+		return next[0] == '&' || next[0] == '^'
+	}
+	// the period would make a float of an integer literal
+	return next[0] == '.' && isInt(prev)
+}
+
+// isInt reports whether text is an integer literal
+func isInt(text string) bool {
+	if text == "" || text[0] < '0' || text[0] > '9' {
+		return false
+	}
+	if len(text) > 1 && (text[1] == 'x' || text[1] == 'X') {
+		return !strings.ContainsAny(text, ".pPi")
+	}
+	return !strings.ContainsAny(text, ".eEi")
+}
+
+// print writes text at the source position pos, preceded by the comments before pos
+func (p *printer) print(pos tokens.Position, text string) {
+	if pos.IsValid() {
+		p.flush(pos)
+	}
+	if p.newlines == 0 && len(p.ws) == 0 && mayCombine(p.lastText, text) {
+		p.blank()
+	}
+	p.writeWhitespace()
+	p.output.WriteString(text)
+	p.lastText = text
+	if pos.IsValid() {
+		p.line = pos.Line
+		p.last = pos.Line
+	}
+}
+
+// printLiteral writes the text of a literal or comment, which may contain tabs and line breaks
+func (p *printer) printLiteral(pos tokens.Position, text string) {
+	p.print(pos, "")
+	p.output.WriteByte(tabwriter.Escape)
+	p.output.WriteString(text)
+	p.output.WriteByte(tabwriter.Escape)
+	p.lastText = text
+	if n := strings.Count(text, "\n"); n > 0 {
+		p.line += n
+		p.last += n
+		p.outLine += n
+	}
+}
+
+// comments
+
+// flush prints the comments before pos
+func (p *printer) flush(pos tokens.Position) {
+	for len(p.comments) > 0 && before(p.comments[0].Slash, pos) {
+		p.comment(pos)
+	}
+}
+
+// flushIndented prints the comments before pos one indentation level deeper, like the
+// comments before the closing brace of a block
+func (p *printer) flushIndented(pos tokens.Position) {
+	p.indent++
+	p.flush(pos)
+	p.indent--
+}
+
+func before(x, y tokens.Position) bool {
+	return x.Line < y.Line || x.Line == y.Line && x.Column < y.Column
+}
+
+// comment prints the next comment, which precedes the text at next
+func (p *printer) comment(next tokens.Position) {
+	c := p.comments[0]
+	p.comments = p.comments[1:]
+	lineComment := strings.HasPrefix(c.Text, "//")
+	endLine := c.End().Line
+	pending := 0 // newlines pending before the comment and still due after it
+	trailing := c.Pos().Line == p.last && p.output.Len() > 0 && p.last > 0
+	if trailing {
+		pending = p.newlines
+		// comment on the same line as the last text
+		var ws []byte
+		for _, b := range p.ws {
+			if b == '\v' {
+				ws = append(ws, b)
+			}
+		}
+		p.output.Write(ws)
+		if len(ws) > 0 {
+			// the vertical tabs separate the comment
+		} else if lineComment || next.Line > endLine || len(p.comments) > 0 && p.comments[0].Pos().Line > endLine {
+			p.output.WriteByte('\t')
+		} else {
+			p.output.WriteByte(' ')
+		}
+		p.ws = p.ws[:0]
+	} else {
+		// comment on a line of its own
+		n := c.Pos().Line - p.last
+		if n > maxNewlines {
+			n = maxNewlines
+		}
+		if n < 1 {
+			n = 1
+		}
+		if n < p.min {
+			n = p.min
+		}
+		if p.output.Len() == 0 {
+			n = 0
+		}
+		p.newlines = n
+		p.section = true
+		p.writeWhitespace()
+	}
+	p.output.WriteByte(tabwriter.Escape)
+	p.output.WriteString(c.Text)
+	p.output.WriteByte(tabwriter.Escape)
+	p.lastText = c.Text
+	p.outLine += strings.Count(c.Text, "\n")
+	p.last = endLine
+	p.line = endLine
+
+	// the text following the comment; a comment on a line of its own ends the section
+	p.newlines = pending
+	if next.IsValid() {
+		p.linebreak(next.Line, 0, !trailing)
+	}
+	if lineComment && p.newlines == 0 {
+		p.linebreak(next.Line, 1, false)
+	}
+	if p.newlines == 0 {
+		p.blank()
+	}
+}
+
+// commentBefore reports whether there is an unprinted comment before pos
+func (p *printer) commentBefore(pos tokens.Position) bool {
+	return len(p.comments) > 0 && before(p.comments[0].Slash, pos)
+}
+
+// commentSizeBefore returns the size of the unprinted comments before pos
+func (p *printer) commentSizeBefore(pos tokens.Position) int {
+	size := 0
+	for _, c := range p.comments {
+		if !before(c.Slash, pos) {
+			break
+		}
+		size += utf8.RuneCountInString(c.Text)
+	}
+	return size
+}
+
+// hasLineComment reports whether the next unprinted comment is on the source line
+func (p *printer) hasLineComment(line int) bool {
+	return len(p.comments) > 0 && p.comments[0].Pos().Line == line
+}
@@ -0,0 +1,85 @@
+package printer_test
+
+import (
+	"go/format"
+	"gocompiler/src/parser"
+	"gocompiler/src/printer"
+	"gocompiler/src/tokens"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// check parses src, prints the tree and compares the result with the output of gofmt
+func check(t *testing.T, name string, src []byte) {
+	t.Helper()
+	f, err := parser.ParseFile(tokens.NewFileSet(), name, src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	expected, err := format.Source(src)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	result, err := printer.Source(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	// gofmt keeps the leading and trailing space of sources without a package clause
+	if strings.TrimSpace(string(result)) != strings.TrimSpace(string(expected)) {
+		t.Errorf("%s: expected\n%s\ngot\n%s", name, expected, result)
+	}
+}
+
+// TestFiles prints the printer test inputs and the parser test inputs without syntax errors
+func TestFiles(t *testing.T) {
+	files, err := filepath.Glob("../tests/parser/input/*/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no test inputs: %v", err)
+	}
+	more, _ := filepath.Glob("../tests/printer/*.txt")
+	files = append(files, more...)
+	for _, name := range files {
+		if strings.Contains(name, "errors") {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, name, src)
+	}
+}
+
+func TestExpression(t *testing.T) {
+	for _, test := range []struct{ src, expected string }{
+		{"a+b*c", "a + b*c"},
+		{"(a+b)*c", "(a + b) * c"},
+		{"f(a+b, c*d)", "f(a+b, c*d)"},
+		{"x[i+1]", "x[i+1]"},
+		{"- -x", "- -x"},
+		{"a&^b", "a &^ b"},
+		{"[]int{1,2}", "[]int{1, 2}"},
+		{"func(x int) int { return x }", "func(x int) int { return x }"},
+		{"f(1 ...)", "f(1 ...)"},
+		{"f(a, b+0x1F ...)", "f(a, b+0x1F ...)"},
+		{"f(1.5 ...)", "f(1.5...)"},
+	} {
+		x, err := parser.ParseExpr(test.src)
+		if err != nil {
+			t.Fatalf("%s: %v", test.src, err)
+		}
+		result, err := printer.Source(x)
+		if err != nil {
+			t.Fatalf("%s: %v", test.src, err)
+		}
+		if string(result) != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.src, test.expected, result)
+		}
+		// the output parses again
+		if _, err := parser.ParseExpr(string(result)); err != nil {
+			t.Errorf("%s: the output %q does not parse: %v", test.src, result, err)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+// Package resolver links the identifiers of syntax trees to the objects they denote
+package resolver
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/printer"
+	"gocompiler/src/tokens"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resolve resolves the identifiers of files, the files of one package keyed by their names.
+// The package level objects of all files are declared in the package scope, nested in Universe;
+// the imports of every file are declared in a file scope nested in the package scope, and the
+// local objects in the scopes of function signatures, blocks, if, for and switch statements and
+// case clauses. The labels of a function body are declared in a label scope of their own and
+// the labels of break, continue and goto statements resolved there. Every identifier denoting an object, declaring identifiers included, gets the
+// object in Obj. Selectors, struct fields and the identifier keys of composite literals are
+// not resolved, as such keys may name struct fields, unless the literal type is an array or
+// slice type.
+//
+// Undeclared names and names declared twice in the same scope are reported in a parser.ErrorList
+// sorted by file name and position; the package is complete even then
+func Resolve(files map[string]*ast.File) (*ast.Package, error) {
+	pkg := &ast.Package{Scope: ast.NewScope(Universe), Files: files}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r := &resolver{pkgScope: pkg.Scope, declFile: map[*ast.Object]string{}}
+	for _, name := range names {
+		f := files[name]
+		r.filename = name
+		if f.Name != nil {
+			if pkg.Name == "" {
+				pkg.Name = f.Name.Name
+			} else if f.Name.Name != pkg.Name {
+				r.errorf(f.Name.Pos(), "package %s; expected package %s", f.Name.Name, pkg.Name)
+			}
+		}
+		for _, decl := range f.Decls {
+			r.declarePackageLevel(decl)
+		}
+	}
+	for _, name := range names {
+		r.filename = name
+		r.file(files[name])
+	}
+	r.errors.Sort()
+	return pkg, r.errors.Err()
+}
+
+// ResolveFile resolves the identifiers of f, the only file of its package, with Resolve
+func ResolveFile(filename string, f *ast.File) (*ast.Package, error) {
+	return Resolve(map[string]*ast.File{filename: f})
+}
+
+type resolver struct {
+	filename  string
+	errors    parser.ErrorList
+	pkgScope  *ast.Scope
+	scope     *ast.Scope             // current scope
+	labels    *ast.Scope             // label scope of the current function body
+	targets   [][]*ast.Ident         // labels of the branch statements of the enclosing function bodies
+	declFile  map[*ast.Object]string // files of the package level objects
+	dotImport bool                   // the file has dot imports, which may declare any name
+}
+
+func (r *resolver) errorf(pos tokens.Position, format string, args ...any) {
+	r.errors = append(r.errors, &parser.Error{Filename: r.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (r *resolver) openScope() {
+	r.scope = ast.NewScope(r.scope)
+}
+
+func (r *resolver) closeScope() {
+	r.scope = r.scope.Outer
+}
+
+func (r *resolver) openLabelScope() {
+	r.labels = ast.NewScope(r.labels)
+	r.targets = append(r.targets, nil)
+}
+
+// closeLabelScope resolves the labels of the branch statements of the function body, which may
+// refer to labels declared after them; undeclared labels are reported by the type checker
+func (r *resolver) closeLabelScope() {
+	n := len(r.targets) - 1
+	for _, ident := range r.targets[n] {
+		ident.Obj = r.labels.Lookup(ident.Name)
+	}
+	r.targets = r.targets[:n]
+	r.labels = r.labels.Outer
+}
+
+// declare declares the object of kind for ident in scope; the blank identifier gets
+// an object but is not declared
+func (r *resolver) declare(scope *ast.Scope, kind ast.ObjKind, ident *ast.Ident, decl, data any) *ast.Object {
+	obj := ast.NewObj(kind, ident.Name)
+	obj.Decl = decl
+	obj.Data = data
+	ident.Obj = obj
+	if ident.Name == "_" {
+		return obj
+	}
+	if alt := scope.Insert(obj); alt != nil {
+		r.redeclared(ident, alt)
+		return obj
+	}
+	if scope == r.pkgScope {
+		r.declFile[obj] = r.filename
+	}
+	return obj
+}
+
+// redeclared reports that ident declares again the name of the object alt
+func (r *resolver) redeclared(ident *ast.Ident, alt *ast.Object) {
+	msg := ident.Name + " redeclared in this block"
+	if pos := alt.Pos(); pos.IsValid() {
+		where := pos.ToString()
+		if file := r.declFile[alt]; file != "" && file != r.filename {
+			where = file + ":" + where
+		}
+		msg += "\n\tprevious declaration at " + where
+	}
+	r.errorf(ident.Pos(), "%s", msg)
+}
+
+// resolve links ident to the object of the innermost declaration of its name
+func (r *resolver) resolve(ident *ast.Ident) {
+	if ident.Name == "_" {
+		return
+	}
+	if obj := r.scope.LookupParent(ident.Name); obj != nil {
+		ident.Obj = obj
+		return
+	}
+	if !r.dotImport {
+		r.errorf(ident.Pos(), "undefined: %s", ident.Name)
+	}
+}
+
+// declarePackageLevel declares the objects of a top level declaration in the package scope
+func (r *resolver) declarePackageLevel(decl ast.Declaration) {
+	switch d := decl.(type) {
+	case *ast.FunctionDeclaration:
+		if d.Name.Name == "init" {
+			d.Name.Obj = ast.NewObj(ast.Fun, "init") // init functions cannot be referred to
+			d.Name.Obj.Decl = d
+			return
+		}
+		r.declare(r.pkgScope, ast.Fun, d.Name, d, nil)
+	case *ast.GenericDeclaration:
+		for i, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.ValueSpec:
+				kind := ast.Var
+				if d.Token == tokens.CONST {
+					kind = ast.Con
+				}
+				for _, name := range s.Names {
+					r.declare(r.pkgScope, kind, name, s, constIndex(kind, i))
+				}
+			case *ast.TypeSpec:
+				r.declare(r.pkgScope, ast.Typ, s.Name, s, nil)
+			}
+		}
+	}
+}
+
+// constIndex returns the Data of an object of kind declared in the spec i of a declaration,
+// the value of iota for constants
+func constIndex(kind ast.ObjKind, i int) any {
+	if kind == ast.Con {
+		return i
+	}
+	return nil
+}
+
+// file resolves the identifiers of the top level declarations of f in its file scope
+func (r *resolver) file(f *ast.File) {
+	r.scope = ast.NewScope(r.pkgScope)
+	r.dotImport = false
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value.Lit)
+		if err != nil {
+			continue // reported by the parser
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		ident := &ast.Ident{NamePos: spec.Path.Pos(), Name: name}
+		if spec.Name != nil {
+			ident = spec.Name
+		}
+		switch ident.Name {
+		case ".":
+			r.dotImport = true
+		case "_":
+		default:
+			obj := r.declare(r.scope, ast.Pkg, ident, spec, nil)
+			obj.Data = path
+			if alt := r.pkgScope.Lookup(ident.Name); alt != nil {
+				r.errors = append(r.errors, &parser.Error{Filename: r.declFile[alt], Pos: alt.Pos(),
+					Msg: fmt.Sprintf("%s already declared through import of package %s", ident.Name, spec.Path.Value.Lit)})
+			}
+		}
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FunctionDeclaration:
+			r.funcDecl(d)
+		case *ast.GenericDeclaration:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					r.exprOrNil(s.Type)
+					r.exprList(s.Values)
+				case *ast.TypeSpec:
+					r.typeSpec(s)
+				}
+			}
+		}
+	}
+	r.scope = nil
+}
+
+// genDecl resolves and declares the objects of a local declaration. The scope of a constant
+// or variable begins after its spec, the scope of a type at its name
+func (r *resolver) genDecl(d *ast.GenericDeclaration) {
+	for i, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.ValueSpec:
+			r.exprOrNil(s.Type)
+			r.exprList(s.Values)
+			kind := ast.Var
+			if d.Token == tokens.CONST {
+				kind = ast.Con
+			}
+			for _, name := range s.Names {
+				r.declare(r.scope, kind, name, s, constIndex(kind, i))
+			}
+		case *ast.TypeSpec:
+			r.declare(r.scope, ast.Typ, s.Name, s, nil)
+			r.typeSpec(s)
+		}
+	}
+}
+
+// typeSpec resolves the type of s; the type parameters are declared in a scope of their own
+func (r *resolver) typeSpec(s *ast.TypeSpec) {
+	if s.TypeParams != nil {
+		r.openScope()
+		defer r.closeScope()
+		r.typeParams(s.TypeParams)
+	}
+	r.expr(s.Type)
+}
+
+// typeParams declares the type parameters of list and resolves their constraints,
+// which may refer to any of the parameters
+func (r *resolver) typeParams(list *ast.FieldList) {
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			r.declare(r.scope, ast.Typ, name, field, nil)
+		}
+	}
+	for _, field := range list.List {
+		r.expr(field.Type)
+	}
+}
+
+// signature resolves the types of a function signature in the current scope and declares
+// its type parameters, parameters and results there. The parameter types cannot refer to the
+// parameters, whose scope is the function body
+func (r *resolver) signature(t *ast.FunctionType) {
+	if t.TypeParams != nil {
+		r.typeParams(t.TypeParams)
+	}
+	lists := []*ast.FieldList{t.Params, t.Results}
+	for _, list := range lists {
+		if list != nil {
+			for _, field := range list.List {
+				r.expr(field.Type)
+			}
+		}
+	}
+	for _, list := range lists {
+		if list != nil {
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					r.declare(r.scope, ast.Var, name, field, nil)
+				}
+			}
+		}
+	}
+}
+
+func (r *resolver) funcDecl(d *ast.FunctionDeclaration) {
+	r.openScope()
+	defer r.closeScope()
+	r.signature(d.Type)
+	if d.Body != nil {
+		r.openLabelScope()
+		r.stmtList(d.Body.List) // the body shares the scope of the parameters
+		r.closeLabelScope()
+	}
+}
+
+func (r *resolver) exprOrNil(x ast.Expression) {
+	if x != nil {
+		r.expr(x)
+	}
+}
+
+func (r *resolver) exprList(list []ast.Expression) {
+	for _, x := range list {
+		r.expr(x)
+	}
+}
+
+func (r *resolver) expr(x ast.Expression) {
+	switch x := x.(type) {
+	case *ast.Ident:
+		r.resolve(x)
+	case *ast.BasicLiteral, *ast.BadExpression:
+	case *ast.Ellipsis:
+		r.exprOrNil(x.Elt)
+	case *ast.FunctionLiteral:
+		r.openScope()
+		defer r.closeScope()
+		r.signature(x.Type)
+		r.openLabelScope()
+		r.stmtList(x.Body.List)
+		r.closeLabelScope()
+	case *ast.CompositeLiteral:
+		r.exprOrNil(x.Type)
+		_, isArray := x.Type.(*ast.ArrayType)
+		for _, elt := range x.Elements {
+			if kv, isKeyValue := elt.(*ast.KeyValueExpression); isKeyValue {
+				// a key may name a struct field, known only with the type of the literal
+				if _, isIdent := kv.Key.(*ast.Ident); !isIdent || isArray {
+					r.expr(kv.Key)
+				}
+				r.expr(kv.Value)
+			} else {
+				r.expr(elt)
+			}
+		}
+	case *ast.ParenExpression:
+		r.expr(x.X)
+	case *ast.SelectorExpression:
+		r.expr(x.X)
+	case *ast.IndexExpression:
+		r.expr(x.X)
+		r.expr(x.Index)
+	case *ast.IndexExpressions:
+		r.expr(x.X)
+		r.exprList(x.Indices)
+	case *ast.CallExpression:
+		r.expr(x.Function)
+		r.exprList(x.Arguments)
+	case *ast.StarExpression:
+		r.expr(x.X)
+	case *ast.UnaryExpression:
+		r.expr(x.X)
+	case *ast.BinaryExpression:
+		r.expr(x.LeftX)
+		r.expr(x.RightX)
+	case *ast.KeyValueExpression:
+		r.expr(x.Key)
+		r.expr(x.Value)
+	case *ast.ArrayType:
+		r.exprOrNil(x.Len)
+		r.expr(x.ElementType)
+	case *ast.StructType:
+		for _, field := range x.Fields.List {
+			r.expr(field.Type)
+		}
+	case *ast.InterfaceType:
+		// the method names are resolved with the type of the interface
+		for _, field := range x.Methods.List {
+			r.expr(field.Type)
+		}
+	case *ast.FunctionType:
+		r.openScope() // the names of the parameters do not matter
+		defer r.closeScope()
+		r.signature(x)
+	}
+}
+
+func (r *resolver) stmtList(list []ast.Statement) {
+	for _, s := range list {
+		r.stmt(s)
+	}
+}
+
+func (r *resolver) stmtOrNil(s ast.Statement) {
+	if s != nil {
+		r.stmt(s)
+	}
+}
+
+func (r *resolver) stmt(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.BadStatement:
+	case *ast.DeclarationStatement:
+		if d, isGen := s.Decl.(*ast.GenericDeclaration); isGen {
+			r.genDecl(d)
+		}
+	case *ast.ExpressionStatement:
+		r.expr(s.X)
+	case *ast.IncDecStatement:
+		r.expr(s.X)
+	case *ast.AssignStatement:
+		r.exprList(s.Rhs)
+		if s.Tok.Tok == tokens.DEFINE {
+			r.shortVarDecl(s)
+		} else {
+			r.exprList(s.Lhs)
+		}
+	case *ast.ReturnStatement:
+		r.exprList(s.Results)
+	case *ast.BranchStatement:
+		if s.Label != nil {
+			n := len(r.targets) - 1
+			r.targets[n] = append(r.targets[n], s.Label)
+		}
+	case *ast.LabeledStatement:
+		obj := ast.NewObj(ast.Lbl, s.Label.Name)
+		obj.Decl = s
+		s.Label.Obj = obj
+		r.labels.Insert(obj) // a label declared twice is reported by the type checker
+		r.stmtOrNil(s.Stmt)
+	case *ast.BlockStatement:
+		r.openScope()
+		r.stmtList(s.List)
+		r.closeScope()
+	case *ast.IfStatement:
+		r.openScope()
+		r.stmtOrNil(s.Init)
+		r.expr(s.Cond)
+		r.stmt(s.Body)
+		r.stmtOrNil(s.Else)
+		r.closeScope()
+	case *ast.ForStatement:
+		r.openScope()
+		r.stmtOrNil(s.Init)
+		r.exprOrNil(s.Cond)
+		r.stmtOrNil(s.Post)
+		r.stmt(s.Body)
+		r.closeScope()
+	case *ast.RangeStatement:
+		r.openScope()
+		r.expr(s.X)
+		for _, x := range []ast.Expression{s.Key, s.Value} {
+			if x == nil {
+				continue
+			}
+			if ident, isIdent := x.(*ast.Ident); isIdent && s.Tok.Tok == tokens.DEFINE {
+				r.declare(r.scope, ast.Var, ident, s, nil)
+			} else {
+				r.expr(x)
+			}
+		}
+		r.stmt(s.Body)
+		r.closeScope()
+	case *ast.SwitchStatement:
+		r.openScope()
+		r.stmtOrNil(s.Init)
+		r.exprOrNil(s.Tag)
+		for _, clause := range s.Body.List {
+			r.stmt(clause)
+		}
+		r.closeScope()
+	case *ast.CaseClause:
+		r.exprList(s.List)
+		r.openScope()
+		r.stmtList(s.Body)
+		r.closeScope()
+	}
+}
+
+// shortVarDecl declares the new variables on the left side of s, of which there must be one;
+// the names already declared in the current scope are assigned to
+func (r *resolver) shortVarDecl(s *ast.AssignStatement) {
+	seen := map[string]bool{}
+	isNew, nonName := false, false
+	for _, x := range s.Lhs {
+		ident, isIdent := x.(*ast.Ident)
+		if !isIdent {
+			r.expr(x)
+			text, _ := printer.Source(x)
+			r.errorf(x.Pos(), "non-name %s on left side of :=", text)
+			nonName = true
+			continue
+		}
+		if ident.Name != "_" {
+			if seen[ident.Name] {
+				r.errorf(ident.Pos(), "%s repeated on left side of :=", ident.Name)
+				continue
+			}
+			seen[ident.Name] = true
+		}
+		if obj := r.scope.Lookup(ident.Name); obj != nil {
+			ident.Obj = obj
+			continue
+		}
+		r.declare(r.scope, ast.Var, ident, s, nil)
+		if ident.Name != "_" {
+			isNew = true
+		}
+	}
+	if !isNew && !nonName {
+		r.errorf(s.TokPos, "no new variables on left side of :=")
+	}
+}
@@ -0,0 +1,230 @@
+package resolver_test
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/resolver"
+	"gocompiler/src/tokens"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, src string) *ast.File {
+	f, err := parser.ParseFile(tokens.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// uses returns the identifiers of f referring to an object declared elsewhere,
+// as "position name -> declaration position"
+func uses(f *ast.File) (list []string) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ident, isIdent := n.(*ast.Ident); isIdent && ident.Obj != nil && ident.Obj.Pos() != ident.Pos() {
+			decl := "universe"
+			if pos := ident.Obj.Pos(); pos.IsValid() {
+				decl = ident.Obj.Kind.String() + " " + pos.ToString()
+			}
+			list = append(list, fmt.Sprintf("%s %s -> %s", ident.Pos().ToString(), ident.Name, decl))
+		}
+		return true
+	})
+	return
+}
+
+func TestResolve(t *testing.T) {
+	const src = `package p
+
+import m "math"
+
+const c = 1
+
+type T struct{ x int }
+
+func f(x int) (r T) {
+	var y = x + c
+	if x := y; x > 0 {
+		r.x = x
+	} else {
+		x := m.Pi
+		_ = x
+	}
+	for i, v := range []int{c: y} {
+		y, z := i, v
+		_ = z
+	}
+	g := func(x T) T { return x }
+	return g(T{x: y})
+}
+`
+	f := parse(t, src)
+	if _, err := resolver.ResolveFile("p.go", f); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"7:18 int -> universe",
+		"9:10 int -> universe",
+		"9:18 T -> type 7:6",
+		"10:10 x -> var 9:8",
+		"10:14 c -> const 5:7",
+		"11:10 y -> var 10:6",
+		"11:13 x -> var 11:5",
+		"12:3 r -> var 9:16",
+		"12:9 x -> var 11:5",
+		"14:8 m -> package 3:8",
+		"15:7 x -> var 14:3",
+		"17:22 int -> universe",
+		"17:26 c -> const 5:7",
+		"17:29 y -> var 10:6",
+		"18:11 i -> var 17:6",
+		"18:14 v -> var 17:9",
+		"19:7 z -> var 18:6",
+		"21:14 T -> type 7:6",
+		"21:17 T -> type 7:6",
+		"21:28 x -> var 21:12",
+		"22:9 g -> var 21:2",
+		"22:11 T -> type 7:6",
+		"22:16 y -> var 10:6",
+	}
+	if got := uses(f); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestScopes(t *testing.T) {
+	f := parse(t, `package p
+
+import "fmt"
+
+type T []int
+
+var v, w = 1, 2
+
+func main() {}
+func init() {}
+func init() {}
+`)
+	pkg, err := resolver.ResolveFile("p.go", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name != "p" || pkg.Scope.Outer != resolver.Universe {
+		t.Errorf("unexpected package %s with outer scope %v", pkg.Name, pkg.Scope.Outer)
+	}
+	expected := "scope {\n\ttype T\n\tfunc main\n\tvar v\n\tvar w\n}"
+	if s := pkg.Scope.String(); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+	if obj := f.Imports[0].Path; obj == nil || pkg.Scope.Lookup("fmt") != nil {
+		t.Errorf("imports must not be declared in the package scope")
+	}
+	if obj := pkg.Scope.Lookup("w"); obj.Decl != f.Decls[2].(*ast.GenericDeclaration).Specs[0] {
+		t.Errorf("w is declared by %v", obj.Decl)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	const src = `package p
+
+func f() {
+	goto L
+L:
+	for {
+		func() {
+		L:
+			for {
+				break L
+			}
+		}()
+		continue L
+	}
+	goto M
+}
+`
+	f := parse(t, src)
+	if _, err := resolver.ResolveFile("p.go", f); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"4:7 L -> label 5:1",
+		"10:11 L -> label 8:3",
+		"13:12 L -> label 5:1",
+	}
+	if got := uses(f); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	body := f.Decls[0].(*ast.FunctionDeclaration).Body.List
+	if label := body[1].(*ast.LabeledStatement).Label; label.Obj == nil || label.Obj.Kind != ast.Lbl || label.Obj.Decl != body[1] {
+		t.Errorf("label L declares %v", label.Obj)
+	}
+	if label := body[2].(*ast.BranchStatement).Label; label.Obj != nil {
+		t.Errorf("undeclared label M resolved to %v", label.Obj)
+	}
+}
+
+func TestMultipleFiles(t *testing.T) {
+	a := parse(t, "package p\n\nvar x = y\n")
+	b := parse(t, "package p\n\nimport \"x\"\n\nvar y = 1\nvar z = x.Z\n")
+	c := parse(t, "package q\n\nvar x int\n")
+	_, err := resolver.Resolve(map[string]*ast.File{"a.go": a, "b.go": b, "c.go": c})
+	expected := []string{
+		"a.go:3:5: x already declared through import of package \"x\"",
+		"c.go:1:9: package q; expected package p",
+		"c.go:3:5: x redeclared in this block\n\tprevious declaration at a.go:3:5",
+	}
+	checkErrors(t, err, expected)
+	if obj := a.Decls[0].(*ast.GenericDeclaration).Specs[0].(*ast.ValueSpec).Values[0].(*ast.Ident).Obj; obj == nil || obj.Pos().Line != 5 {
+		t.Errorf("y resolved to %v", obj)
+	}
+}
+
+func checkErrors(t *testing.T, err error, expected []string) {
+	t.Helper()
+	var got []string
+	if list, isList := err.(parser.ErrorList); isList {
+		for _, e := range list {
+			got = append(got, e.Error())
+		}
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected errors\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, test := range []struct {
+		src    string
+		errors []string
+	}{
+		{"var x = y", []string{"p.go:1:9: undefined: y"}},
+		{"var x, x int", []string{"p.go:1:8: x redeclared in this block\n\tprevious declaration at 1:5"}},
+		{"func f(x int) { var x int }", []string{"p.go:1:21: x redeclared in this block\n\tprevious declaration at 1:8"}},
+		{"func f() { { var x int }; x = 1 }", []string{"p.go:1:27: undefined: x"}},
+		{"func f() { if x := 1; x > 0 {}; _ = x }", []string{"p.go:1:37: undefined: x"}},
+		{"func f() { for i := 0; i < 1; i++ {}; i = 0 }", []string{"p.go:1:39: undefined: i"}},
+		{"func f() { switch x := 1; x { case 1: y := 2; _ = y; default: _ = y } }", []string{"p.go:1:67: undefined: y"}},
+		{"func f() { var x = x }", []string{"p.go:1:20: undefined: x"}},
+		{"func f() { type T []T; var _ T }", nil},
+		{"func f(a T) {}; type T int", nil},
+		{"func f(x int, y x) {}", []string{"p.go:1:17: undefined: x"}},
+		{"func f() { a, a := 1, 2 }", []string{"p.go:1:15: a repeated on left side of :="}},
+		{"func f(p *struct{ x int }) { p.x := 1 }", []string{"p.go:1:30: non-name p.x on left side of :="}},
+		{"func f(x int) { x, y := 1, 2; _ = y }", nil},
+		{"func f(x int) { x := 1; _ := 2 }", []string{"p.go:1:19: no new variables on left side of :=", "p.go:1:27: no new variables on left side of :="}},
+		{"func f(x int) { if x, y := 1, 2; y > x { x, y := 3, 4 } }", nil},
+		{"func f() { _ = undefinedFunc(_) }", []string{"p.go:1:16: undefined: undefinedFunc"}},
+		{"import . \"math\"\nvar x = Pi", nil},
+		{"import \"fmt\"\nimport \"other/fmt\"", []string{"p.go:2:8: fmt redeclared in this block\n\tprevious declaration at 1:8"}},
+		{"type T struct{ x int }\nvar v = T{x: 1}", nil},
+		{"func f() { L: for { break M }; L: for {} }", nil},
+		{"func main() {}\nfunc main() {}", []string{"p.go:2:6: main redeclared in this block\n\tprevious declaration at 1:6"}},
+	} {
+		f := parse(t, test.src)
+		_, err := resolver.ResolveFile("p.go", f)
+		checkErrors(t, err, test.errors)
+	}
+}
@@ -0,0 +1,25 @@
+package resolver
+
+import "gocompiler/src/ast"
+
+// Universe is the scope of the predeclared identifiers; it encloses the scopes of all packages
+var Universe = ast.NewScope(nil)
+
+func init() {
+	for _, name := range []string{
+		"any", "bool", "byte", "comparable", "complex64", "complex128", "error", "float32", "float64",
+		"int", "int8", "int16", "int32", "int64", "rune", "string",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	} {
+		Universe.Insert(ast.NewObj(ast.Typ, name))
+	}
+	for _, name := range []string{"true", "false", "iota", "nil"} {
+		Universe.Insert(ast.NewObj(ast.Con, name))
+	}
+	for _, name := range []string{
+		"append", "cap", "clear", "close", "complex", "copy", "delete", "imag", "len", "make",
+		"max", "min", "new", "panic", "print", "println", "real", "recover",
+	} {
+		Universe.Insert(ast.NewObj(ast.Fun, name))
+	}
+}
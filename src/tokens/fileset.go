@@ -0,0 +1,59 @@
+package tokens
+
+// File is a source file registered in a FileSet
+type File struct {
+	name  string
+	size  int
+	lines int
+}
+
+// Name returns the file name as passed to AddFile
+func (f *File) Name() string {
+	return f.name
+}
+
+// Size returns the size of the file source in bytes
+func (f *File) Size() int {
+	return f.size
+}
+
+// LineCount returns the number of lines in the file
+func (f *File) LineCount() int {
+	return f.lines
+}
+
+// FileSet is the set of source files parsed together
+type FileSet struct {
+	files []*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// AddFile registers a file with the given name and source
+func (s *FileSet) AddFile(filename string, src []byte) *File {
+	f := &File{name: filename, size: len(src), lines: 1}
+	for _, b := range src {
+		if b == '\n' {
+			f.lines++
+		}
+	}
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file added under filename, or nil
+func (s *FileSet) File(filename string) *File {
+	for _, f := range s.files {
+		if f.name == filename {
+			return f
+		}
+	}
+	return nil
+}
+
+// Files returns the files of the set in the order they were added
+func (s *FileSet) Files() []*File {
+	return s.files
+}
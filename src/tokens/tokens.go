@@ -1,6 +1,10 @@
 package tokens
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 type Token struct {
 	Pos Position
@@ -17,6 +21,16 @@ func (l *Token) LexString() string {
 	return fmt.Sprintf("%v", l.Lex)
 }
 
+// End returns the position of the first character after the token
+func (l *Token) End() Position {
+	lines := strings.Count(l.Lit, "\n")
+	if lines == 0 {
+		return Position{Line: l.Pos.Line, Column: l.Pos.Column + utf8.RuneCountInString(l.Lit)}
+	}
+	last := l.Lit[strings.LastIndex(l.Lit, "\n")+1:]
+	return Position{Line: l.Pos.Line + lines, Column: utf8.RuneCountInString(last) + 1}
+}
+
 type Position struct {
 	Line   int
 	Column int
@@ -91,6 +105,7 @@ const (
 	RBRACE    // }
 	SEMICOLON // ;
 	COLON     // :
+	TILDE     // ~
 	keyword_beg
 	// keywords
 	BREAK
@@ -191,6 +206,7 @@ var tokens = []string{
 	RBRACE:    "}",
 	SEMICOLON: ";",
 	COLON:     ":",
+	TILDE:     "~",
 
 	BREAK:    "break",
 	CASE:     "case",
@@ -227,10 +243,60 @@ func (t TokenType) String() string {
 	return tokens[t]
 }
 
+// IsLiteral reports whether t is an identifier or a basic literal
+func (t TokenType) IsLiteral() bool {
+	return t >= IDENT && t <= STRING
+}
+
+// IsKeyword reports whether t is a keyword
+func (t TokenType) IsKeyword() bool {
+	return t > keyword_beg && t < keyword_end
+}
+
+// precedence levels of Go binary operators; unary operators bind tighter than any of them
+const (
+	LowestPrec  = 0
+	UnaryPrec   = 6
+	HighestPrec = 7
+)
+
+// Precedence returns the binary precedence of the operator t,
+// or LowestPrec if t is not a binary operator
+func (t TokenType) Precedence() int {
+	switch t {
+	case LOR:
+		return 1
+	case LAND:
+		return 2
+	case EQL, NEQ, LSS, LEQ, GTR, GEQ:
+		return 3
+	case ADD, SUB, OR, XOR:
+		return 4
+	case MUL, QUO, REM, SHL, SHR, AND, AND_NOT:
+		return 5
+	}
+	return LowestPrec
+}
+
 func (p Position) ToString() string {
 	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
+// Add returns the position n columns to the right of p
+func (p Position) Add(n int) Position {
+	return Position{Line: p.Line, Column: p.Column + n}
+}
+
+// Before reports whether p is before q in the source
+func (p Position) Before(q Position) bool {
+	return p.Line < q.Line || p.Line == q.Line && p.Column < q.Column
+}
+
+// IsValid reports whether the position points into the source
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
 var Keywords map[string]TokenType
 
 func InitKeywords() {
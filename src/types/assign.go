@@ -0,0 +1,394 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+	"strconv"
+)
+
+// assignableTo reports whether x can be assigned to a variable of type T, with the cause
+// if it cannot and there is more to say than the types
+func (x *operand) assignableTo(T Type) (bool, string) {
+	if x.mode == invalid || !isValid(T) {
+		return true, "" // avoid follow-up errors
+	}
+	V := x.typ
+	if identical(V, T) {
+		return true, ""
+	}
+	Vu, Tu := under(V), under(T)
+	Vp, _ := V.(*TypeParam)
+	Tp, _ := T.(*TypeParam)
+	assignableToAll := func(x *operand, tp *TypeParam) bool {
+		return tp.allTerms(func(t Type) bool {
+			ok, _ := x.assignableTo(t)
+			return ok
+		})
+	}
+
+	if isUntyped(Vu) {
+		if Tp != nil {
+			// x must be assignable to all the types of the type set of T
+			return assignableToAll(x, Tp), ""
+		}
+		switch t := Tu.(type) {
+		case *Basic:
+			if x.isNil() {
+				return false, ""
+			}
+			if x.mode == constant_ {
+				return representableConst(x.val, t, nil), ""
+			}
+			// non-constant untyped values: booleans and the results of shifts
+			if isBoolean(Vu) {
+				return isBoolean(t), ""
+			}
+			return isNumeric(Vu) && isNumeric(t), ""
+		case *Interface:
+			return x.isNil() || t.Empty(), ""
+		case *Pointer, *Signature, *Slice:
+			return x.isNil(), ""
+		}
+		return false, ""
+	}
+
+	// identical underlying types of which at least one is not a named type
+	if identical(Vu, Tu) && (!hasName(V) || !hasName(T)) && Vp == nil && Tp == nil {
+		return true, ""
+	}
+
+	if Ti, isIface := Tu.(*Interface); isIface && Tp == nil {
+		if m := missingMethod(V, Ti); m != nil {
+			return false, V.String() + " does not implement " + T.String() + " (missing method " + m.name + ")"
+		}
+		return true, ""
+	}
+
+	// a value of a type literal is assignable to a type parameter if it is assignable to all
+	// the types of its type set, and a value of a type parameter to a type literal if all the
+	// types of its type set are
+	if Tp != nil && !hasName(V) {
+		return assignableToAll(x, Tp), ""
+	}
+	if Vp != nil && !hasName(T) {
+		return Vp.allTerms(func(t Type) bool {
+			y := *x
+			y.typ = t
+			ok, _ := y.assignableTo(T)
+			return ok
+		}), ""
+	}
+	return false, ""
+}
+
+// assignment checks that x can be assigned to a variable of type T, converting an untyped x
+// to T, or to its default type if T is nil. The context describes the assignment in errors.
+// x is invalid after an error
+func (c *Checker) assignment(x *operand, T Type, context string) {
+	c.singleValue(x)
+	switch x.mode {
+	case invalid:
+		return
+	case constant_, variable, value:
+	default:
+		c.errorf(x.expr.Pos(), "cannot assign %s to %s in %s", x, T, context)
+		x.mode = invalid
+		return
+	}
+
+	if isUntyped(x.typ) {
+		target := T
+		if T == nil || isInterface(T) {
+			if T == nil && x.typ == Typ[UntypedNil] {
+				c.errorf(x.expr.Pos(), "use of untyped nil in %s", context)
+				x.mode = invalid
+				return
+			}
+			if T == nil || !x.isNil() {
+				target = defaultType(x.typ)
+			}
+		}
+		newType, val, err := c.implicitType(x, target)
+		if err != reprOk {
+			msg := ""
+			switch err {
+			case reprTruncated:
+				msg = " (truncated)"
+			case reprOverflows:
+				msg = " (overflows)"
+			}
+			c.errorf(x.expr.Pos(), "cannot use %s as %s value in %s%s", x, target, context, msg)
+			x.mode = invalid
+			return
+		}
+		c.setImplicitType(x, newType, val)
+	}
+
+	if T == nil {
+		return
+	}
+	if ok, cause := x.assignableTo(T); !ok {
+		if cause != "" {
+			c.errorf(x.expr.Pos(), "cannot use %s as %s value in %s: %s", x, T, context, cause)
+		} else {
+			c.errorf(x.expr.Pos(), "cannot use %s as %s value in %s", x, T, context)
+		}
+		x.mode = invalid
+	}
+}
+
+// initConst initializes the constant lhs with x; an untyped lhs takes the type of x
+func (c *Checker) initConst(lhs *Const, x *operand) {
+	if x.mode == invalid || !isValid(x.typ) || lhs.typ != nil && !isValid(lhs.typ) {
+		if lhs.typ == nil {
+			lhs.typ = Typ[Invalid]
+		}
+		return
+	}
+	if x.mode != constant_ {
+		c.errorf(x.expr.Pos(), "%s is not constant", x)
+		if lhs.typ == nil {
+			lhs.typ = Typ[Invalid]
+		}
+		return
+	}
+	if lhs.typ == nil {
+		lhs.typ = x.typ
+	}
+	c.assignment(x, lhs.typ, "constant declaration")
+	if x.mode == invalid {
+		return
+	}
+	lhs.val = x.val
+}
+
+// initVar initializes the variable lhs with x; a variable declared without a type
+// takes the default type of x
+func (c *Checker) initVar(lhs *Var, x *operand, context string) {
+	if x.mode == invalid || !isValid(x.typ) || lhs.typ != nil && !isValid(lhs.typ) {
+		if lhs.typ == nil {
+			lhs.typ = Typ[Invalid]
+		}
+		lhs.used = true // the variable is not reported as unused too
+		x.mode = invalid
+		return
+	}
+	if lhs.typ == nil {
+		typ := x.typ
+		if isUntyped(typ) {
+			if typ == Typ[UntypedNil] {
+				c.errorf(x.expr.Pos(), "use of untyped nil in %s", context)
+				lhs.typ = Typ[Invalid]
+				x.mode = invalid
+				return
+			}
+			typ = defaultType(typ)
+		}
+		lhs.typ = typ
+	}
+	c.assignment(x, lhs.typ, context)
+}
+
+// initVars initializes the variables lhs with the expressions rhs; the context describes
+// the declaration in errors
+func (c *Checker) initVars(lhs []*Var, rhs []ast.Expression, context string) {
+	if len(lhs) == len(rhs) {
+		for i, v := range lhs {
+			var x operand
+			c.expr(&x, rhs[i])
+			c.initVar(v, &x, context)
+		}
+		return
+	}
+	if len(rhs) == 1 {
+		xs := c.multiExpr(rhs[0])
+		if len(xs) == len(lhs) {
+			for i, v := range lhs {
+				c.initVar(v, xs[i], context)
+			}
+			return
+		}
+		if xs[0].mode != invalid {
+			c.assignError(rhs, len(lhs), len(xs))
+		}
+	} else {
+		c.use(rhs...)
+		c.assignError(rhs, len(lhs), len(rhs))
+	}
+	for _, v := range lhs {
+		if v.typ == nil {
+			v.typ = Typ[Invalid]
+		}
+		v.used = true
+	}
+}
+
+// assignError reports that the number of values of rhs, r, does not match the number of
+// variables on the left side, l
+func (c *Checker) assignError(rhs []ast.Expression, l, r int) {
+	vars, vals := measure(l, "variable"), measure(r, "value")
+	if len(rhs) == 1 {
+		if call, isCall := unparen(rhs[0]).(*ast.CallExpression); isCall {
+			c.errorf(rhs[0].Pos(), "assignment mismatch: %s but %s returns %s", vars, call.Function, vals)
+			return
+		}
+	}
+	c.errorf(rhs[0].Pos(), "assignment mismatch: %s but %s", vars, vals)
+}
+
+// measure returns n and the unit, in the plural unless n is 1
+func measure(n int, unit string) string {
+	if n != 1 {
+		unit += "s"
+	}
+	return strconv.Itoa(n) + " " + unit
+}
+
+// multiExpr checks the expression e, which may be a call returning several values, and
+// returns an operand for every value
+func (c *Checker) multiExpr(e ast.Expression) []*operand {
+	var x operand
+	c.rawExpr(&x, e, nil)
+	c.exclude(&x, novalue, builtin, typexpr)
+	c.nonGeneric(&x)
+	if t, isTuple := x.typ.(*Tuple); isTuple && x.mode == value {
+		list := make([]*operand, t.Len())
+		for i, v := range t.vars {
+			list[i] = &operand{mode: value, expr: e, typ: v.typ}
+		}
+		return list
+	}
+	return []*operand{&x}
+}
+
+// lhsVar checks the left side lhs of an assignment and returns its type, or nil for the
+// blank identifier. Assigning to a variable does not use it
+func (c *Checker) lhsVar(lhs ast.Expression) Type {
+	ident, isIdent := unparen(lhs).(*ast.Ident)
+	if isIdent && ident.Name == "_" {
+		c.recordDef(ident, nil)
+		return nil
+	}
+
+	var v *Var
+	var used bool
+	if isIdent {
+		if obj, isVar := c.lookup(ident).(*Var); isVar {
+			v, used = obj, obj.used
+		}
+	}
+	var x operand
+	c.expr(&x, lhs)
+	if v != nil {
+		v.used = used
+	}
+
+	switch x.mode {
+	case invalid:
+		return Typ[Invalid]
+	case variable:
+	default:
+		c.errorf(x.expr.Pos(), "cannot assign to %s (neither addressable nor a map index expression)", x.expr)
+		return Typ[Invalid]
+	}
+	return x.typ
+}
+
+// assignVar assigns x to the left side lhs of an assignment
+func (c *Checker) assignVar(lhs ast.Expression, x *operand) {
+	T := c.lhsVar(lhs)
+	if T != nil && !isValid(T) {
+		return
+	}
+	c.assignment(x, T, "assignment")
+}
+
+// assignVars assigns the expressions rhs to the left sides lhs
+func (c *Checker) assignVars(lhs, rhs []ast.Expression) {
+	if len(lhs) == len(rhs) {
+		for i, e := range lhs {
+			var x operand
+			c.expr(&x, rhs[i])
+			c.assignVar(e, &x)
+		}
+		return
+	}
+	if len(rhs) == 1 {
+		xs := c.multiExpr(rhs[0])
+		if len(xs) == len(lhs) {
+			for i, e := range lhs {
+				c.assignVar(e, xs[i])
+			}
+			return
+		}
+		if xs[0].mode != invalid {
+			c.assignError(rhs, len(lhs), len(xs))
+		}
+	} else {
+		c.use(rhs...)
+		c.assignError(rhs, len(lhs), len(rhs))
+	}
+	for _, e := range lhs {
+		c.lhsVar(e)
+	}
+}
+
+// shortVarDecl checks the short variable declaration s: the new variables on the left side are
+// declared with the types of the values, the variables declared before are assigned to
+func (c *Checker) shortVarDecl(s *ast.AssignStatement) {
+	var lhsVars []*Var
+	var newVars []*Var
+	var newIdents []*ast.Ident
+	for _, e := range s.Lhs {
+		ident, isIdent := e.(*ast.Ident)
+		if !isIdent {
+			c.use(e) // reported by the resolver
+			lhsVars = append(lhsVars, NewVar(e.Pos(), "_", nil))
+			continue
+		}
+		if ident.Obj != nil && ident.Obj.Decl != s {
+			// a variable declared before in the same scope
+			if obj, isVar := c.lookup(ident).(*Var); isVar {
+				c.recordUse(ident, obj)
+				lhsVars = append(lhsVars, obj)
+				continue
+			}
+			c.errorf(ident.Pos(), "cannot assign to %s", ident.Name)
+			lhsVars = append(lhsVars, NewVar(ident.Pos(), "_", nil))
+			continue
+		}
+		v := NewVar(ident.Pos(), ident.Name, nil)
+		lhsVars = append(lhsVars, v)
+		newVars = append(newVars, v)
+		newIdents = append(newIdents, ident)
+	}
+
+	c.initVars(lhsVars, s.Rhs, "assignment")
+
+	for i, v := range newVars {
+		c.declareVar(newIdents[i], v)
+	}
+}
+
+// opAssign checks the assignment operation s, like x += y
+func (c *Checker) opAssign(s *ast.AssignStatement) {
+	if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+		c.errorf(s.TokPos, "assignment operation %s requires single-valued expressions", s.Tok.Tok)
+		c.use(s.Lhs...)
+		c.use(s.Rhs...)
+		return
+	}
+	op := s.Tok.Tok - tokens.ADD_ASSIGN + tokens.ADD
+	var x operand
+	c.binary(&x, nil, s.Lhs[0], s.Rhs[0], op, s.TokPos)
+	if x.mode == invalid {
+		return
+	}
+	c.assignVar(s.Lhs[0], &x)
+}
@@ -0,0 +1,418 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+)
+
+// builtin sets x to the result of the call e of the built-in function id and reports
+// whether the call is valid
+func (c *Checker) builtin(x *operand, e *ast.CallExpression, id builtinId) bool {
+	bin := predeclaredFuncs[id]
+	if e.Ellipsis.IsValid() && id != _Append {
+		c.errorf(e.Ellipsis, "invalid operation: invalid use of ... with built-in %s", bin.name)
+		c.use(e.Arguments...)
+		return false
+	}
+
+	// len and cap of an array are constant unless the argument contains a call
+	if id == _Len || id == _Cap {
+		defer func(b bool) { c.hasCallOrRecv = b }(c.hasCallOrRecv)
+		c.hasCallOrRecv = false
+	}
+
+	// the arguments of make and new start with a type, evaluated by the cases below
+	var args []*operand
+	switch id {
+	case _Make, _New:
+	default:
+		args = c.exprList(e.Arguments)
+		for _, a := range args {
+			if a.mode == invalid {
+				return false
+			}
+		}
+	}
+	nargs := len(e.Arguments)
+	if args != nil {
+		nargs = len(args)
+	}
+
+	msg := ""
+	if nargs < bin.nargs {
+		msg = "not enough"
+	} else if !bin.variadic && nargs > bin.nargs {
+		msg = "too many"
+	}
+	if msg != "" {
+		pos := e.RParenPos
+		if msg == "too many" && bin.nargs < len(e.Arguments) {
+			pos = e.Arguments[bin.nargs].Pos()
+		}
+		c.errorf(pos, "%s arguments for %s (expected %d, found %d)", msg, exprString(e), bin.nargs, nargs)
+		if args == nil {
+			c.use(e.Arguments...)
+		}
+		return false
+	}
+	if args != nil {
+		*x = *args[0]
+	}
+
+	switch id {
+	case _Append:
+		// append(s S, x ...E) S
+		s := args[0]
+		S := s.typ
+		slice, isSlice := coreType(S).(*Slice)
+		if !isSlice {
+			if s.isNil() {
+				c.errorf(s.expr.Pos(), "first argument to append must be a typed slice; have untyped nil")
+			} else {
+				c.errorf(s.expr.Pos(), "invalid argument: %s is not a slice", s)
+			}
+			return false
+		}
+		if e.Ellipsis.IsValid() {
+			if nargs != 2 {
+				c.errorf(e.Ellipsis, "can only use ... with final argument in list")
+				return false
+			}
+			// append([]byte, string...) appends the bytes of the string
+			if b, isBasic := under(slice.elem).(*Basic); isBasic && b.kind == Byte && isString(args[1].typ) {
+				c.assignment(args[1], Typ[String], "argument to append")
+			} else {
+				c.assignment(args[1], S, "argument to append")
+			}
+		} else {
+			for _, a := range args[1:] {
+				c.assignment(a, slice.elem, "argument to append")
+			}
+		}
+		x.mode = value
+		x.typ = S
+
+	case _Cap, _Len:
+		// cap(x), len(x)
+		a := args[0]
+		mode := invalid
+		var val constant.Value
+		arrayLen := func(t *Array) {
+			mode = value
+			if !c.hasCallOrRecv {
+				mode = constant_
+				if t.len < 0 {
+					val = constant.MakeUnknown()
+				} else {
+					val = constant.MakeInt64(t.len)
+				}
+			}
+		}
+		hasLen := func(u Type) bool {
+			switch t := u.(type) {
+			case *Basic:
+				return isString(t) && id == _Len
+			case *Array, *Slice:
+				return true
+			case *Pointer:
+				_, isArray := under(t.base).(*Array)
+				return isArray
+			}
+			return false
+		}
+		if tp, isTypeParam := a.typ.(*TypeParam); isTypeParam {
+			// the length of a value of a type parameter type is not constant
+			if tp.iface().typeSet().underIs(hasLen) {
+				mode = value
+			}
+		} else if u := under(a.typ); hasLen(u) {
+			switch t := u.(type) {
+			case *Basic:
+				mode = value
+				if a.mode == constant_ {
+					mode = constant_
+					val = constant.MakeInt64(int64(len(constant.StringVal(a.val))))
+				}
+			case *Array:
+				arrayLen(t)
+			case *Pointer:
+				arrayLen(under(t.base).(*Array))
+			case *Slice:
+				mode = value
+			}
+		}
+		if mode == invalid {
+			if isValid(a.typ) {
+				c.errorf(a.expr.Pos(), "invalid argument: %s for built-in %s", a, bin.name)
+			}
+			return false
+		}
+		if isUntyped(a.typ) {
+			c.updateExprType(a.expr, defaultType(a.typ), true)
+		}
+		x.mode = mode
+		x.typ = Typ[Int]
+		x.val = val
+
+	case _Clear:
+		// clear(s)
+		if _, isSlice := coreType(args[0].typ).(*Slice); !isSlice {
+			c.errorf(args[0].expr.Pos(), "invalid argument: %s must be a map or slice", args[0])
+			return false
+		}
+		x.mode = novalue
+
+	case _Close:
+		// the subset has no channels
+		c.errorf(args[0].expr.Pos(), "invalid operation: non-chan argument %s", args[0])
+		return false
+
+	case _Complex:
+		// complex(r, i float) complex
+		r, i := args[0], args[1]
+		switch {
+		case isTyped(r.typ) && isTyped(i.typ):
+		case isTyped(i.typ):
+			c.convertUntyped(r, i.typ)
+		case isTyped(r.typ):
+			c.convertUntyped(i, r.typ)
+		case r.mode == constant_ && i.mode == constant_:
+			// untyped numeric constants without imaginary parts are floats
+			toFloat := func(x *operand) {
+				if isNumeric(x.typ) && constant.Sign(constant.Imag(x.val)) == 0 {
+					x.typ = Typ[UntypedFloat]
+				}
+			}
+			toFloat(r)
+			toFloat(i)
+		default:
+			// an untyped non-constant shift
+			c.convertUntyped(r, Typ[Float64])
+			c.convertUntyped(i, Typ[Float64])
+		}
+		if r.mode == invalid || i.mode == invalid {
+			return false
+		}
+		if !identical(r.typ, i.typ) {
+			c.errorf(r.expr.Pos(), "invalid operation: %s (mismatched types %s and %s)", exprString(e), r.typ, i.typ)
+			return false
+		}
+		var res Type
+		if t, isBasic := under(r.typ).(*Basic); isBasic {
+			switch t.kind {
+			case Float32:
+				res = Typ[Complex64]
+			case Float64:
+				res = Typ[Complex128]
+			case UntypedFloat:
+				res = Typ[UntypedComplex]
+			}
+		}
+		if res == nil {
+			c.errorf(r.expr.Pos(), "invalid argument: arguments have type %s, expected floating-point", r.typ)
+			return false
+		}
+		*x = *r
+		if r.mode == constant_ && i.mode == constant_ {
+			x.val = constant.BinaryOp(constant.ToFloat(r.val), tokens.ADD, constant.MakeImag(constant.ToFloat(i.val)))
+		} else {
+			x.mode = value
+		}
+		if x.mode != constant_ {
+			c.updateExprType(r.expr, r.typ, true)
+			c.updateExprType(i.expr, i.typ, true)
+		}
+		x.typ = res
+
+	case _Copy:
+		// copy(dst, src []T) int
+		dst, isSlice := coreType(args[0].typ).(*Slice)
+		if !isSlice {
+			c.errorf(args[0].expr.Pos(), "invalid argument: copy expects slice arguments; found %s and %s", args[0], args[1])
+			return false
+		}
+		var srcElem Type
+		switch t := coreType(args[1].typ).(type) {
+		case *Slice:
+			srcElem = t.elem
+		case *Basic:
+			if isString(t) {
+				srcElem = universe["byte"].Type()
+			}
+		}
+		if srcElem == nil {
+			c.errorf(args[0].expr.Pos(), "invalid argument: copy expects slice arguments; found %s and %s", args[0], args[1])
+			return false
+		}
+		if !identical(dst.elem, srcElem) {
+			c.errorf(args[0].expr.Pos(), "invalid argument: arguments to copy %s and %s have different element types %s and %s",
+				args[0], args[1], dst.elem, srcElem)
+			return false
+		}
+		if isUntyped(args[1].typ) {
+			c.updateExprType(args[1].expr, Typ[String], true)
+		}
+		x.mode = value
+		x.typ = Typ[Int]
+
+	case _Delete:
+		// the subset has no maps
+		c.errorf(args[0].expr.Pos(), "invalid argument: %s is not a map", args[0])
+		return false
+
+	case _Imag, _Real:
+		// imag(c complex) float, real(c complex) float
+		if isUntyped(x.typ) {
+			if x.mode == constant_ {
+				// an untyped numeric constant is a complex constant
+				if isNumeric(x.typ) {
+					x.typ = Typ[UntypedComplex]
+				}
+			} else {
+				// an untyped non-constant shift, which cannot be complex
+				c.convertUntyped(x, Typ[Complex128])
+				if x.mode == invalid {
+					return false
+				}
+			}
+		}
+		var res Type
+		if t, isBasic := under(x.typ).(*Basic); isBasic {
+			switch t.kind {
+			case Complex64:
+				res = Typ[Float32]
+			case Complex128:
+				res = Typ[Float64]
+			case UntypedComplex:
+				res = Typ[UntypedFloat]
+			}
+		}
+		if res == nil {
+			c.errorf(x.expr.Pos(), "invalid argument: argument has type %s, expected complex type", x.typ)
+			return false
+		}
+		if x.mode == constant_ {
+			if id == _Real {
+				x.val = constant.Real(x.val)
+			} else {
+				x.val = constant.Imag(x.val)
+			}
+		} else {
+			x.mode = value
+		}
+		x.typ = res
+
+	case _Make:
+		// make(T, n), make(T, n, m)
+		T := c.varType(e.Arguments[0])
+		if !isValid(T) {
+			c.use(e.Arguments[1:]...)
+			return false
+		}
+		if _, isSlice := coreType(T).(*Slice); !isSlice {
+			c.errorf(e.Arguments[0].Pos(), "invalid argument: cannot make %s; type must be slice, map, or channel", e.Arguments[0])
+			c.use(e.Arguments[1:]...)
+			return false
+		}
+		if nargs == 1 || nargs > 3 {
+			c.errorf(e.Pos(), "invalid operation: %s expects 2 or 3 arguments; found %d", exprString(e), nargs)
+			c.use(e.Arguments[1:]...)
+			return false
+		}
+		var sizes []int64 // the constant sizes
+		for _, arg := range e.Arguments[1:] {
+			var a operand
+			c.expr(&a, arg)
+			if c.isValidIndex(&a, "index") && a.mode == constant_ {
+				if n, ok := constant.Int64Val(a.val); ok {
+					sizes = append(sizes, n)
+				}
+			}
+		}
+		if len(sizes) == 2 && sizes[0] > sizes[1] {
+			c.errorf(e.Arguments[1].Pos(), "invalid argument: length and capacity swapped")
+		}
+		x.mode = value
+		x.typ = T
+
+	case _Max, _Min:
+		// max(x, y...), min(x, y...)
+		op := tokens.LSS
+		if id == _Max {
+			op = tokens.GTR
+		}
+		for i, a := range args {
+			if !isOrdered(a.typ) {
+				c.errorf(a.expr.Pos(), "invalid argument: %s cannot be ordered", a)
+				return false
+			}
+			if i > 0 {
+				c.matchTypes(x, a)
+				if x.mode == invalid {
+					return false
+				}
+				if !identical(x.typ, a.typ) {
+					c.errorf(a.expr.Pos(), "invalid argument: mismatched types %s (previous argument) and %s (type of %s)", x.typ, a.typ, a.expr)
+					return false
+				}
+				if x.mode == constant_ && a.mode == constant_ {
+					if constant.Compare(a.val, op, x.val) {
+						*x = *a
+					}
+				} else {
+					x.mode = value
+				}
+			}
+		}
+		if x.mode != constant_ {
+			x.mode = value
+			c.assignment(x, nil, "argument to built-in "+bin.name)
+			if x.mode == invalid {
+				return false
+			}
+		}
+		for _, a := range args {
+			c.updateExprType(a.expr, x.typ, true)
+		}
+
+	case _New:
+		// new(T)
+		T := c.varType(e.Arguments[0])
+		if !isValid(T) {
+			return false
+		}
+		x.mode = value
+		x.typ = NewPointer(T)
+
+	case _Panic:
+		// panic(x)
+		c.assignment(args[0], universeAny, "argument to panic")
+		if args[0].mode == invalid {
+			return false
+		}
+		x.mode = novalue
+
+	case _Print, _Println:
+		// print(x, y, ...), println(x, y, ...)
+		for _, a := range args {
+			c.assignment(a, nil, "argument to built-in "+bin.name)
+			if a.mode == invalid {
+				return false
+			}
+		}
+		x.mode = novalue
+
+	case _Recover:
+		// recover() interface{}
+		x.mode = value
+		x.typ = universeAny
+	}
+	return true
+}
@@ -0,0 +1,421 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"strings"
+	"unicode"
+)
+
+// callExpr sets x to the result of the call, conversion or built-in call e and returns
+// whether e may be used as an expression statement
+func (c *Checker) callExpr(x *operand, e *ast.CallExpression) exprKind {
+	var xlist []ast.Expression // explicit type arguments of a generic function
+	switch f := e.Function.(type) {
+	case *ast.IndexExpression, *ast.IndexExpressions:
+		if c.indexExpr(x, f) {
+			xlist = indexList(f)
+		}
+		x.expr = f
+		if x.mode == invalid {
+			x.typ = Typ[Invalid]
+		}
+		if xlist == nil {
+			c.record(x)
+		}
+	default:
+		c.genericExprOrType(x, e.Function)
+	}
+
+	switch x.mode {
+	case invalid:
+		c.use(e.Arguments...)
+		x.expr = e
+		return statement
+
+	case typexpr:
+		c.nonGeneric(x)
+		if x.mode == invalid {
+			c.use(e.Arguments...)
+			x.expr = e
+			return expression
+		}
+		T := x.typ
+		x.mode = invalid
+		switch n := len(e.Arguments); n {
+		case 0:
+			c.errorf(e.RParenPos, "missing argument in conversion to %s", T)
+		case 1:
+			c.expr(x, e.Arguments[0])
+			if x.mode != invalid {
+				if e.Ellipsis.IsValid() {
+					c.errorf(e.Ellipsis, "invalid use of ... in conversion to %s", T)
+					break
+				}
+				c.conversion(x, T)
+			}
+		default:
+			c.use(e.Arguments...)
+			c.errorf(e.Arguments[n-1].Pos(), "too many arguments in conversion to %s", T)
+		}
+		x.expr = e
+		return expression
+
+	case builtin:
+		id := x.id
+		if !c.builtin(x, e, id) {
+			x.mode = invalid
+		}
+		x.expr = e
+		if x.mode != invalid && x.mode != constant_ {
+			c.hasCallOrRecv = true
+		}
+		return predeclaredFuncs[id].kind
+	}
+
+	sig, isFunc := coreType(x.typ).(*Signature)
+	if !isFunc {
+		c.errorf(x.expr.Pos(), "invalid operation: cannot call non-function %s", x)
+		c.use(e.Arguments...)
+		x.mode = invalid
+		x.expr = e
+		return statement
+	}
+
+	var targs []Type
+	if xlist != nil {
+		targs = c.typeList(xlist)
+		if targs == nil {
+			c.use(e.Arguments...)
+			x.mode = invalid
+			x.expr = e
+			return statement
+		}
+		if got, want := len(targs), len(sig.tparams); got > want {
+			c.errorf(xlist[want].Pos(), "got %d type arguments but %s has %d type parameters", got, x.expr, want)
+			c.use(e.Arguments...)
+			x.mode = invalid
+			x.expr = e
+			return statement
+		}
+	}
+
+	args := c.exprList(e.Arguments)
+	sig = c.arguments(e, sig, targs, xlist, args)
+	c.hasCallOrRecv = true
+	if sig == nil {
+		x.mode = invalid
+		x.expr = e
+		return statement
+	}
+
+	switch sig.results.Len() {
+	case 0:
+		x.mode = novalue
+	case 1:
+		x.mode = value
+		x.typ = sig.results.At(0).typ
+	default:
+		x.mode = value
+		x.typ = sig.results
+	}
+	x.expr = e
+	return statement
+}
+
+// exprList checks the arguments of a call and returns an operand for every value;
+// a single argument may be a call returning several values
+func (c *Checker) exprList(list []ast.Expression) []*operand {
+	if len(list) == 1 {
+		return c.multiExpr(list[0])
+	}
+	xs := make([]*operand, len(list))
+	for i, e := range list {
+		var x operand
+		c.expr(&x, e)
+		xs[i] = &x
+	}
+	return xs
+}
+
+// arguments checks the arguments args of the call e of a function with the signature sig and
+// returns the signature of the called function. For a generic function, it is the signature of
+// the instance for the explicit type arguments targs, at the expressions xlist, and the inferred
+// ones; it is nil if these cannot be inferred or do not satisfy their constraints
+func (c *Checker) arguments(e *ast.CallExpression, sig *Signature, targs []Type, xlist []ast.Expression, args []*operand) *Signature {
+	rsig := sig
+	if sig.tparams != nil {
+		rsig = nil
+	}
+	for _, x := range args {
+		if x.mode == invalid {
+			return rsig
+		}
+	}
+
+	nargs := len(args)
+	npars := sig.params.Len()
+	ddd := e.Ellipsis.IsValid()
+
+	// the parameters the arguments are assigned to
+	params := sig.params
+	if sig.variadic {
+		if ddd {
+			// variadic(a, b, c...)
+			if len(e.Arguments) == 1 && nargs > 1 {
+				c.errorf(e.Ellipsis, "cannot use ... with %d-valued %s", nargs, e.Arguments[0])
+				return rsig
+			}
+		} else if nargs >= npars-1 {
+			// variadic(a, b, c): the arguments for the last parameter take its element type
+			vars := make([]*Var, npars-1, nargs)
+			copy(vars, sig.params.vars)
+			last := sig.params.vars[npars-1]
+			for len(vars) < nargs {
+				vars = append(vars, NewVar(last.pos, last.name, last.typ.(*Slice).elem))
+			}
+			params = NewTuple(vars...)
+			npars = nargs
+		} else {
+			npars-- // the variadic parameter may have no arguments
+		}
+	} else if ddd {
+		c.errorf(e.Ellipsis, "cannot use ... in call to non-variadic %s", e.Function)
+		return rsig
+	}
+
+	if nargs != npars {
+		pos := e.RParenPos
+		qualifier := "not enough"
+		if nargs > npars {
+			pos = args[npars].expr.Pos()
+			qualifier = "too many"
+		} else if nargs > 0 {
+			pos = args[nargs-1].expr.Pos()
+		}
+		c.errorf(pos, "%s arguments in call to %s\n\thave %s\n\twant %s",
+			qualifier, e.Function, typesSummary(operandTypes(args), false), typesSummary(varTypes(sig.params), sig.variadic))
+		return rsig
+	}
+
+	if sig.tparams != nil {
+		targs, srcs := c.infer(e, sig.tparams, targs, xlist, params, args)
+		if targs == nil {
+			return nil
+		}
+		if i, cause := verify(sig.tparams, targs); i >= 0 {
+			pos := e.Function.Pos()
+			if srcs[i] != nil {
+				pos = srcs[i].Pos()
+			}
+			c.errorf(pos, "%s", cause)
+			return nil
+		}
+		rsig = instantiateSignature(sig, targs)
+		params = subst(params, makeSubstMap(sig.tparams, targs)).(*Tuple)
+		c.recordTypeAndValue(e.Function, value, rsig, nil)
+	}
+
+	context := "argument to " + exprString(e.Function)
+	for i, x := range args {
+		c.assignment(x, params.At(i).typ, context)
+	}
+	return rsig
+}
+
+func operandTypes(list []*operand) []Type {
+	types := make([]Type, len(list))
+	for i, x := range list {
+		types[i] = x.typ
+	}
+	return types
+}
+
+func varTypes(t *Tuple) []Type {
+	types := make([]Type, t.Len())
+	for i := range types {
+		types[i] = t.At(i).typ
+	}
+	return types
+}
+
+// typesSummary returns a list of types in parentheses for the errors of counts; the untyped
+// types are shown as number, string, bool or nil and the last type of a variadic list as ...T
+func typesSummary(list []Type, variadic bool) string {
+	res := make([]string, len(list))
+	for i, t := range list {
+		var s string
+		switch {
+		case t == nil || !isValid(t):
+			s = "unknown type"
+		case isUntyped(t):
+			if isNumeric(t) {
+				s = "number"
+			} else {
+				s = strings.TrimPrefix(t.String(), "untyped ")
+			}
+		case variadic && i == len(list)-1:
+			s = "..." + t.(*Slice).elem.String()
+		default:
+			s = t.String()
+		}
+		res[i] = s
+	}
+	return "(" + strings.Join(res, ", ") + ")"
+}
+
+// conversion converts x to the type T, for an explicit conversion T(x)
+func (c *Checker) conversion(x *operand, T Type) {
+	constArg := x.mode == constant_
+	var ok bool
+	switch {
+	case constArg && isTypeParam(T):
+		// the constant must convert to all the types of the type set of T; the result is
+		// not constant
+		ok = T.(*TypeParam).allTerms(func(t Type) bool {
+			y := *x
+			if isConstType(t) {
+				return y.constConvertibleTo(t)
+			}
+			return y.convertibleTo(t)
+		})
+	case constArg && isConstType(T):
+		ok = x.constConvertibleTo(T)
+		// a conversion from an integer constant to an integer type can only overflow
+		if !ok && isInteger(x.typ) && isInteger(T) {
+			c.errorf(x.expr.Pos(), "constant %s overflows %s", x.val, T)
+			x.mode = invalid
+			return
+		}
+	default:
+		ok = x.convertibleTo(T)
+	}
+	if !ok {
+		c.errorf(x.expr.Pos(), "cannot convert %s to type %s", x, T)
+		x.mode = invalid
+		return
+	}
+
+	// an untyped argument takes the type T, or its default type if T is an interface or a
+	// constant is converted to a type that has no constants; an integer converted to a string
+	// keeps its type
+	if isUntyped(x.typ) {
+		final := T
+		if isInterface(T) || constArg && !isConstType(T) {
+			final = defaultType(x.typ)
+		} else if x.mode == constant_ && isInteger(x.typ) && isString(T) {
+			final = x.typ
+		}
+		c.updateExprType(x.expr, final, true)
+	}
+	if !constArg || !isConstType(T) {
+		x.mode = value
+	}
+	x.typ = T
+}
+
+// constConvertibleTo reports whether the constant x can be converted to a constant of the
+// basic type T and sets its value to the converted value: x must be representable by T,
+// or an integer converted to a string
+func (x *operand) constConvertibleTo(T Type) bool {
+	t := under(T).(*Basic)
+	switch {
+	case representableConst(x.val, t, &x.val):
+		return true
+	case isInteger(x.typ) && isString(t):
+		codepoint := unicode.ReplacementChar
+		if i, ok := constant.Uint64Val(x.val); ok && i <= unicode.MaxRune {
+			codepoint = rune(i)
+		}
+		x.val = constant.MakeString(string(codepoint))
+		return true
+	}
+	return false
+}
+
+// convertibleTo reports whether the non-constant x can be converted to the type T
+func (x *operand) convertibleTo(T Type) bool {
+	if ok, _ := x.assignableTo(T); ok {
+		return true
+	}
+	V := x.typ
+
+	// a conversion from or to a type parameter is valid if it is valid for all the types of
+	// its type set
+	Vp, _ := V.(*TypeParam)
+	Tp, _ := T.(*TypeParam)
+	switch {
+	case Vp != nil:
+		return Vp.allTerms(func(v Type) bool {
+			y := *x
+			y.typ = v
+			return y.convertibleTo(T)
+		})
+	case Tp != nil:
+		return Tp.allTerms(func(t Type) bool { return x.convertibleTo(t) })
+	}
+
+	Vu, Tu := under(V), under(T)
+
+	// identical underlying types, ignoring the struct tags
+	if identicalIgnoreTags(Vu, Tu) {
+		return true
+	}
+	// unnamed pointer types with identical underlying base types
+	if Vp, isPtr := V.(*Pointer); isPtr {
+		if Tp, isPtr := T.(*Pointer); isPtr && identicalIgnoreTags(under(Vp.base), under(Tp.base)) {
+			return true
+		}
+	}
+	// integers and floats convert to each other, complex numbers to complex numbers
+	if (isInteger(Vu) || isFloat(Vu)) && (isInteger(Tu) || isFloat(Tu)) {
+		return true
+	}
+	if isComplex(Vu) && isComplex(Tu) {
+		return true
+	}
+	// integers, byte slices and rune slices convert to strings, strings to byte and rune slices
+	if (isInteger(Vu) || isBytesOrRunes(Vu)) && isString(Tu) {
+		return true
+	}
+	if isString(Vu) && isBytesOrRunes(Tu) {
+		return true
+	}
+	// slices convert to arrays and to pointers to arrays of the same element type
+	if s, isSlice := Vu.(*Slice); isSlice {
+		switch a := Tu.(type) {
+		case *Array:
+			return identical(s.elem, a.elem)
+		case *Pointer:
+			if a, isArray := under(a.base).(*Array); isArray {
+				return identical(s.elem, a.elem)
+			}
+		}
+	}
+	return false
+}
+
+// isBytesOrRunes reports whether t is a slice of bytes or runes
+func isBytesOrRunes(t Type) bool {
+	if s, isSlice := t.(*Slice); isSlice {
+		b, isBasic := under(s.elem).(*Basic)
+		return isBasic && (b.kind == Byte || b.kind == Rune)
+	}
+	return false
+}
+
+// identicalIgnoreTags reports whether x and y are identical types, ignoring the struct tags
+func identicalIgnoreTags(x, y Type) bool {
+	xs, isStruct := x.(*Struct)
+	ys, isStruct2 := y.(*Struct)
+	if !isStruct || !isStruct2 {
+		return identical(x, y)
+	}
+	return identical(NewStruct(xs.fields, nil), NewStruct(ys.fields, nil))
+}
@@ -0,0 +1,345 @@
+// Copyright 2011 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"fmt"
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/parser"
+	"gocompiler/src/resolver"
+	"gocompiler/src/tokens"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// noPos is the position of the predeclared objects
+var noPos tokens.Position
+
+// Check type-checks the files of a package, keyed by their names, and records the types of
+// the expressions and the objects of the identifiers in the maps of info that are not nil.
+// The identifiers are resolved by resolver.Resolve first. The errors are returned in a
+// parser.ErrorList sorted by file name and position, the errors of the resolver included;
+// the package is complete even then
+func Check(files map[string]*ast.File, info *Info) (*Package, error) {
+	if info == nil {
+		info = &Info{}
+	}
+	astPkg, err := resolver.Resolve(files)
+	c := &Checker{
+		info:    info,
+		pkg:     &Package{name: astPkg.Name, objects: map[string]Object{}},
+		scope:   astPkg.Scope,
+		files:   files,
+		objMap:  map[*ast.Object]Object{},
+		imports: map[*ast.ImportSpec]*PkgName{},
+		decls:   map[Object]*declInfo{},
+		untyped: map[ast.Expression]exprInfo{},
+	}
+	if list, isList := err.(parser.ErrorList); isList {
+		c.errors = append(c.errors, list...)
+	}
+	c.checkFiles()
+	c.errors.Sort()
+	return c.pkg, c.errors.Err()
+}
+
+// CheckFile type-checks f, the only file of its package, with Check
+func CheckFile(filename string, f *ast.File, info *Info) (*Package, error) {
+	return Check(map[string]*ast.File{filename: f}, info)
+}
+
+// Checker holds the state of the type checking of a package
+type Checker struct {
+	info     *Info
+	pkg      *Package
+	scope    *ast.Scope // package scope built by the resolver
+	files    map[string]*ast.File
+	errors   parser.ErrorList
+	filename string // file of the checked declaration
+
+	objMap  map[*ast.Object]Object       // objects of the resolved identifiers
+	imports map[*ast.ImportSpec]*PkgName // package names of the imports
+	decls   map[Object]*declInfo         // package level declarations, checked on first use
+	objList []Object                     // package level objects in source order
+	path    []Object                     // package level objects being checked, for cycle detection
+	untyped map[ast.Expression]exprInfo  // untyped expressions whose final type is not known yet
+	funcs   []funcInfo                   // function bodies to check after the package level declarations
+	sig     *Signature                   // signature of the function whose body is checked; or nil
+	locals  []*Var                       // variables declared in the function body being checked
+	ctxt    stmtContext                  // branch statements permitted in the checked statement
+
+	hasCallOrRecv bool            // the checked expression contains a function call, so len and cap are not constant
+	iota          constant.Value  // value of iota in a constant declaration; or nil
+	errpos        tokens.Position // position of the errors in an inherited constant expression; or invalid
+}
+
+// declInfo describes the declaration of a package level object
+type declInfo struct {
+	file     string
+	state    declState
+	spec     *ast.ValueSpec           // spec of a constant or variable
+	init     *ast.ValueSpec           // spec giving the type and values of a constant, a previous one if inherited
+	iota     int                      // index of the spec of a constant in its declaration
+	lhs      []*Var                   // all variables of a spec whose values do not match them one to one
+	typeSpec *ast.TypeSpec            // spec of a type name
+	fdecl    *ast.FunctionDeclaration // declaration of a function
+}
+
+type declState int
+
+const (
+	unchecked declState = iota
+	inProgress
+	checked
+)
+
+// funcInfo is a function body waiting to be checked
+type funcInfo struct {
+	file string
+	sig  *Signature
+	body *ast.BlockStatement
+}
+
+// exprInfo is the information recorded for an untyped expression
+type exprInfo struct {
+	isLhs bool // the expression is the left operand of a shift with a delayed type
+	mode  operandMode
+	typ   *Basic
+	val   constant.Value // the value of a constant expression
+}
+
+func (c *Checker) errorf(pos tokens.Position, format string, args ...any) {
+	if c.errpos.IsValid() {
+		pos = c.errpos
+	}
+	for i, arg := range args {
+		if x, isExpr := arg.(ast.Expression); isExpr {
+			args[i] = exprString(x)
+		}
+	}
+	c.errors = append(c.errors, &parser.Error{Filename: c.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// errorAt returns an error at pos in the given file
+func (c *Checker) errorAt(filename string, pos tokens.Position, msg string) *parser.Error {
+	return &parser.Error{Filename: filename, Pos: pos, Msg: msg}
+}
+
+// checkFiles checks the package level declarations and then the function bodies
+func (c *Checker) checkFiles() {
+	c.collectObjects()
+	for _, obj := range c.objList {
+		c.objDecl(obj)
+	}
+	for i := 0; i < len(c.funcs); i++ {
+		f := c.funcs[i]
+		c.filename = f.file
+		c.funcBody(f.sig, f.body)
+	}
+	c.unusedImports()
+	c.recordUntyped()
+}
+
+// unusedImports reports the imported packages that are not used. The blank and dot imports
+// are not reported, the names in the scope of a dot import are not known
+func (c *Checker) unusedImports() {
+	names := make([]string, 0, len(c.files))
+	for name := range c.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c.filename = name
+		for _, spec := range c.files[name].Imports {
+			obj := c.imports[spec]
+			if obj == nil || obj.used || obj.name == "_" || obj.name == "." {
+				continue
+			}
+			if spec.Name != nil {
+				c.errorf(obj.pos, "%q imported as %s and not used", obj.path, obj.name)
+			} else {
+				c.errorf(obj.pos, "%q imported and not used", obj.path)
+			}
+		}
+	}
+}
+
+// collectObjects creates the objects of the imports and of the package level declarations
+func (c *Checker) collectObjects() {
+	names := make([]string, 0, len(c.files))
+	for name := range c.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := c.files[name]
+		c.filename = name
+		if f.Name != nil {
+			c.recordDef(f.Name, nil)
+		}
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value.Lit)
+			if err != nil {
+				continue
+			}
+			pkgName := path[strings.LastIndex(path, "/")+1:]
+			pos := spec.Path.Pos()
+			if spec.Name != nil {
+				pkgName, pos = spec.Name.Name, spec.Name.Pos()
+			}
+			obj := &PkgName{object: object{name: pkgName, typ: Typ[Invalid], pos: pos}, path: path}
+			c.imports[spec] = obj
+			if spec.Name != nil {
+				c.recordDef(spec.Name, obj)
+			}
+		}
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FunctionDeclaration:
+				obj := &Func{object{name: d.Name.Name, pos: d.Name.Pos()}}
+				c.declarePackageObj(d.Name, obj, &declInfo{file: name, fdecl: d})
+			case *ast.GenericDeclaration:
+				var last *ast.ValueSpec // the last constant spec with a type or values
+				for iota, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.ValueSpec:
+						if d.Token == tokens.CONST {
+							last = c.constSpec(s, last)
+						}
+						var lhs []*Var
+						if d.Token == tokens.VAR && len(s.Values) > 0 && len(s.Values) != len(s.Names) {
+							lhs = make([]*Var, len(s.Names))
+							for i, ident := range s.Names {
+								lhs[i] = NewVar(ident.Pos(), ident.Name, nil)
+							}
+						}
+						for i, ident := range s.Names {
+							var obj Object
+							switch {
+							case d.Token == tokens.CONST:
+								obj = NewConst(ident.Pos(), ident.Name, nil, nil)
+							case lhs != nil:
+								obj = lhs[i]
+							default:
+								obj = NewVar(ident.Pos(), ident.Name, nil)
+							}
+							c.declarePackageObj(ident, obj, &declInfo{file: name, spec: s, init: last, iota: iota, lhs: lhs})
+						}
+					case *ast.TypeSpec:
+						obj := NewTypeName(s.Name.Pos(), s.Name.Name, nil)
+						c.declarePackageObj(s.Name, obj, &declInfo{file: name, typeSpec: s})
+					}
+				}
+			}
+		}
+	}
+}
+
+// constSpec returns the spec giving the type and values of the constants of the spec s, s itself
+// or, if s has neither, last, the previous one that has, and reports the constants without a
+// value and the extra values
+func (c *Checker) constSpec(s, last *ast.ValueSpec) *ast.ValueSpec {
+	if s.Type != nil || s.Values != nil || last == nil {
+		last = s
+	}
+	switch l, r := len(s.Names), len(last.Values); {
+	case l < r:
+		if last == s {
+			c.errorf(s.Values[l].Pos(), "extra init expr")
+		} else {
+			c.errorf(s.Pos(), "extra init expr at %s", last.Values[l].Pos().ToString())
+		}
+	case l > r:
+		c.errorf(s.Names[r].Pos(), "missing init expr for %s", s.Names[r].Name)
+	}
+	return last
+}
+
+// declarePackageObj declares the package level object obj of ident, to be checked with d
+func (c *Checker) declarePackageObj(ident *ast.Ident, obj Object, d *declInfo) {
+	c.declare(ident, obj)
+	c.decls[obj] = d
+	c.objList = append(c.objList, obj)
+	if ident.Name != "_" && ident.Name != "init" && c.pkg.objects[ident.Name] == nil {
+		c.pkg.objects[ident.Name] = obj
+	}
+}
+
+// declare links ident, a declaring identifier, to obj
+func (c *Checker) declare(ident *ast.Ident, obj Object) {
+	if ident.Obj != nil {
+		c.objMap[ident.Obj] = obj
+	}
+	c.recordDef(ident, obj)
+}
+
+// declareVar declares the local variable v of ident, which must be used in the function body
+func (c *Checker) declareVar(ident *ast.Ident, v *Var) {
+	c.declare(ident, v)
+	if ident.Name != "_" {
+		c.locals = append(c.locals, v)
+	}
+}
+
+// lookup returns the object denoted by ident, checking its declaration first if it is a package
+// level object, or nil if ident does not denote an object known to the checker
+func (c *Checker) lookup(ident *ast.Ident) Object {
+	o := ident.Obj
+	if o == nil {
+		return nil
+	}
+	if o.Decl == nil {
+		return universe[o.Name]
+	}
+	if spec, isImport := o.Decl.(*ast.ImportSpec); isImport {
+		return c.imports[spec]
+	}
+	obj := c.objMap[o]
+	if obj != nil {
+		c.objDecl(obj)
+	}
+	return obj
+}
+
+func (c *Checker) recordDef(ident *ast.Ident, obj Object) {
+	if c.info.Defs != nil {
+		c.info.Defs[ident] = obj
+	}
+}
+
+func (c *Checker) recordUse(ident *ast.Ident, obj Object) {
+	if c.info.Uses != nil {
+		c.info.Uses[ident] = obj
+	}
+}
+
+func (c *Checker) recordTypeAndValue(x ast.Expression, mode operandMode, typ Type, val constant.Value) {
+	if mode == invalid || c.info.Types == nil {
+		return
+	}
+	if mode != constant_ {
+		val = nil
+	}
+	c.info.Types[x] = TypeAndValue{mode: mode, Type: typ, Value: val}
+}
+
+// rememberUntyped delays the recording of the untyped expression x until its final type is known
+func (c *Checker) rememberUntyped(x ast.Expression, isLhs bool, mode operandMode, typ *Basic, val constant.Value) {
+	c.untyped[x] = exprInfo{isLhs: isLhs, mode: mode, typ: typ, val: val}
+}
+
+// recordUntyped records the expressions that remained untyped with their untyped types
+func (c *Checker) recordUntyped() {
+	for x, info := range c.untyped {
+		c.recordTypeAndValue(x, info.mode, info.typ, info.val)
+	}
+}
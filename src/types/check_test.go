@@ -0,0 +1,355 @@
+package types_test
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/parser"
+	"gocompiler/src/printer"
+	"gocompiler/src/tokens"
+	"gocompiler/src/types"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, src string) *ast.File {
+	f, err := parser.ParseFile(tokens.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func checkErrors(t *testing.T, err error, expected []string) {
+	t.Helper()
+	var got []string
+	if list, isList := err.(parser.ErrorList); isList {
+		for _, e := range list {
+			got = append(got, e.Error())
+		}
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected errors\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, test := range []struct {
+		src    string
+		errors []string
+	}{
+		{`var x int = "hello"`, []string{`p.go:1:13: cannot use "hello" (untyped string constant) as int value in variable declaration`}},
+		{"var x, y = 1, 2.5\nvar z int = x + y", []string{"p.go:2:15: invalid operation: x + y (mismatched types int and float64)"}},
+		{"type T int\nvar t T = 1\nvar i int = t", []string{"p.go:3:13: cannot use t (variable of type T) as int value in variable declaration"}},
+		{"var s []int = nil\nvar p *int = nil\nvar f func() = nil\nvar g = f == nil", nil},
+		{"var x = nil", []string{"p.go:1:9: use of untyped nil in variable declaration"}},
+		{`var b = "a" == 1`, []string{`p.go:1:13: invalid operation: "a" == 1 (mismatched types untyped string and untyped int)`}},
+		{"var a = []int{1, 2}\nvar b = a == a", []string{"p.go:2:9: invalid operation: a == a (slice can only be compared to nil)"}},
+		{"var p *int\nvar q = *p + 1\nvar r = *q", []string{"p.go:3:10: invalid operation: cannot indirect q (variable of type int)"}},
+		{"var x = x", []string{"p.go:1:5: initialization cycle: x refers to itself"}},
+		{"type T struct{ x T }", []string{"p.go:1:6: invalid recursive type T"}},
+		{"type T struct{ a, b int }\nvar t = T{a: 1, c: 2}", []string{"p.go:2:17: unknown field c in struct literal of type T"}},
+		{"type P struct{ x, y int }\ntype Q struct{ P; z int }\nfunc f(q *Q) int { return q.x + q.P.y + q.z }", nil},
+		{"type T struct{ x int }\nfunc f(t T) { t.y = 1 }", []string{"p.go:2:17: t.y undefined (type T has no field or method y)"}},
+		{"var ps = []struct{ x int }{{1}, {x: 2}}\nvar pp = [][]int{{1}, {2, 3}}", nil},
+		{"func f() { var a [2]int; _ = a[5] }", []string{"p.go:1:32: invalid argument: index 5 out of bounds [0:2]"}},
+		{"func f() { s := \"abc\"; var b byte = s[0]; s[0] = b }", []string{"p.go:1:43: cannot assign to s[0] (neither addressable nor a map index expression)"}},
+		{"func f(a int, b ...string) {}\nfunc g() { f(1, \"a\", 2); f(1, []string{}...) }", []string{"p.go:2:22: cannot use 2 (untyped int constant) as string value in argument to f"}},
+		{"func f() { f(1) }", []string{"p.go:1:14: too many arguments in call to f\n\thave (number)\n\twant ()"}},
+		{"func f() int { return \"s\" }", []string{`p.go:1:23: cannot use "s" (untyped string constant) as int value in return statement`}},
+		{"func f() (int, string) { return 1 }", []string{"p.go:1:33: not enough return values\n\thave (number)\n\twant (int, string)"}},
+		{"func f() (r int) { r = 1; return }", nil},
+		{"func f() { g := func(x int) int { return x * 2 }; var s string = g(1); _ = s }", []string{"p.go:1:66: cannot use g(1) (value of type int) as string value in variable declaration"}},
+		{"func f() { var b bool; if 1 {}; for b {} }", []string{"p.go:1:27: non-boolean condition in if statement"}},
+		{"func f() { for i, c := range \"abc\" { var s string = c; _, _ = i, s } }", []string{"p.go:1:53: cannot use c (variable of type rune) as string value in variable declaration"}},
+		{"func f(x int) { switch { case x: } }", []string{"p.go:1:31: invalid case x in switch (mismatched types int and bool)"}},
+		{"func f(x int) { switch x { case 1, 2: default: default: } }", []string{"p.go:1:48: multiple defaults (first at 1:39)"}},
+		{"func f() { 1 + 2; len }", []string{"p.go:1:12: 1 + 2 (untyped int constant 3) is not used", "p.go:1:19: len (built-in) must be called"}},
+		{"func f() { var s string; s++ }", []string{"p.go:1:16: declared and not used: s", "p.go:1:26: invalid operation: s++ (non-numeric type string)"}},
+		{"func f() { var s []int; s = append(s, 1, 2); s = append(s, s...); clear(s); println(len(s), cap(s)) }", nil},
+		{"var x = make([]int, 2)\nvar y = make(int)", []string{"p.go:2:14: invalid argument: cannot make int; type must be slice, map, or channel"}},
+		{"var f = 1.5\nvar i = int(f)\nvar s = string(rune(i))", nil},
+		{"const big = 1 << 100\nconst small = big >> 98\nvar a [small]int\nvar b [len(a) * 2]int", nil},
+		{"var x int8 = 300", []string{"p.go:1:14: cannot use 300 (untyped int constant) as int8 value in variable declaration (overflows)"}},
+		{"var i int = 1.5", []string{"p.go:1:13: cannot use 1.5 (untyped float constant) as int value in variable declaration (truncated)"}},
+		{"var x = int8(300)\nvar y = uint(-1)", []string{"p.go:1:14: constant 300 overflows int8", "p.go:2:14: constant -1 overflows uint"}},
+		{"const c int8 = 100\nvar x = c * 2", []string{"p.go:2:9: c * 2 (constant 200 of type int8) overflows int8"}},
+		{"const c = 1 << 600", []string{"p.go:1:13: constant shift overflow"}},
+		{"var x = 1 / 0\nvar y = 1.5 % 2", []string{"p.go:1:13: invalid operation: division by zero", "p.go:2:9: invalid operation: operator % not defined on 1.5 (untyped float constant)"}},
+		{"var x = 1.5 << 2\nvar y = 1 << -1", []string{"p.go:1:9: invalid operation: shifted operand 1.5 (untyped float constant) must be integer", "p.go:2:14: invalid operation: negative shift count -1 (untyped int constant)"}},
+		{"var n = 2\nvar a [n]int\nvar b [1.5]int\nvar c [-1]int", []string{"p.go:2:8: array length n (variable of type int) must be constant", "p.go:3:8: array length 1.5 (untyped float constant) must be integer", "p.go:4:8: invalid array length -1 (untyped int constant)"}},
+		{"func f(x int) { switch x { case 1, 2: case 3, 1: } }", []string{"p.go:1:47: duplicate case 1 (constant of type int) in expression switch\n\tprevious case at 1:33"}},
+		{"var s = make([]int, 3, 2)", []string{"p.go:1:21: invalid argument: length and capacity swapped"}},
+		{"var x = iota\nfunc f() { _ = iota }", []string{"p.go:1:9: cannot use iota outside constant declaration", "p.go:2:16: cannot use iota outside constant declaration"}},
+		{"const (\n\ta int8 = 100 * iota\n\tb\n\tc\n)", []string{"p.go:4:2: cannot use 100 * iota (untyped int constant 200) as int8 value in constant declaration (overflows)"}},
+		{"const (\n\ta, b = iota, 1\n\tc, d\n\te, f, g\n)", []string{"p.go:4:8: missing init expr for g"}},
+		{"const (\n\ta = 1, 2\n\tb\n)\nconst c int", []string{"p.go:2:9: extra init expr", "p.go:3:2: extra init expr at 2:9", "p.go:5:7: missing init expr for c"}},
+		{"const (\n\ta\n)", []string{"p.go:2:2: missing init expr for a"}},
+		{"func f() { x, y := 1, 2; var z int; for i := range \"ab\" {}; z = y; g := func() { _ = x } ; _ = g }", []string{"p.go:1:30: declared and not used: z", "p.go:1:41: declared and not used: i"}},
+		{"func f() { x := 1; x++; y := 2; y = 3; g := func() { z := 1 }; g() }", []string{"p.go:1:12: declared and not used: x", "p.go:1:25: declared and not used: y", "p.go:1:54: declared and not used: z"}},
+		{"import \"strings\"\nimport f \"fmt\"\nimport _ \"os\"\nimport \"io\"\nvar r = io.EOF", []string{`p.go:1:8: "strings" imported and not used`, `p.go:2:8: "fmt" imported as f and not used`}},
+		{"func f() (int, int) { return 1, 2 }\nfunc g() { a, b, c := f(); var d, e = 1; var h int = 1, 2; _, _, _, _ = a, b, c, d }", []string{"p.go:2:23: assignment mismatch: 3 variables but f returns 2 values", "p.go:2:39: assignment mismatch: 2 variables but 1 value", "p.go:2:54: assignment mismatch: 1 variable but 2 values"}},
+		{"func f() { var a, b int; a, b = 1; a = f(); _, _ = a, b }", []string{"p.go:1:33: assignment mismatch: 2 variables but 1 value", "p.go:1:40: f() (no value) used as value"}},
+		{"var a, b = 1\nvar c = 1, 2", []string{"p.go:1:12: assignment mismatch: 2 variables but 1 value", "p.go:2:9: assignment mismatch: 1 variable but 2 values"}},
+		{"func f(x int) int {\n\tif x > 0 {\n\t\treturn 1\n\t}\n}\nvar g = func() (r int) { for x := range 3 { return x } }", []string{"p.go:5:1: missing return", "p.go:6:56: missing return"}},
+		{"func f(x int) int { if x > 0 { return 1 } else { panic(x) } }\nfunc g() int { for {} }\nfunc h(x int) int { switch x { case 1: return 1; default: return 0 } }\nfunc k() int { L: for { break L } }", []string{"p.go:4:35: missing return"}},
+		{"func f(x int) int { switch x { case 1: fallthrough; default: return 0 } }\nfunc g(x int) int { L: goto L }\nfunc h(x int) int { switch { case x > 0: break; default: }; return 1 }", nil},
+		{"func f() { break; for { func() { continue }() }; switch { case true: fallthrough } }", []string{"p.go:1:12: break is not in a loop, switch, or select", "p.go:1:34: continue is not in a loop", "p.go:1:70: cannot fallthrough final case in switch"}},
+		{"func f() { L: for {}; M: for { break L; continue N }; goto O; { P: } ; goto P }", []string{"p.go:1:12: label L declared and not used", "p.go:1:23: label M declared and not used", "p.go:1:38: invalid break label L", "p.go:1:50: invalid continue label N", "p.go:1:60: label O not declared", "p.go:1:77: goto P jumps into block"}},
+		{"func f() {\n\tgoto L\n\tx := 1\n\t_ = x\nL:\n\tfor {\n\t\tif x > 0 {\n\t\t\tgoto M\n\t\t}\n\t\tvar y int\n\t\t_ = y\n\tM:\n\t}\nN:\n\tz := 1\n\t_ = z\n\tgoto N\n}", []string{"p.go:2:7: goto L jumps over variable declaration at line 3", "p.go:8:9: goto M jumps over variable declaration at line 10"}},
+		{"type Number interface{ ~int | ~float64 }\nfunc Sum[T Number](s []T) T { var r T; for _, x := range s { r += x }; return r }\nvar a = Sum([]int{1})\nvar b = Sum([]string{\"a\"})", []string{"p.go:4:13: string does not satisfy Number (string missing in ~int | ~float64)"}},
+		{"type MyInt int\nfunc F[T int | float64](x T) {}\nfunc g() { F(MyInt(1)) }", []string{"p.go:3:14: MyInt does not satisfy int | float64 (possibly missing ~ for int in int | float64)"}},
+		{"func F[T any]() T { var x T; return x }\nvar x = F()", []string{"p.go:2:11: in call to F, cannot infer T"}},
+		{"type List[T any] struct{ next *List[T]; val T }\nvar l List\nvar m List[int, string]", []string{"p.go:2:7: cannot use generic type List[T any] without instantiation", "p.go:3:17: too many type arguments for type List: have 2, want 1"}},
+		{"type S struct{}\ntype C interface{ ~S | int }\ntype I interface{ ~error }", []string{"p.go:2:19: invalid use of ~ (underlying type of S is struct{})", "p.go:3:19: invalid use of ~ (error is an interface)"}},
+		{"func F[T any](x, y T) {}\nfunc g() { F(1, \"a\"); F(1, 2.5) }", []string{"p.go:2:17: mismatched types untyped int and untyped string (cannot infer T)"}},
+		{"type Number interface{ int | float64 }\nvar n Number", []string{"p.go:2:7: cannot use type Number outside a type constraint: interface contains type constraints"}},
+		{"func Index[E comparable](s []E, x E) int { return -1 }\nvar i = Index([]string{\"a\"}, \"a\")\nvar f = Index[[]int]\nvar j = Index([]int{1}, \"a\")", []string{"p.go:3:15: []int does not satisfy comparable", `p.go:4:25: cannot use "a" (untyped string constant) as int value in argument to Index`}},
+		{"func F[T interface{ int | string }](x T) T { return x + x }\nfunc G[T any](x T) T { return x + x }", []string{"p.go:2:31: invalid operation: operator + not defined on x (variable of type T constrained by any)"}},
+		{"func F[T any](s []T, p *T) {}\nfunc g() { F([]int{}, new(string)); F(1, nil) }", []string{"p.go:2:23: type *string of new(string) does not match inferred type *int for *T", "p.go:2:45: in call to F, cannot infer T"}},
+	} {
+		f := parse(t, test.src)
+		_, err := types.CheckFile("p.go", f, nil)
+		checkErrors(t, err, test.errors)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	f := parse(t, `package p
+
+type T struct{ x int }
+
+func f(t T) float64 {
+	var y = t.x + 1
+	return float64(y) / 2
+}
+`)
+	info := &types.Info{
+		Types: map[ast.Expression]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	pkg, err := types.CheckFile("p.go", f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name() != "p" || pkg.Lookup("T") == nil || pkg.Lookup("f") == nil {
+		t.Errorf("unexpected package %s", pkg.Name())
+	}
+
+	var got []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		if e, isExpr := n.(ast.Expression); isExpr {
+			if tv, found := info.Types[e]; found {
+				got = append(got, e.Pos().ToString()+" "+source(t, e)+": "+tv.Type.String())
+			}
+		}
+		return true
+	})
+	expected := []string{
+		"3:8 struct{ x int }: struct{x int}",
+		"3:18 int: int",
+		"5:10 T: T",
+		"5:13 float64: float64",
+		"6:10 t.x + 1: int",
+		"6:10 t.x: int",
+		"6:10 t: T",
+		"6:16 1: int",
+		"7:9 float64(y) / 2: float64",
+		"7:9 float64(y): float64",
+		"7:9 float64: float64",
+		"7:17 y: int",
+		"7:22 2: float64",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected types\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	for ident, obj := range info.Uses {
+		if !obj.Pos().IsValid() {
+			continue // predeclared
+		}
+		if def := info.Defs[findIdent(f, obj.Name())]; def != obj {
+			t.Errorf("%s at %s refers to %v, declared as %v", ident.Name, ident.Pos().ToString(), obj, def)
+		}
+	}
+}
+
+func source(t *testing.T, e ast.Expression) string {
+	b, err := printer.Source(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// findIdent returns the first identifier of f with the given name
+func findIdent(f *ast.File, name string) (ident *ast.Ident) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, isIdent := n.(*ast.Ident); isIdent && id.Name == name && ident == nil {
+			ident = id
+		}
+		return ident == nil
+	})
+	return
+}
+
+func TestConstants(t *testing.T) {
+	f := parse(t, `package p
+
+const (
+	big   = 1 << 100
+	small = big >> 98
+	f     = small / 8.0
+	s     = "go" + "pher"
+	n     = len(s)
+	b     = n > 5 && !false
+	z     = complex(1, 2) * complex(0, 1)
+	r     = imag(z)
+	m     = min(n, 8.0)
+)
+
+var a [small * 2]int
+const l = len(a)
+`)
+	pkg, err := types.CheckFile("p.go", f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ name, obj string }{
+		{"big", "const big untyped int = 1267650600228229401496703205376"},
+		{"small", "const small untyped int = 4"},
+		{"f", "const f untyped float = 0.5"},
+		{"s", `const s untyped string = "gopher"`},
+		{"n", "const n int = 6"},
+		{"b", "const b untyped bool = true"},
+		{"z", "const z untyped complex = (-2 + 1i)"},
+		{"r", "const r untyped float = 1"},
+		{"m", "const m int = 6"},
+		{"l", "const l int = 8"},
+	} {
+		if obj := pkg.Lookup(test.name); obj == nil || obj.String() != test.obj {
+			t.Errorf("expected %s, got %v", test.obj, obj)
+		}
+	}
+}
+
+func TestIota(t *testing.T) {
+	f := parse(t, `package p
+
+type Kind int
+
+const (
+	A Kind = iota
+	B
+	C
+)
+
+const (
+	_  = iota
+	KB = 1 << (10 * iota)
+	MB
+)
+
+func f() {
+	const (
+		x, y = iota, -iota
+		z, w
+	)
+	var k Kind = C
+	_, _, _ = k, z, w
+}
+`)
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	pkg, err := types.CheckFile("p.go", f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ name, obj string }{
+		{"A", "const A Kind = 0"},
+		{"B", "const B Kind = 1"},
+		{"C", "const C Kind = 2"},
+		{"KB", "const KB untyped int = 1024"},
+		{"MB", "const MB untyped int = 1048576"},
+	} {
+		if obj := pkg.Lookup(test.name); obj == nil || obj.String() != test.obj {
+			t.Errorf("expected %s, got %v", test.obj, obj)
+		}
+	}
+	for _, test := range []struct{ name, obj string }{
+		{"z", "const z untyped int = 1"},
+		{"w", "const w untyped int = -1"},
+	} {
+		if obj := info.Defs[findIdent(f, test.name)]; obj == nil || obj.String() != test.obj {
+			t.Errorf("expected %s, got %v", test.obj, obj)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	f := parse(t, `package p
+
+type Number interface {
+	~int | ~float64
+}
+
+type List[T any] struct {
+	next *List[T]
+	val  T
+}
+
+func Sum[T Number](s []T) T {
+	var r T
+	for _, x := range s {
+		r += x
+	}
+	return r
+}
+
+func Map[S, D any](s []S, f func(S) D) []D {
+	r := make([]D, 0, len(s))
+	for _, x := range s {
+		r = append(r, f(x))
+	}
+	return r
+}
+
+type Celsius float64
+
+var l List[int]
+var a = Sum([]Celsius{1.5})
+var b = Map([]int{1}, func(i int) string { return "" })
+var c = Sum[int]
+var d = l.next.val
+`)
+	info := &types.Info{Types: map[ast.Expression]types.TypeAndValue{}}
+	_, err := types.CheckFile("p.go", f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, decl := range f.Decls {
+		gen, isGen := decl.(*ast.GenericDeclaration)
+		if !isGen || gen.Token != tokens.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			var e ast.Expression = vs.Type
+			if len(vs.Values) > 0 {
+				e = vs.Values[0]
+			}
+			got = append(got, source(t, e)+": "+info.Types[e].Type.String())
+		}
+	}
+	expected := []string{
+		"List[int]: List[int]",
+		"Sum([]Celsius{1.5}): Celsius",
+		`Map([]int{1}, func(i int) string { return "" }): []string`,
+		"Sum[int]: func([]int) int",
+		"l.next.val: int",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected types\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+	"math"
+)
+
+// reprError tells why a constant cannot be represented by a value of a basic type
+type reprError int
+
+const (
+	reprOk        reprError = iota
+	reprTruncated           // a float constant that is not an integer, for an integer type
+	reprOverflows           // a numeric constant out of the range of the type
+	reprInvalid             // a constant of another kind
+)
+
+// sizeof returns the size in bytes of the values of the basic type t; int, uint and uintptr
+// have 64 bits
+func sizeof(t *Basic) int64 {
+	switch t.kind {
+	case Int8, Uint8:
+		return 1
+	case Int16, Uint16:
+		return 2
+	case Int32, Uint32, Float32:
+		return 4
+	case Complex128:
+		return 16
+	}
+	return 8
+}
+
+// representableConst reports whether the constant x can be represented by a value of the
+// basic type typ; if rounded is not nil, it is set to the value of x in typ, rounded for
+// floats. An unknown value is representable by any type, to avoid follow-up errors
+func representableConst(x constant.Value, typ *Basic, rounded *constant.Value) bool {
+	if x.Kind() == constant.Unknown {
+		return true
+	}
+
+	switch {
+	case isInteger(typ):
+		x := constant.ToInt(x)
+		if x.Kind() != constant.Int {
+			return false
+		}
+		if rounded != nil {
+			*rounded = x
+		}
+		if x, ok := constant.Int64Val(x); ok {
+			switch typ.kind {
+			case Int, Int64:
+				return true
+			case Int8, Int16, Int32:
+				s := uint(sizeof(typ)) * 8
+				return -1<<(s-1) <= x && x <= 1<<(s-1)-1
+			case Uint8, Uint16, Uint32:
+				s := uint(sizeof(typ)) * 8
+				return 0 <= x && x <= 1<<s-1
+			case Uint, Uint64, Uintptr:
+				return 0 <= x
+			case UntypedInt:
+				return true
+			}
+		}
+		// x does not fit into an int64
+		switch n := constant.BitLen(x); typ.kind {
+		case Uint, Uint64, Uintptr:
+			return constant.Sign(x) >= 0 && n <= 64
+		case UntypedInt:
+			return true
+		}
+
+	case isFloat(typ):
+		x := constant.ToFloat(x)
+		if x.Kind() != constant.Float {
+			return false
+		}
+		switch typ.kind {
+		case Float32:
+			if r := roundFloat32(x); r != nil {
+				if rounded != nil {
+					*rounded = r
+				}
+				return true
+			}
+		case Float64:
+			if r := roundFloat64(x); r != nil {
+				if rounded != nil {
+					*rounded = r
+				}
+				return true
+			}
+		case UntypedFloat:
+			return true
+		}
+
+	case isComplex(typ):
+		x := constant.ToComplex(x)
+		if x.Kind() != constant.Complex {
+			return false
+		}
+		round := roundFloat64
+		if typ.kind == Complex64 {
+			round = roundFloat32
+		} else if typ.kind == UntypedComplex {
+			return true
+		}
+		re, im := round(constant.Real(x)), round(constant.Imag(x))
+		if re != nil && im != nil {
+			if rounded != nil {
+				*rounded = constant.BinaryOp(re, tokens.ADD, constant.MakeImag(im))
+			}
+			return true
+		}
+
+	case isString(typ):
+		return x.Kind() == constant.String
+
+	case isBoolean(typ):
+		return x.Kind() == constant.Bool
+	}
+	return false
+}
+
+// roundFloat32 returns x rounded to a float32, or nil if it overflows
+func roundFloat32(x constant.Value) constant.Value {
+	f32, _ := constant.Float32Val(x)
+	if f := float64(f32); !math.IsInf(f, 0) {
+		return constant.MakeFloat64(f)
+	}
+	return nil
+}
+
+// roundFloat64 returns x rounded to a float64, or nil if it overflows
+func roundFloat64(x constant.Value) constant.Value {
+	if f, _ := constant.Float64Val(x); !math.IsInf(f, 0) {
+		return constant.MakeFloat64(f)
+	}
+	return nil
+}
+
+// representation returns the value of the constant x as a value of the basic type typ, or
+// why it cannot be represented
+func representation(x *operand, typ *Basic) (constant.Value, reprError) {
+	v := x.val
+	if !representableConst(x.val, typ, &v) {
+		if isNumeric(x.typ) && isNumeric(typ) {
+			if !isInteger(x.typ) && isInteger(typ) {
+				return nil, reprTruncated
+			}
+			return nil, reprOverflows
+		}
+		return nil, reprInvalid
+	}
+	return v, reprOk
+}
+
+// representable checks that the constant x can be represented by a value of the basic type
+// typ and sets its value to the representation; x is invalid after an error
+func (c *Checker) representable(x *operand, typ *Basic) {
+	v, err := representation(x, typ)
+	if err != reprOk {
+		c.invalidConversion(err, x, typ)
+		x.mode = invalid
+		return
+	}
+	x.val = v
+}
+
+// invalidConversion reports that x cannot be converted to the type target for the reason err
+func (c *Checker) invalidConversion(err reprError, x *operand, target Type) {
+	switch err {
+	case reprTruncated:
+		c.errorf(x.expr.Pos(), "%s truncated to %s", x, target)
+	case reprOverflows:
+		c.errorf(x.expr.Pos(), "%s overflows %s", x, target)
+	default:
+		c.errorf(x.expr.Pos(), "cannot convert %s to type %s", x, target)
+	}
+}
+
+// overflow checks that the constant x, the result of an operation at opPos, is representable
+// by its type, or is an untyped integer of reasonable size
+func (c *Checker) overflow(x *operand, opPos tokens.Position) {
+	if x.val.Kind() == constant.Unknown {
+		c.errorf(opPos, "constant result is not representable")
+		return
+	}
+	if isTyped(x.typ) {
+		c.representable(x, under(x.typ).(*Basic))
+		return
+	}
+	// the untyped integers must not grow without bounds
+	const prec = 512
+	if x.val.Kind() == constant.Int && constant.BitLen(x.val) > prec {
+		op := opName(x.expr)
+		if op != "" {
+			op += " "
+		}
+		c.errorf(opPos, "constant %soverflow", op)
+		x.val = constant.MakeUnknown()
+	}
+}
+
+var op2str1 = map[tokens.TokenType]string{
+	tokens.XOR: "bitwise complement",
+}
+
+var op2str2 = map[tokens.TokenType]string{
+	tokens.ADD: "addition",
+	tokens.SUB: "subtraction",
+	tokens.XOR: "bitwise XOR",
+	tokens.MUL: "multiplication",
+	tokens.SHL: "shift",
+}
+
+// opName returns the name of the operation of e for the overflow errors, or ""
+func opName(e ast.Expression) string {
+	switch e := e.(type) {
+	case *ast.BinaryExpression:
+		return op2str2[e.Operator]
+	case *ast.UnaryExpression:
+		return op2str1[e.Operator]
+	}
+	return ""
+}
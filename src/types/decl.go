@@ -0,0 +1,334 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+)
+
+// objDecl checks the declaration of the package level object obj unless it is checked already.
+// An object met again while its own declaration is checked is part of a cycle, which is an error
+// unless a type name refers to itself through a type that does not contain it
+func (c *Checker) objDecl(obj Object) {
+	d := c.decls[obj]
+	if d == nil || d.state == checked {
+		return
+	}
+	if d.state == inProgress {
+		switch obj := obj.(type) {
+		case *TypeName:
+			if obj.typ == nil {
+				c.cycleError(obj)
+				obj.typ = Typ[Invalid]
+			}
+		case *Const:
+			c.cycleError(obj)
+			obj.typ = Typ[Invalid]
+		case *Var:
+			c.cycleError(obj)
+			if obj.typ == nil {
+				obj.typ = Typ[Invalid]
+			}
+		}
+		return
+	}
+
+	d.state = inProgress
+	c.path = append(c.path, obj)
+	filename, sig, iota, errpos := c.filename, c.sig, c.iota, c.errpos
+	c.filename, c.sig, c.iota, c.errpos = d.file, nil, nil, tokens.Position{}
+	defer func() {
+		c.filename, c.sig, c.iota, c.errpos = filename, sig, iota, errpos
+		c.path = c.path[:len(c.path)-1]
+		d.state = checked
+	}()
+
+	switch obj := obj.(type) {
+	case *Const:
+		i := identIndex(d.spec.Names, obj.name, obj.pos)
+		c.constDecl(obj, d.init.Type, valueAt(d.init.Values, i), d.iota, d.init != d.spec)
+	case *Var:
+		if d.lhs != nil {
+			for _, v := range d.lhs {
+				c.decls[v].state = inProgress
+			}
+			c.varDecls(d.lhs, d.spec.Type, d.spec.Values)
+			for _, v := range d.lhs {
+				c.decls[v].state = checked
+			}
+			return
+		}
+		i := identIndex(d.spec.Names, obj.name, obj.pos)
+		c.varDecl(obj, d.spec.Type, valueAt(d.spec.Values, i))
+	case *TypeName:
+		c.typeDecl(obj, d.typeSpec)
+	case *Func:
+		c.funcDecl(obj, d.fdecl)
+	}
+}
+
+// identIndex returns the index of the identifier with the given name and position in list
+func identIndex(list []*ast.Ident, name string, pos tokens.Position) int {
+	for i, ident := range list {
+		if ident.Name == name && ident.Pos() == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+// valueAt returns the i'th expression of values, or nil if there is none
+func valueAt(values []ast.Expression, i int) ast.Expression {
+	if i >= 0 && i < len(values) {
+		return values[i]
+	}
+	return nil
+}
+
+// cycleError reports the cycle of declarations from obj back to obj in the current path
+func (c *Checker) cycleError(obj Object) {
+	cycle := []Object{obj}
+	for i, o := range c.path {
+		if o == obj {
+			cycle = c.path[i:]
+			break
+		}
+	}
+	var msg string
+	if _, isType := obj.(*TypeName); isType {
+		msg = "invalid recursive type " + obj.Name()
+	} else if len(cycle) == 1 {
+		msg = "initialization cycle: " + obj.Name() + " refers to itself"
+	} else {
+		msg = "initialization cycle for " + obj.Name()
+	}
+	if len(cycle) > 1 {
+		for i, o := range cycle {
+			next := cycle[(i+1)%len(cycle)]
+			msg += "\n\t" + o.Name() + " refers to " + next.Name()
+		}
+	}
+	c.errors = append(c.errors, c.errorAt(c.declFile(cycle[0]), cycle[0].Pos(), msg))
+}
+
+// constDecl checks the declaration of the constant obj with the optional type typ and the
+// initialization expression init, declared in the spec iota of its declaration. The errors in
+// an expression inherited from a previous spec are reported at obj
+func (c *Checker) constDecl(obj *Const, typ, init ast.Expression, iota int, inherited bool) {
+	defer func(iota constant.Value, errpos tokens.Position) {
+		c.iota, c.errpos = iota, errpos
+	}(c.iota, c.errpos)
+	c.iota = constant.MakeInt64(int64(iota))
+	c.errpos = tokens.Position{}
+	if inherited {
+		c.errpos = obj.pos
+	}
+
+	obj.val = constant.MakeUnknown()
+	if typ != nil {
+		t := c.typ(typ)
+		if !isConstType(t) {
+			if isValid(t) {
+				c.errorf(typ.Pos(), "invalid constant type %s", t)
+			}
+			obj.typ = Typ[Invalid]
+			return
+		}
+		obj.typ = t
+	}
+	var x operand
+	if init != nil {
+		c.expr(&x, init)
+	}
+	c.initConst(obj, &x)
+}
+
+// varDecl checks the declaration of the variable obj with the optional type typ and the
+// optional initialization expression init
+func (c *Checker) varDecl(obj *Var, typ, init ast.Expression) {
+	if typ != nil {
+		obj.typ = c.varType(typ)
+	}
+	if init == nil {
+		if typ == nil {
+			obj.typ = Typ[Invalid]
+		}
+		return
+	}
+	var x operand
+	c.expr(&x, init)
+	c.initVar(obj, &x, "variable declaration")
+}
+
+// varDecls checks the declaration of the variables lhs, initialized together by the values
+func (c *Checker) varDecls(lhs []*Var, typ ast.Expression, values []ast.Expression) {
+	if typ != nil {
+		t := c.varType(typ)
+		for _, v := range lhs {
+			v.typ = t
+		}
+	}
+	c.initVars(lhs, values, "variable declaration")
+}
+
+// varType returns the type of a variable declared with the type expression e, which cannot be
+// a constraint interface
+func (c *Checker) varType(e ast.Expression) Type {
+	typ := c.typ(e)
+	if iface, isIface := under(typ).(*Interface); isIface && !isTypeParam(typ) {
+		s := iface.typeSet()
+		switch {
+		case !s.terms.isAll():
+			c.errorf(e.Pos(), "cannot use type %s outside a type constraint: interface contains type constraints", typ)
+			return Typ[Invalid]
+		case s.comparable:
+			c.errorf(e.Pos(), "cannot use type %s outside a type constraint: interface is (or embeds) comparable", typ)
+			return Typ[Invalid]
+		}
+	}
+	return typ
+}
+
+// typeDecl checks the declaration of the type name obj
+func (c *Checker) typeDecl(obj *TypeName, spec *ast.TypeSpec) {
+	if spec.AssignPos.IsValid() {
+		if spec.TypeParams != nil {
+			c.errorf(spec.TypeParams.Pos(), "generic type cannot be alias")
+		}
+		obj.typ = c.typ(spec.Type)
+		return
+	}
+	named := NewNamed(obj, nil)
+	if spec.TypeParams != nil {
+		named.tparams = c.collectTypeParams(spec.TypeParams)
+	}
+	rhs := c.typ(spec.Type)
+	if n, isNamed := rhs.(*Named); isNamed && n.Origin().underlying == nil {
+		// the type on the right is being declared and refers back to obj
+		c.cycleError(n.obj)
+		rhs = Typ[Invalid]
+	}
+	if isTypeParam(rhs) {
+		c.errorf(spec.Type.Pos(), "cannot use a type parameter as RHS in type declaration")
+		rhs = Typ[Invalid]
+	}
+	named.underlying = under(rhs)
+	c.validType(named)
+}
+
+// validType reports an invalid recursive type if the named type t contains itself, or an
+// instance of itself, through the element types of arrays and the field types of structs,
+// and makes t invalid then
+func (c *Checker) validType(t *Named) {
+	var path []*Named
+	var visit func(typ Type) bool
+	visit = func(typ Type) bool {
+		switch typ := typ.(type) {
+		case *Array:
+			return visit(typ.elem)
+		case *Struct:
+			for _, f := range typ.fields {
+				if !visit(f.typ) {
+					return false
+				}
+			}
+		case *Named:
+			if typ == t {
+				msg := "invalid recursive type " + t.obj.name
+				if len(path) > 0 {
+					cycle := append([]*Named{t}, path...)
+					for i, n := range cycle {
+						msg += "\n\t" + n.obj.name + " refers to " + cycle[(i+1)%len(cycle)].obj.name
+					}
+				}
+				c.errors = append(c.errors, c.errorAt(c.declFile(t.obj), t.obj.pos, msg))
+				return false
+			}
+			for _, n := range path {
+				if n == typ {
+					return true // a cycle not involving t, reported with its own declaration
+				}
+			}
+			path = append(path, typ)
+			defer func() { path = path[:len(path)-1] }()
+			return typ.underlying == nil || visit(typ.underlying)
+		}
+		return true
+	}
+	if !visit(t.underlying) {
+		t.underlying = Typ[Invalid]
+	}
+}
+
+// declFile returns the file declaring the package level object obj, or the file of the
+// checked declaration for a local object
+func (c *Checker) declFile(obj Object) string {
+	if d := c.decls[obj]; d != nil {
+		return d.file
+	}
+	return c.filename
+}
+
+// funcDecl checks the signature of the function obj and queues its body
+func (c *Checker) funcDecl(obj *Func, d *ast.FunctionDeclaration) {
+	sig := c.signature(d.Type)
+	obj.typ = sig
+	if name := d.Name.Name; name == "init" || name == "main" && c.pkg.name == "main" {
+		if sig.params.Len() > 0 || sig.results.Len() > 0 {
+			c.errorf(d.Name.Pos(), "func %s must have no arguments and no return values", name)
+		}
+	}
+	if d.Body == nil {
+		c.errorf(d.Name.Pos(), "missing function body")
+		return
+	}
+	c.funcs = append(c.funcs, funcInfo{file: c.filename, sig: sig, body: d.Body})
+}
+
+// declStmt checks a local declaration; the constants and variables are declared after
+// their specs are checked
+func (c *Checker) declStmt(d *ast.GenericDeclaration) {
+	var last *ast.ValueSpec // the last constant spec with a type or values
+	for iota, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.ValueSpec:
+			switch d.Token {
+			case tokens.CONST:
+				last = c.constSpec(s, last)
+				consts := make([]*Const, len(s.Names))
+				for i, ident := range s.Names {
+					consts[i] = NewConst(ident.Pos(), ident.Name, nil, nil)
+					c.constDecl(consts[i], last.Type, valueAt(last.Values, i), iota, last != s)
+				}
+				for i, ident := range s.Names {
+					c.declare(ident, consts[i])
+				}
+			case tokens.VAR:
+				vars := make([]*Var, len(s.Names))
+				for i, ident := range s.Names {
+					vars[i] = NewVar(ident.Pos(), ident.Name, nil)
+				}
+				if len(s.Values) > 0 && len(s.Values) != len(s.Names) {
+					c.varDecls(vars, s.Type, s.Values)
+				} else {
+					for i, v := range vars {
+						c.varDecl(v, s.Type, valueAt(s.Values, i))
+					}
+				}
+				for i, ident := range s.Names {
+					c.declareVar(ident, vars[i])
+				}
+			}
+		case *ast.TypeSpec:
+			obj := NewTypeName(s.Name.Pos(), s.Name.Name, nil)
+			c.declare(s.Name, obj)
+			c.typeDecl(obj, s)
+		}
+	}
+}
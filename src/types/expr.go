@@ -0,0 +1,1256 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+)
+
+// opPredicates maps the operators to the predicates their operand types must satisfy
+type opPredicates map[tokens.TokenType]func(Type) bool
+
+// The operators are defined on a type parameter if they are defined on all the types of its
+// type set
+var unaryOpPredicates = opPredicates{
+	tokens.ADD: allNumeric,
+	tokens.SUB: allNumeric,
+	tokens.XOR: allInteger,
+	tokens.NOT: allBoolean,
+}
+
+var binaryOpPredicates = opPredicates{
+	tokens.ADD:     allNumericOrString,
+	tokens.SUB:     allNumeric,
+	tokens.MUL:     allNumeric,
+	tokens.QUO:     allNumeric,
+	tokens.REM:     allInteger,
+	tokens.AND:     allInteger,
+	tokens.OR:      allInteger,
+	tokens.XOR:     allInteger,
+	tokens.AND_NOT: allInteger,
+	tokens.LAND:    allBoolean,
+	tokens.LOR:     allBoolean,
+}
+
+func isShift(op tokens.TokenType) bool {
+	return op == tokens.SHL || op == tokens.SHR
+}
+
+func isComparison(op tokens.TokenType) bool {
+	switch op {
+	case tokens.EQL, tokens.NEQ, tokens.LSS, tokens.LEQ, tokens.GTR, tokens.GEQ:
+		return true
+	}
+	return false
+}
+
+// op reports whether the operator op is defined on the type of x
+func (c *Checker) op(m opPredicates, x *operand, op tokens.TokenType) bool {
+	pred := m[op]
+	if pred == nil {
+		c.errorf(x.expr.Pos(), "unknown operator %s", op)
+		return false
+	}
+	if !pred(x.typ) {
+		c.errorf(x.expr.Pos(), "invalid operation: operator %s not defined on %s", op, x)
+		return false
+	}
+	return true
+}
+
+// expr checks the single-valued expression e and sets x to its value
+func (c *Checker) expr(x *operand, e ast.Expression) {
+	c.rawExpr(x, e, nil)
+	c.exclude(x, novalue, builtin, typexpr)
+	c.singleValue(x)
+	c.nonGeneric(x)
+}
+
+// exprWithHint is like expr, with hint as the type of an untyped composite literal
+func (c *Checker) exprWithHint(x *operand, e ast.Expression, hint Type) {
+	c.rawExpr(x, e, hint)
+	c.exclude(x, novalue, builtin, typexpr)
+	c.singleValue(x)
+	c.nonGeneric(x)
+}
+
+// exprOrType checks the expression or type e and sets x to its value or type
+func (c *Checker) exprOrType(x *operand, e ast.Expression) {
+	c.genericExprOrType(x, e)
+	c.nonGeneric(x)
+}
+
+// genericExprOrType is like exprOrType, but x may be a generic function or type that is not
+// instantiated
+func (c *Checker) genericExprOrType(x *operand, e ast.Expression) {
+	c.rawExpr(x, e, nil)
+	c.exclude(x, novalue)
+	c.singleValue(x)
+}
+
+// nonGeneric reports an error if x is a generic function or type that is not instantiated,
+// and makes x invalid then
+func (c *Checker) nonGeneric(x *operand) {
+	switch {
+	case x.mode == typexpr && isGeneric(x.typ):
+		named := x.typ.(*Named)
+		c.errorf(x.expr.Pos(), "cannot use generic type %s%s without instantiation", named.obj.name, tparamList(named.tparams))
+	case x.mode == value && isGenericFunc(x.typ):
+		c.errorf(x.expr.Pos(), "cannot use generic function %s without instantiation", x.expr)
+	default:
+		return
+	}
+	x.mode = invalid
+	x.typ = Typ[Invalid]
+}
+
+// isGenericFunc reports whether t is the signature of a generic function that is not instantiated
+func isGenericFunc(t Type) bool {
+	sig, isSig := t.(*Signature)
+	return isSig && sig.tparams != nil
+}
+
+// exclude reports an error if the mode of x is one of modes and makes x invalid then
+func (c *Checker) exclude(x *operand, modes ...operandMode) {
+	for _, mode := range modes {
+		if x.mode != mode {
+			continue
+		}
+		switch mode {
+		case novalue:
+			c.errorf(x.expr.Pos(), "%s used as value", x)
+		case builtin:
+			c.errorf(x.expr.Pos(), "%s must be called", x)
+		case typexpr:
+			c.errorf(x.expr.Pos(), "%s is not an expression", x)
+		}
+		x.mode = invalid
+	}
+}
+
+// singleValue reports an error if x is the tuple of a call returning several values
+func (c *Checker) singleValue(x *operand) {
+	if x.mode == value {
+		if t, isTuple := x.typ.(*Tuple); isTuple {
+			c.errorf(x.expr.Pos(), "multiple-value %s (value of type %s) in single-value context", x.expr, t)
+			x.mode = invalid
+		}
+	}
+}
+
+// use checks the expressions whose values are not needed after an error, so that
+// their identifiers are resolved and their variables used
+func (c *Checker) use(list ...ast.Expression) {
+	var x operand
+	for _, e := range list {
+		if e != nil {
+			c.rawExpr(&x, e, nil)
+		}
+	}
+}
+
+// rawExpr checks the expression e, with hint as the type of an untyped composite literal, sets x to
+// its value and records it. It returns whether e may be used as an expression statement
+func (c *Checker) rawExpr(x *operand, e ast.Expression, hint Type) exprKind {
+	kind := c.exprInternal(x, e, hint)
+	c.record(x)
+	return kind
+}
+
+// record records the type of x, or remembers it if it is untyped
+func (c *Checker) record(x *operand) {
+	var typ Type
+	switch x.mode {
+	case invalid:
+		typ = Typ[Invalid]
+	case novalue:
+		typ = (*Tuple)(nil)
+	default:
+		typ = x.typ
+	}
+	if b, isBasic := typ.(*Basic); isBasic && isUntyped(b) {
+		c.rememberUntyped(x.expr, false, x.mode, b, x.val)
+		return
+	}
+	c.recordTypeAndValue(x.expr, x.mode, typ, x.val)
+}
+
+func (c *Checker) exprInternal(x *operand, e ast.Expression, hint Type) exprKind {
+	x.mode = invalid
+	x.typ = Typ[Invalid]
+	kind := expression
+
+	switch e := e.(type) {
+	case *ast.BadExpression:
+
+	case *ast.Ident:
+		c.ident(x, e)
+
+	case *ast.Ellipsis:
+		c.errorf(e.Pos(), "invalid use of ...")
+
+	case *ast.BasicLiteral:
+		x.setConst(e)
+		if x.mode == invalid {
+			c.errorf(e.Pos(), "malformed constant: %s", e.Value.Lit)
+		}
+
+	case *ast.FunctionLiteral:
+		sig := c.typ(e.Type).(*Signature)
+		c.funcBody(sig, e.Body)
+		x.mode = value
+		x.typ = sig
+
+	case *ast.CompositeLiteral:
+		c.compositeLit(x, e, hint)
+
+	case *ast.ParenExpression:
+		kind = c.rawExpr(x, e.X, nil)
+
+	case *ast.SelectorExpression:
+		c.selector(x, e)
+
+	case *ast.IndexExpression, *ast.IndexExpressions:
+		if c.indexExpr(x, e) {
+			c.funcInst(x, e)
+		}
+
+	case *ast.CallExpression:
+		kind = c.callExpr(x, e)
+
+	case *ast.StarExpression:
+		c.exprOrType(x, e.X)
+		switch x.mode {
+		case invalid:
+		case typexpr:
+			x.typ = NewPointer(x.typ)
+		default:
+			if p, isPointer := coreType(x.typ).(*Pointer); isPointer {
+				x.mode = variable
+				x.typ = p.base
+			} else {
+				if x.isNil() {
+					c.errorf(x.expr.Pos(), "invalid operation: cannot indirect nil")
+				} else {
+					c.errorf(x.expr.Pos(), "invalid operation: cannot indirect %s", x)
+				}
+				x.mode = invalid
+			}
+		}
+
+	case *ast.UnaryExpression:
+		c.unary(x, e)
+
+	case *ast.BinaryExpression:
+		c.binary(x, e, e.LeftX, e.RightX, e.Operator, e.OpPos)
+
+	case *ast.KeyValueExpression:
+		c.errorf(e.Pos(), "unexpected key:value expression")
+		c.use(e.Key, e.Value)
+		x.mode = invalid
+
+	case *ast.ArrayType, *ast.StructType, *ast.FunctionType, *ast.InterfaceType:
+		x.mode = typexpr
+		x.typ = c.typ(e)
+
+	default:
+		c.errorf(e.Pos(), "unexpected expression %s", e)
+	}
+
+	x.expr = e
+	if x.mode == invalid {
+		x.typ = Typ[Invalid]
+	}
+	return kind
+}
+
+// ident sets x to the object denoted by the identifier e
+func (c *Checker) ident(x *operand, e *ast.Ident) {
+	x.mode = invalid
+	x.expr = e
+	if e.Name == "_" {
+		c.errorf(e.Pos(), "cannot use _ as value")
+		return
+	}
+	obj := c.lookup(e)
+	if obj == nil {
+		return // undefined, reported by the resolver
+	}
+	c.recordUse(e, obj)
+
+	typ := obj.Type()
+	switch obj := obj.(type) {
+	case *PkgName:
+		c.errorf(e.Pos(), "use of package %s without selector", obj.name)
+		return
+	case *Const:
+		x.mode = constant_
+		x.val = obj.val
+		if obj == universeIota {
+			if c.iota == nil {
+				c.errorf(e.Pos(), "cannot use iota outside constant declaration")
+				return
+			}
+			x.val = c.iota
+		}
+	case *TypeName:
+		x.mode = typexpr
+	case *Var:
+		obj.used = true
+		x.mode = variable
+	case *Func:
+		x.mode = value
+	case *Builtin:
+		x.mode = builtin
+		x.id = obj.id
+		x.typ = Typ[Invalid]
+		return
+	case *Nil:
+		x.mode = value
+	}
+	if typ == nil || !isValid(typ) && x.mode != typexpr {
+		x.mode = invalid
+		return
+	}
+	x.typ = typ
+}
+
+// selector sets x to the field or method selected by e
+func (c *Checker) selector(x *operand, e *ast.SelectorExpression) {
+	sel := e.Selector.Name
+	if ident, isIdent := e.X.(*ast.Ident); isIdent {
+		if pkg, isPkg := c.lookup(ident).(*PkgName); isPkg {
+			c.recordUse(ident, pkg)
+			pkg.used = true
+			// the imported packages are not loaded, their members are not known
+			x.mode = invalid
+			x.expr = e
+			return
+		}
+	}
+
+	c.exprOrType(x, e.X)
+	switch x.mode {
+	case invalid:
+		return
+	case typexpr:
+		// the types of the subset have no methods
+		c.errorf(e.Selector.Pos(), "%s.%s undefined (type %s has no method %s)", e.X, sel, x.typ, sel)
+		x.mode = invalid
+		return
+	case builtin:
+		c.errorf(e.Pos(), "invalid use of %s in selector expression", x)
+		x.mode = invalid
+		return
+	}
+
+	obj, indirect, ambiguous := lookupFieldOrMethod(x.typ, sel)
+	if obj == nil {
+		switch {
+		case !isValid(under(x.typ)):
+		case ambiguous:
+			c.errorf(e.Selector.Pos(), "ambiguous selector %s", exprString(e))
+		default:
+			c.errorf(e.Selector.Pos(), "%s undefined (type %s has no field or method %s)", exprString(e), x.typ, sel)
+		}
+		x.mode = invalid
+		return
+	}
+	c.recordUse(e.Selector, obj)
+	switch obj := obj.(type) {
+	case *Var:
+		if x.mode != variable && !indirect {
+			x.mode = value
+		} else {
+			x.mode = variable
+		}
+		x.typ = obj.typ
+	case *Func:
+		x.mode = value
+		x.typ = obj.typ
+	}
+}
+
+// embeddedType is a type searched for a field or method, reached through a pointer if indirect is set
+type embeddedType struct {
+	typ      Type
+	indirect bool
+}
+
+// lookupFieldOrMethod looks up the field or interface method name of a value of type T, following
+// the embedded fields breadth first; indirect is set if a pointer is followed to the field, and
+// ambiguous if several fields or methods of the same depth have the name
+func lookupFieldOrMethod(T Type, name string) (obj Object, indirect, ambiguous bool) {
+	if name == "_" {
+		return nil, false, false
+	}
+	current := []embeddedType{derefEmbedded(T, false)}
+	seen := map[*Named]bool{}
+	for len(current) > 0 {
+		var next []embeddedType
+		count := 0
+		for _, e := range current {
+			if named, isNamed := e.typ.(*Named); isNamed {
+				if seen[named] {
+					continue
+				}
+				seen[named] = true
+			}
+			switch t := under(e.typ).(type) {
+			case *Struct:
+				for _, f := range t.fields {
+					if f.name == name {
+						count++
+						obj, indirect = f, e.indirect
+					}
+					if f.embedded {
+						next = append(next, derefEmbedded(f.typ, e.indirect))
+					}
+				}
+			case *Interface:
+				if m := lookupMethod(t, name); m != nil {
+					count++
+					obj, indirect = m, e.indirect
+				}
+			}
+		}
+		if count == 1 {
+			return obj, indirect, false
+		}
+		if count > 1 {
+			return nil, false, true
+		}
+		current = next
+	}
+	return nil, false, false
+}
+
+// derefEmbedded returns the type searched for the fields of a value of type typ
+func derefEmbedded(typ Type, indirect bool) embeddedType {
+	if p, isPointer := under(typ).(*Pointer); isPointer {
+		return embeddedType{p.base, true}
+	}
+	return embeddedType{typ, indirect}
+}
+
+// indexExpr sets x to the element selected by the index expression e, or to the instance of
+// a generic type. It returns true if x is a generic function, which the caller instantiates
+// with the type arguments of e
+func (c *Checker) indexExpr(x *operand, e ast.Expression) (isFuncInst bool) {
+	indices := indexList(e)
+	c.genericExprOrType(x, indexedExpr(e))
+	switch x.mode {
+	case invalid:
+		c.use(indices...)
+		return false
+	case typexpr:
+		x.typ = c.typeInstance(x, e)
+		if !isValid(x.typ) {
+			x.mode = invalid
+		}
+		return false
+	case value:
+		if isGenericFunc(x.typ) {
+			return true
+		}
+	}
+	if len(indices) > 1 {
+		c.errorf(indices[1].Pos(), "invalid operation: more than one index")
+		c.use(indices...)
+		x.mode = invalid
+		return false
+	}
+
+	valid := false
+	length := int64(-1)
+	switch typ := coreType(x.typ).(type) {
+	case *Basic:
+		if isString(typ) {
+			valid = true
+			x.mode = value
+			x.typ = universe["byte"].Type()
+		}
+	case *Array:
+		valid = true
+		length = typ.len
+		if x.mode != variable {
+			x.mode = value
+		}
+		x.typ = typ.elem
+	case *Pointer:
+		if a, isArray := under(typ.base).(*Array); isArray {
+			valid = true
+			length = a.len
+			x.mode = variable
+			x.typ = a.elem
+		}
+	case *Slice:
+		valid = true
+		x.mode = variable
+		x.typ = typ.elem
+	}
+	if !valid {
+		c.errorf(x.expr.Pos(), "invalid operation: cannot index %s", x)
+		c.use(indices...)
+		x.mode = invalid
+		return false
+	}
+	c.index(indices[0], length)
+	return false
+}
+
+// funcInst sets x to the instance of the generic function x for the type arguments of the
+// index expression e, which must give all of them
+func (c *Checker) funcInst(x *operand, e ast.Expression) {
+	xlist := indexList(e)
+	targs := c.typeList(xlist)
+	if targs == nil {
+		x.mode = invalid
+		return
+	}
+	sig := x.typ.(*Signature)
+	if got, want := len(targs), len(sig.tparams); got != want {
+		if got > want {
+			c.errorf(xlist[want].Pos(), "got %d type arguments but %s has %d type parameters", got, x.expr, want)
+		} else {
+			c.errorf(e.Pos(), "not enough type arguments for func %s: have %d, want %d", x.expr, got, want)
+		}
+		x.mode = invalid
+		return
+	}
+	if i, cause := verify(sig.tparams, targs); i >= 0 {
+		c.errorf(xlist[i].Pos(), "%s", cause)
+		x.mode = invalid
+		return
+	}
+	x.typ = instantiateSignature(sig, targs)
+}
+
+// index checks the index e of an array, slice or string of the given length, or of
+// unknown length if it is negative. It returns the value of a constant index, or -1,
+// and whether the index is valid
+func (c *Checker) index(e ast.Expression, length int64) (int64, bool) {
+	var x operand
+	c.expr(&x, e)
+	if !c.isValidIndex(&x, "index") {
+		return -1, false
+	}
+	if x.mode != constant_ || x.val.Kind() == constant.Unknown {
+		return -1, true
+	}
+	v, _ := constant.Int64Val(x.val)
+	if length >= 0 && v >= length {
+		c.errorf(e.Pos(), "invalid argument: index %s out of bounds [0:%d]", x.val, length)
+		return v, false
+	}
+	return v, true
+}
+
+// isValidIndex checks that x is an integer that may be used as an index or size, described
+// by what in errors: a constant must be non-negative and representable by an int
+func (c *Checker) isValidIndex(x *operand, what string) bool {
+	if x.mode == invalid {
+		return false
+	}
+	c.convertUntyped(x, Typ[Int])
+	if x.mode == invalid {
+		return false
+	}
+	if !allInteger(x.typ) {
+		c.errorf(x.expr.Pos(), "invalid argument: %s %s must be integer", what, x)
+		return false
+	}
+	if x.mode == constant_ {
+		if constant.Sign(x.val) < 0 {
+			c.errorf(x.expr.Pos(), "invalid argument: %s %s must not be negative", what, x)
+			return false
+		}
+		if !representableConst(x.val, Typ[Int], &x.val) {
+			c.errorf(x.expr.Pos(), "invalid argument: %s %s overflows int", what, x)
+			return false
+		}
+	}
+	return true
+}
+
+// unparen returns e with the enclosing parentheses removed
+func unparen(e ast.Expression) ast.Expression {
+	for {
+		p, isParen := e.(*ast.ParenExpression)
+		if !isParen {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// compositeLit sets x to the value of the composite literal e; hint is the type of a
+// literal whose type is elided
+func (c *Checker) compositeLit(x *operand, e *ast.CompositeLiteral, hint Type) {
+	var typ Type
+	switch {
+	case e.Type != nil:
+		typ = c.typ(e.Type)
+	case hint != nil:
+		typ = hint
+	default:
+		c.errorf(e.Pos(), "invalid composite literal type: missing type")
+		c.use(e.Elements...)
+		x.mode = invalid
+		return
+	}
+
+	switch utyp := coreType(typ).(type) {
+	case *Struct:
+		c.structLit(e, typ, utyp)
+	case *Array:
+		c.indexedElts(e.Elements, utyp.elem, utyp.len)
+	case *Slice:
+		c.indexedElts(e.Elements, utyp.elem, -1)
+	default:
+		if utyp == nil || isValid(utyp) {
+			c.errorf(e.Pos(), "invalid composite literal type %s", typ)
+		}
+		for _, elt := range e.Elements {
+			if kv, isKeyValue := elt.(*ast.KeyValueExpression); isKeyValue {
+				elt = kv.Value
+			}
+			c.use(elt)
+		}
+		x.mode = invalid
+		return
+	}
+	x.mode = value
+	x.typ = typ
+}
+
+// structLit checks the elements of the literal e of the struct type typ
+func (c *Checker) structLit(e *ast.CompositeLiteral, typ Type, utyp *Struct) {
+	if len(e.Elements) == 0 {
+		return
+	}
+	var x operand
+	if _, isKeyValue := e.Elements[0].(*ast.KeyValueExpression); isKeyValue {
+		visited := make([]bool, len(utyp.fields))
+		for _, elt := range e.Elements {
+			kv, isKeyValue := elt.(*ast.KeyValueExpression)
+			if !isKeyValue {
+				c.errorf(elt.Pos(), "mixture of field:value and value elements in struct literal")
+				c.use(elt)
+				continue
+			}
+			key, isIdent := kv.Key.(*ast.Ident)
+			if !isIdent {
+				c.errorf(kv.Pos(), "invalid field name %s in struct literal", kv.Key)
+				c.use(kv.Value)
+				continue
+			}
+			i := fieldIndex(utyp.fields, key.Name)
+			if i < 0 {
+				c.errorf(kv.Pos(), "unknown field %s in struct literal of type %s", key.Name, typ)
+				c.use(kv.Value)
+				continue
+			}
+			fld := utyp.fields[i]
+			c.recordUse(key, fld)
+			c.exprWithHint(&x, kv.Value, fld.typ)
+			c.assignment(&x, fld.typ, "struct literal")
+			if visited[i] {
+				c.errorf(kv.Pos(), "duplicate field name %s in struct literal", key.Name)
+				continue
+			}
+			visited[i] = true
+		}
+		return
+	}
+
+	for i, elt := range e.Elements {
+		if kv, isKeyValue := elt.(*ast.KeyValueExpression); isKeyValue {
+			c.errorf(kv.Pos(), "mixture of field:value and value elements in struct literal")
+			c.use(kv.Value)
+			continue
+		}
+		if i >= len(utyp.fields) {
+			c.errorf(elt.Pos(), "too many values in struct literal of type %s", typ)
+			c.use(e.Elements[i:]...)
+			return
+		}
+		fld := utyp.fields[i]
+		c.exprWithHint(&x, elt, fld.typ)
+		c.assignment(&x, fld.typ, "struct literal")
+	}
+	if len(e.Elements) < len(utyp.fields) {
+		c.errorf(e.RbracePos, "too few values in struct literal of type %s", typ)
+	}
+}
+
+// fieldIndex returns the index of the field with the given name, or -1
+func fieldIndex(fields []*Var, name string) int {
+	if name == "_" {
+		return -1
+	}
+	for i, f := range fields {
+		if f.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexedElts checks the elements of an array or slice literal of element type typ and the
+// given length, or of unknown length if it is negative, and returns the length of the literal
+func (c *Checker) indexedElts(elts []ast.Expression, typ Type, length int64) int64 {
+	visited := map[int64]bool{}
+	index, max := int64(0), int64(0)
+	var x operand
+	for _, elt := range elts {
+		validIndex := false
+		value := elt
+		if kv, isKeyValue := elt.(*ast.KeyValueExpression); isKeyValue {
+			c.keyIdent(kv.Key)
+			if i, ok := c.index(kv.Key, length); ok {
+				if i >= 0 {
+					index = i
+					validIndex = true
+				}
+			}
+			value = kv.Value
+		} else if length >= 0 && index >= length {
+			c.errorf(elt.Pos(), "index %d is out of bounds (>= %d)", index, length)
+		} else {
+			validIndex = true
+		}
+		if validIndex {
+			if visited[index] {
+				c.errorf(elt.Pos(), "duplicate index %d in array or slice literal", index)
+			}
+			visited[index] = true
+		}
+		index++
+		if index > max {
+			max = index
+		}
+		c.exprWithHint(&x, value, typ)
+		c.assignment(&x, typ, "array or slice literal")
+	}
+	return max
+}
+
+// keyIdent resolves an identifier key of an array or slice literal in the package scope. The
+// resolver leaves such keys alone unless the literal type is an array or slice type literal,
+// as they may name the fields of a struct type
+func (c *Checker) keyIdent(key ast.Expression) {
+	ident, isIdent := key.(*ast.Ident)
+	if !isIdent || ident.Obj != nil || ident.Name == "_" {
+		return
+	}
+	if ident.Obj = c.scope.LookupParent(ident.Name); ident.Obj == nil {
+		c.errorf(ident.Pos(), "undefined: %s", ident.Name)
+	}
+}
+
+// unary sets x to the value of the unary expression e
+func (c *Checker) unary(x *operand, e *ast.UnaryExpression) {
+	c.expr(x, e.X)
+	if x.mode == invalid {
+		return
+	}
+	if !c.op(unaryOpPredicates, x, e.Operator) {
+		x.mode = invalid
+		return
+	}
+	if x.mode == constant_ {
+		if x.val.Kind() == constant.Unknown {
+			return
+		}
+		var prec uint
+		if isUnsigned(x.typ) {
+			prec = uint(sizeof(under(x.typ).(*Basic)) * 8)
+		}
+		x.val = constant.UnaryOp(e.Operator, x.val, prec)
+		x.expr = e
+		c.overflow(x, e.OpPos)
+		return
+	}
+	x.mode = value
+}
+
+// binary sets x to the value of the binary operation lhs op rhs; e is the binary expression,
+// or nil for an assignment operation
+func (c *Checker) binary(x *operand, e ast.Expression, lhs, rhs ast.Expression, op tokens.TokenType, opPos tokens.Position) {
+	var y operand
+	c.expr(x, lhs)
+	c.expr(&y, rhs)
+	if x.mode == invalid {
+		return
+	}
+	if y.mode == invalid {
+		x.mode = invalid
+		x.expr = y.expr
+		return
+	}
+
+	if isShift(op) {
+		c.shift(x, &y, e, op, opPos)
+		return
+	}
+
+	c.matchTypes(x, &y)
+	if x.mode == invalid {
+		return
+	}
+
+	if isComparison(op) {
+		c.comparison(x, &y, op, opPos, false)
+		return
+	}
+
+	if !identical(x.typ, y.typ) {
+		if isValid(x.typ) && isValid(y.typ) {
+			if e != nil {
+				c.errorf(opPos, "invalid operation: %s (mismatched types %s and %s)", e, x.typ, y.typ)
+			} else {
+				c.errorf(opPos, "invalid operation: %s %s= %s (mismatched types %s and %s)", lhs, op, rhs, x.typ, y.typ)
+			}
+		}
+		x.mode = invalid
+		return
+	}
+
+	if !c.op(binaryOpPredicates, x, op) {
+		x.mode = invalid
+		return
+	}
+
+	if op == tokens.QUO || op == tokens.REM {
+		// an integer or constant division by a constant zero
+		if (x.mode == constant_ || isInteger(x.typ)) && y.mode == constant_ && constant.Sign(y.val) == 0 {
+			c.errorf(y.expr.Pos(), "invalid operation: division by zero")
+			x.mode = invalid
+			return
+		}
+		// a complex divisor whose squared parts underflow to zero
+		if x.mode == constant_ && y.mode == constant_ && isComplex(x.typ) {
+			re, im := constant.Real(y.val), constant.Imag(y.val)
+			re2, im2 := constant.BinaryOp(re, tokens.MUL, re), constant.BinaryOp(im, tokens.MUL, im)
+			if constant.Sign(re2) == 0 && constant.Sign(im2) == 0 {
+				c.errorf(y.expr.Pos(), "invalid operation: division by zero")
+				x.mode = invalid
+				return
+			}
+		}
+	}
+
+	if x.mode == constant_ && y.mode == constant_ {
+		if x.val.Kind() == constant.Unknown || y.val.Kind() == constant.Unknown {
+			x.val = constant.MakeUnknown()
+			return
+		}
+		// the division of integers is the truncated integer division
+		tok := op
+		if op == tokens.QUO && isInteger(x.typ) {
+			tok = tokens.QUO_ASSIGN
+		}
+		x.val = constant.BinaryOp(x.val, tok, y.val)
+		if e != nil {
+			x.expr = e
+		}
+		c.overflow(x, opPos)
+		return
+	}
+	x.mode = value
+}
+
+// matchTypes converts the untyped operand of a binary operation to the type of the other
+// operand, if the operation may be valid then
+func (c *Checker) matchTypes(x, y *operand) {
+	mayConvert := func(x, y *operand) bool {
+		if isTyped(x.typ) && isTyped(y.typ) {
+			return false
+		}
+		if isBoolean(x.typ) != isBoolean(y.typ) {
+			return false
+		}
+		if isString(x.typ) != isString(y.typ) {
+			return false
+		}
+		if x.isNil() {
+			return hasNil(y.typ)
+		}
+		if y.isNil() {
+			return hasNil(x.typ)
+		}
+		if isPointer(x.typ) || isPointer(y.typ) {
+			return false
+		}
+		return true
+	}
+	if mayConvert(x, y) {
+		c.convertUntyped(x, y.typ)
+		if x.mode == invalid {
+			return
+		}
+		c.convertUntyped(y, x.typ)
+		if y.mode == invalid {
+			x.mode = invalid
+		}
+	}
+}
+
+// isPointer reports whether the underlying type of t is a pointer type
+func isPointer(t Type) bool {
+	_, isPtr := under(t).(*Pointer)
+	return isPtr
+}
+
+// shift sets x to the value of the shift x op y, the expression e at opPos
+func (c *Checker) shift(x, y *operand, e ast.Expression, op tokens.TokenType, opPos tokens.Position) {
+	// the left operand must be an integer, or an untyped constant representable as one
+	var xval constant.Value
+	if x.mode == constant_ {
+		xval = constant.ToInt(x.val)
+	}
+	if !allInteger(x.typ) && !(isUntyped(x.typ) && xval != nil && xval.Kind() == constant.Int) {
+		c.errorf(x.expr.Pos(), "invalid operation: shifted operand %s must be integer", x)
+		x.mode = invalid
+		return
+	}
+
+	// the right operand must be an integer, or an untyped constant representable as uint
+	var yval constant.Value
+	if y.mode == constant_ {
+		yval = constant.ToInt(y.val)
+		if yval.Kind() == constant.Int && constant.Sign(yval) < 0 {
+			c.errorf(y.expr.Pos(), "invalid operation: negative shift count %s", y)
+			x.mode = invalid
+			return
+		}
+		if isUntyped(y.typ) {
+			// the count is checked but keeps its type
+			c.representable(y, Typ[Uint])
+			if y.mode == invalid {
+				x.mode = invalid
+				return
+			}
+		}
+	} else {
+		switch {
+		case allInteger(y.typ):
+		case isUntyped(y.typ):
+			c.convertUntyped(y, Typ[Uint])
+			if y.mode == invalid {
+				x.mode = invalid
+				return
+			}
+		default:
+			c.errorf(y.expr.Pos(), "invalid operation: shift count %s must be integer", y)
+			x.mode = invalid
+			return
+		}
+	}
+
+	if x.mode == constant_ {
+		if y.mode == constant_ {
+			if x.val.Kind() == constant.Unknown || y.val.Kind() == constant.Unknown {
+				x.val = constant.MakeUnknown()
+				if !isInteger(x.typ) {
+					x.typ = Typ[UntypedInt]
+				}
+				return
+			}
+			// the count of a constant shift is bounded, large enough for the smallest float64
+			const shiftBound = 1023 - 1 + 52
+			s, ok := constant.Uint64Val(yval)
+			if !ok || s > shiftBound {
+				c.errorf(y.expr.Pos(), "invalid operation: invalid shift count %s", y)
+				x.mode = invalid
+				return
+			}
+			// an untyped float like 2.0 shifts as an integer
+			if !isInteger(x.typ) {
+				x.typ = Typ[UntypedInt]
+			}
+			x.val = constant.Shift(xval, op, uint(s))
+			if e != nil {
+				x.expr = e
+			}
+			c.overflow(x, opPos)
+			return
+		}
+
+		if isUntyped(x.typ) {
+			// the type of a non-constant shift of an untyped constant is the type the shift
+			// takes in its context, which must be an integer type
+			if info, found := c.untyped[x.expr]; found {
+				info.isLhs = true
+				c.untyped[x.expr] = info
+			}
+			x.mode = value
+			return
+		}
+	}
+
+	if !allInteger(x.typ) {
+		c.errorf(x.expr.Pos(), "invalid operation: shifted operand %s must be integer", x)
+		x.mode = invalid
+		return
+	}
+	x.mode = value
+}
+
+// comparison sets x to the value of the comparison x op y; if switchCase is set, x is a case
+// value of a switch statement on y, or of a switch without tag if y.expr is nil
+func (c *Checker) comparison(x, y *operand, op tokens.TokenType, opPos tokens.Position, switchCase bool) {
+	errOp := x
+	cause := ""
+	xok, _ := x.assignableTo(y.typ)
+	yok, _ := y.assignableTo(x.typ)
+	mismatched := !xok && !yok
+	if !mismatched {
+		switch op {
+		case tokens.EQL, tokens.NEQ:
+			switch {
+			case x.isNil() && y.isNil():
+				cause = "operator " + op.String() + " not defined on nil"
+			case x.isNil() || y.isNil():
+			case !comparable(x.typ):
+				cause = incomparableCause(x.typ)
+			case !comparable(y.typ):
+				errOp = y
+				cause = incomparableCause(y.typ)
+			}
+		default:
+			switch {
+			case !allOrdered(x.typ):
+				cause = "operator " + op.String() + " not defined on " + kindString(x.typ)
+			case !allOrdered(y.typ):
+				errOp = y
+				cause = "operator " + op.String() + " not defined on " + kindString(y.typ)
+			}
+		}
+	}
+
+	if mismatched || cause != "" {
+		if mismatched {
+			cause = "mismatched types " + x.typ.String() + " and " + y.typ.String()
+		}
+		switch {
+		case switchCase && y.expr == nil:
+			c.errorf(x.expr.Pos(), "invalid case %s in switch (%s)", x.expr, cause)
+		case switchCase:
+			c.errorf(x.expr.Pos(), "invalid case %s in switch on %s (%s)", x.expr, y.expr, cause)
+		case mismatched:
+			c.errorf(opPos, "invalid operation: %s %s %s (%s)", x.expr, op, y.expr, cause)
+		default:
+			c.errorf(errOp.expr.Pos(), "invalid operation: %s %s %s (%s)", x.expr, op, y.expr, cause)
+		}
+		x.mode = invalid
+		return
+	}
+
+	if x.mode == constant_ && y.mode == constant_ {
+		x.val = constant.MakeBool(constant.Compare(x.val, op, y.val))
+		x.typ = Typ[UntypedBool]
+		return
+	}
+	x.mode = value
+	c.updateExprType(x.expr, defaultType(x.typ), true)
+	c.updateExprType(y.expr, defaultType(y.typ), true)
+	x.typ = Typ[UntypedBool]
+}
+
+// incomparableCause explains why values of type typ cannot be compared
+func incomparableCause(typ Type) string {
+	if isTypeParam(typ) {
+		return "incomparable types in type set"
+	}
+	switch t := under(typ).(type) {
+	case *Slice, *Signature:
+		return kindString(typ) + " can only be compared to nil"
+	case *Struct:
+		for _, f := range t.fields {
+			if !comparable(f.typ) {
+				return "struct containing " + f.typ.String() + " cannot be compared"
+			}
+		}
+	}
+	return typ.String() + " cannot be compared"
+}
+
+// kindString returns the kind of a composite type, or the type itself if it is a basic type
+// or a type parameter
+func kindString(typ Type) string {
+	if isTypeParam(typ) {
+		return typ.String()
+	}
+	switch under(typ).(type) {
+	case *Array:
+		return "array"
+	case *Slice:
+		return "slice"
+	case *Struct:
+		return "struct"
+	case *Pointer:
+		return "pointer"
+	case *Signature:
+		return "func"
+	case *Interface:
+		return "interface"
+	}
+	return typ.String()
+}
+
+// implicitType returns the type an untyped operand x takes when it is used where a value of type
+// target is expected, with the value of a constant x in that type, or why x cannot be converted
+// implicitly. Typed operands keep their type
+func (c *Checker) implicitType(x *operand, target Type) (Type, constant.Value, reprError) {
+	if x.mode == invalid || isTyped(x.typ) || !isValid(target) {
+		return x.typ, nil, reprOk
+	}
+	if isUntyped(target) {
+		// both x and target are untyped: the numeric kinds order from int to complex
+		xkind, tkind := x.typ.(*Basic).kind, target.(*Basic).kind
+		if isNumeric(x.typ) && isNumeric(target) {
+			if xkind < tkind {
+				return target, nil, reprOk
+			}
+		} else if xkind != tkind {
+			return nil, nil, reprInvalid
+		}
+		return x.typ, nil, reprOk
+	}
+
+	if tp, isTypeParam := target.(*TypeParam); isTypeParam {
+		// x must convert to all the types of the type set of target
+		if !tp.allTerms(func(t Type) bool {
+			typ, _, err := c.implicitType(x, t)
+			return typ != nil && err == reprOk
+		}) {
+			return nil, nil, reprInvalid
+		}
+		if x.isNil() {
+			return Typ[UntypedNil], nil, reprOk
+		}
+		return target, nil, reprOk
+	}
+
+	switch u := under(target).(type) {
+	case *Basic:
+		if x.mode == constant_ {
+			v, err := representation(x, u)
+			if err != reprOk {
+				return nil, nil, err
+			}
+			return target, v, reprOk
+		}
+		// non-constant untyped values are booleans of comparisons and the results of shifts
+		switch {
+		case isBoolean(x.typ):
+			if !isBoolean(u) {
+				return nil, nil, reprInvalid
+			}
+		case isNumeric(x.typ):
+			if !isNumeric(u) {
+				return nil, nil, reprInvalid
+			}
+		default:
+			return nil, nil, reprInvalid
+		}
+	case *Interface:
+		if x.isNil() {
+			return Typ[UntypedNil], nil, reprOk
+		}
+		if !u.Empty() {
+			return nil, nil, reprInvalid // untyped values have no methods
+		}
+		return defaultType(x.typ), nil, reprOk
+	case *Pointer, *Signature, *Slice:
+		if !x.isNil() {
+			return nil, nil, reprInvalid
+		}
+		return Typ[UntypedNil], nil, reprOk
+	default:
+		return nil, nil, reprInvalid
+	}
+	return target, nil, reprOk
+}
+
+// convertUntyped converts the untyped operand x to the type target, as for an implicit
+// conversion in a binary operation
+func (c *Checker) convertUntyped(x *operand, target Type) {
+	newType, val, err := c.implicitType(x, target)
+	if err != reprOk {
+		c.invalidConversion(err, x, under(target))
+		x.mode = invalid
+		return
+	}
+	c.setImplicitType(x, newType, val)
+}
+
+// setImplicitType sets the type of the untyped operand x to the result of implicitType
+func (c *Checker) setImplicitType(x *operand, newType Type, val constant.Value) {
+	if val != nil {
+		x.val = val
+		c.updateExprVal(x.expr, val)
+	}
+	if newType != x.typ {
+		x.typ = newType
+		c.updateExprType(x.expr, newType, false)
+	}
+}
+
+// updateExprVal updates the value of the untyped constant expression x
+func (c *Checker) updateExprVal(x ast.Expression, val constant.Value) {
+	if info, found := c.untyped[x]; found {
+		info.val = val
+		c.untyped[x] = info
+	}
+}
+
+// updateExprType updates the type of the untyped expression x, and of the operands its type
+// depends on, to typ. If final is set or typ is typed, the type is recorded and x no longer
+// untyped; the operands of constant expressions remain untyped
+func (c *Checker) updateExprType(x ast.Expression, typ Type, final bool) {
+	old, found := c.untyped[x]
+	if !found {
+		return
+	}
+	switch x := x.(type) {
+	case *ast.ParenExpression:
+		c.updateExprType(x.X, typ, final)
+	case *ast.UnaryExpression:
+		if old.mode != constant_ {
+			c.updateExprType(x.X, typ, final)
+		}
+	case *ast.BinaryExpression:
+		if old.mode != constant_ {
+			switch {
+			case isComparison(x.Operator):
+				// the result type does not depend on the operand types
+			case isShift(x.Operator):
+				c.updateExprType(x.LeftX, typ, final)
+			default:
+				c.updateExprType(x.LeftX, typ, final)
+				c.updateExprType(x.RightX, typ, final)
+			}
+		}
+	}
+
+	if !final && isUntyped(typ) {
+		old.typ = under(typ).(*Basic)
+		c.untyped[x] = old
+		return
+	}
+	delete(c.untyped, x)
+	if old.isLhs && !isInteger(typ) {
+		c.errorf(x.Pos(), "invalid operation: shifted operand %s (type %s) must be integer", x, typ)
+		return
+	}
+	c.recordTypeAndValue(x, old.mode, typ, old.val)
+}
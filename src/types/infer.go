@@ -0,0 +1,412 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"strings"
+)
+
+// infer returns the type arguments of the call e of a generic function with the type parameters
+// tparams, given the explicit type arguments targs, at the expressions xlist, and the arguments
+// args of the parameters params. The types of the typed arguments are unified with the types of
+// their parameters, the inferred types with the core types of the constraints, and the untyped
+// arguments of parameters of a type parameter type take their default types. For every type
+// argument, infer also returns the expression it is inferred from, or nil. It reports an error
+// and returns nil if a type argument cannot be inferred
+func (c *Checker) infer(e *ast.CallExpression, tparams []*TypeParam, targs []Type, xlist []ast.Expression, params *Tuple, args []*operand) ([]Type, []ast.Expression) {
+	u := newUnifier(tparams)
+	for i, targ := range targs {
+		u.src = xlist[i]
+		u.set(i, targ)
+	}
+	if len(targs) == len(tparams) {
+		return u.targs, u.srcs
+	}
+
+	// the typed arguments
+	for i, arg := range args {
+		if i >= params.Len() {
+			break
+		}
+		ptype := params.At(i).typ
+		if isUntyped(arg.typ) || !isParameterized(tparams, ptype) {
+			continue
+		}
+		u.src = arg.expr
+		if !u.unify(ptype, arg.typ) {
+			c.inferError(tparams, u, ptype, arg)
+			return nil, nil
+		}
+	}
+	if !c.inferCore(e, u) {
+		return nil, nil
+	}
+
+	// the untyped arguments of the parameters of a type parameter type not inferred yet; the
+	// numeric constants passed to the same parameter take the default type of the largest kind
+	untyped := make([]Type, len(tparams))
+	for i, arg := range args {
+		if i >= params.Len() || !isUntyped(arg.typ) || arg.isNil() {
+			continue
+		}
+		j := u.index(params.At(i).typ)
+		if j < 0 || u.targs[j] != nil {
+			continue
+		}
+		if untyped[j] == nil {
+			untyped[j], u.srcs[j] = arg.typ, arg.expr
+			continue
+		}
+		max := maxType(untyped[j], arg.typ)
+		if max == nil {
+			c.errorf(arg.expr.Pos(), "mismatched types %s and %s (cannot infer %s)", untyped[j], arg.typ, tparams[j])
+			return nil, nil
+		}
+		untyped[j] = max
+	}
+	for j, t := range untyped {
+		if t != nil {
+			u.targs[j] = defaultType(t)
+		}
+	}
+	if !c.inferCore(e, u) {
+		return nil, nil
+	}
+
+	// the inferred types may refer to the type parameters inferred from them
+	inferred := u.targs
+	for range tparams {
+		smap := makeSubstMap(tparams, inferred)
+		changed := false
+		for i, t := range inferred {
+			if t == nil {
+				continue
+			}
+			if s := subst(t, smap); s != t {
+				inferred[i] = s
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	for i, t := range inferred {
+		if t == nil {
+			c.errorf(e.RParenPos, "in call to %s, cannot infer %s", e.Function, tparams[i])
+			return nil, nil
+		}
+	}
+	return inferred, u.srcs
+}
+
+// inferError reports that the type of the argument arg does not unify with the type ptype of
+// its parameter
+func (c *Checker) inferError(tparams []*TypeParam, u *unifier, ptype Type, arg *operand) {
+	allFailed := true
+	for _, t := range u.targs {
+		if t != nil {
+			allFailed = false
+		}
+	}
+	if allFailed {
+		c.errorf(arg.expr.Pos(), "type %s of %s does not match %s (cannot infer %s)", arg.typ, arg.expr, ptype, typeParamsString(tparams))
+		return
+	}
+	if inferred := subst(ptype, makeSubstMap(tparams, u.targs)); inferred != ptype {
+		c.errorf(arg.expr.Pos(), "type %s of %s does not match inferred type %s for %s", arg.typ, arg.expr, inferred, ptype)
+		return
+	}
+	c.errorf(arg.expr.Pos(), "type %s of %s does not match %s", arg.typ, arg.expr, ptype)
+}
+
+// inferCore unifies the types inferred for the type parameters of u with the core types of
+// their constraints, and infers the type parameters whose constraints have a single type. It
+// reports an error and returns false if an inferred type does not unify
+func (c *Checker) inferCore(e *ast.CallExpression, u *unifier) bool {
+	for changed := true; changed; {
+		changed = false
+		for i, tpar := range u.tparams {
+			core := coreTerm(tpar)
+			if core == nil {
+				continue
+			}
+			known := countInferred(u)
+			if tx := u.targs[i]; tx != nil {
+				if core.tilde && !isTypeParam(tx) {
+					tx = under(tx)
+				}
+				u.src = u.srcs[i]
+				if !u.unify(core.typ, tx) {
+					pos := e.Function.Pos()
+					if u.srcs[i] != nil {
+						pos = u.srcs[i].Pos()
+					}
+					c.errorf(pos, "%s (type %s) does not satisfy %s", tpar, u.targs[i], tpar.bound)
+					return false
+				}
+			} else if !core.tilde {
+				u.src = nil
+				u.set(i, core.typ)
+			}
+			if countInferred(u) > known {
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// countInferred returns the number of type arguments inferred by u
+func countInferred(u *unifier) int {
+	n := 0
+	for _, t := range u.targs {
+		if t != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// coreTerm returns the single term of the type set of the constraint of tpar, or a ~ term of
+// the core type of the set, or nil
+func coreTerm(tpar *TypeParam) *term {
+	s := tpar.iface().typeSet()
+	if !s.hasTerms() {
+		return nil
+	}
+	if len(s.terms) == 1 {
+		return s.terms[0]
+	}
+	if core := coreType(tpar); core != nil {
+		return &term{true, core}
+	}
+	return nil
+}
+
+// maxType returns the larger of the untyped numeric types x and y, x if they are the same type
+// otherwise, or nil
+func maxType(x, y Type) Type {
+	if isNumeric(x) && isNumeric(y) {
+		if x.(*Basic).kind < y.(*Basic).kind {
+			return y
+		}
+		return x
+	}
+	if identical(x, y) {
+		return x
+	}
+	return nil
+}
+
+// typeParamsString returns the names of the type parameters in a list like "T, U, and V"
+func typeParamsString(list []*TypeParam) string {
+	switch len(list) {
+	case 1:
+		return list[0].String()
+	case 2:
+		return list[0].String() + " and " + list[1].String()
+	}
+	s := make([]string, len(list))
+	for i, t := range list {
+		s[i] = t.String()
+	}
+	s[len(s)-1] = "and " + s[len(s)-1]
+	return strings.Join(s, ", ")
+}
+
+// isParameterized reports whether typ contains one of the type parameters tparams
+func isParameterized(tparams []*TypeParam, typ Type) bool {
+	switch t := typ.(type) {
+	case *TypeParam:
+		for _, tpar := range tparams {
+			if t == tpar {
+				return true
+			}
+		}
+	case *Array:
+		return isParameterized(tparams, t.elem)
+	case *Slice:
+		return isParameterized(tparams, t.elem)
+	case *Pointer:
+		return isParameterized(tparams, t.base)
+	case *Struct:
+		for _, f := range t.fields {
+			if isParameterized(tparams, f.typ) {
+				return true
+			}
+		}
+	case *Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if isParameterized(tparams, t.At(i).typ) {
+				return true
+			}
+		}
+	case *Signature:
+		return isParameterized(tparams, t.params) || isParameterized(tparams, t.results)
+	case *Interface:
+		for _, m := range t.methods {
+			if isParameterized(tparams, m.typ) {
+				return true
+			}
+		}
+		for _, e := range t.embeddeds {
+			if isParameterized(tparams, e) {
+				return true
+			}
+		}
+	case *Union:
+		for _, term := range t.terms {
+			if isParameterized(tparams, term.typ) {
+				return true
+			}
+		}
+	case *Named:
+		for _, targ := range t.targs {
+			if isParameterized(tparams, targ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unifier infers the types of type parameters by unifying types containing them with other types
+type unifier struct {
+	tparams []*TypeParam
+	targs   []Type           // inferred types; nil if not known
+	srcs    []ast.Expression // expressions the types are inferred from
+	src     ast.Expression   // expression of the types unified
+}
+
+func newUnifier(tparams []*TypeParam) *unifier {
+	return &unifier{
+		tparams: tparams,
+		targs:   make([]Type, len(tparams)),
+		srcs:    make([]ast.Expression, len(tparams)),
+	}
+}
+
+// index returns the index of typ in the type parameters of u, or -1 if it is not one of them
+func (u *unifier) index(typ Type) int {
+	if tp, isTypeParam := typ.(*TypeParam); isTypeParam {
+		for i, tpar := range u.tparams {
+			if tp == tpar {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// set infers the type t for the i'th type parameter
+func (u *unifier) set(i int, t Type) {
+	u.targs[i] = t
+	u.srcs[i] = u.src
+}
+
+// unify reports whether x and y are the same type once the type parameters are inferred; a named
+// type unifies with a type literal of its underlying type
+func (u *unifier) unify(x, y Type) bool {
+	return u.nify(x, y, 0)
+}
+
+func (u *unifier) nify(x, y Type, depth int) bool {
+	const maxDepth = 64 // the types cannot nest deeper without a cycle through inferred types
+	if depth > maxDepth {
+		return false
+	}
+	depth++
+	if i := u.index(x); i >= 0 {
+		return u.bind(i, y, depth)
+	}
+	if i := u.index(y); i >= 0 {
+		return u.bind(i, x, depth)
+	}
+	if x == y {
+		return true
+	}
+	if hasName(x) != hasName(y) && !isTypeParam(x) && !isTypeParam(y) {
+		if hasName(x) {
+			x = under(x)
+		} else {
+			y = under(y)
+		}
+	}
+
+	switch x := x.(type) {
+	case *Basic:
+		if y, isBasic := y.(*Basic); isBasic {
+			return x.kind == y.kind
+		}
+	case *Array:
+		if y, isArray := y.(*Array); isArray {
+			return x.len == y.len && u.nify(x.elem, y.elem, depth)
+		}
+	case *Slice:
+		if y, isSlice := y.(*Slice); isSlice {
+			return u.nify(x.elem, y.elem, depth)
+		}
+	case *Pointer:
+		if y, isPointer := y.(*Pointer); isPointer {
+			return u.nify(x.base, y.base, depth)
+		}
+	case *Struct:
+		if y, isStruct := y.(*Struct); isStruct && len(x.fields) == len(y.fields) {
+			for i, f := range x.fields {
+				g := y.fields[i]
+				if f.embedded != g.embedded || f.name != g.name || x.Tag(i) != y.Tag(i) || !u.nify(f.typ, g.typ, depth) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Tuple:
+		if y, isTuple := y.(*Tuple); isTuple && x.Len() == y.Len() {
+			for i := 0; i < x.Len(); i++ {
+				if !u.nify(x.At(i).typ, y.At(i).typ, depth) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Signature:
+		if y, isSig := y.(*Signature); isSig {
+			return x.variadic == y.variadic && u.nify(x.params, y.params, depth) && u.nify(x.results, y.results, depth)
+		}
+	case *Named:
+		if y, isNamed := y.(*Named); isNamed && x.orig != nil && x.orig == y.orig {
+			for i, targ := range x.targs {
+				if !u.nify(targ, y.targs[i], depth) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Interface, *TypeParam:
+		return identical(x, y)
+	}
+	return false
+}
+
+// bind unifies the i'th type parameter with t: t is inferred for it if nothing is yet, and a
+// named type is preferred to the type literal it unifies with
+func (u *unifier) bind(i int, t Type, depth int) bool {
+	cur := u.targs[i]
+	if cur == nil {
+		u.set(i, t)
+		return true
+	}
+	if !u.nify(cur, t, depth) {
+		return false
+	}
+	if hasName(t) && !hasName(cur) {
+		u.targs[i] = t
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+// substMap maps the type parameters to the types replacing them
+type substMap map[*TypeParam]Type
+
+// makeSubstMap returns the map of the type parameters tparams to the type arguments targs
+func makeSubstMap(tparams []*TypeParam, targs []Type) substMap {
+	smap := make(substMap, len(tparams))
+	for i, tpar := range tparams {
+		if i < len(targs) && targs[i] != nil {
+			smap[tpar] = targs[i]
+		}
+	}
+	return smap
+}
+
+// subst returns typ with the type parameters replaced by their types in smap; the types not
+// containing them are returned as they are
+func subst(typ Type, smap substMap) Type {
+	if len(smap) == 0 || typ == nil {
+		return typ
+	}
+	switch t := typ.(type) {
+	case *TypeParam:
+		if u, found := smap[t]; found {
+			return u
+		}
+	case *Array:
+		if elem := subst(t.elem, smap); elem != t.elem {
+			return NewArray(elem, t.len)
+		}
+	case *Slice:
+		if elem := subst(t.elem, smap); elem != t.elem {
+			return NewSlice(elem)
+		}
+	case *Pointer:
+		if base := subst(t.base, smap); base != t.base {
+			return NewPointer(base)
+		}
+	case *Struct:
+		if fields, copied := substVars(t.fields, smap); copied {
+			return NewStruct(fields, t.tags)
+		}
+	case *Tuple:
+		if t == nil {
+			return typ
+		}
+		if vars, copied := substVars(t.vars, smap); copied {
+			return NewTuple(vars...)
+		}
+	case *Signature:
+		params := subst(t.params, smap).(*Tuple)
+		results := subst(t.results, smap).(*Tuple)
+		if params != t.params || results != t.results {
+			return &Signature{params: params, results: results, variadic: t.variadic}
+		}
+	case *Interface:
+		methods, mcopied := substFuncs(t.methods, smap)
+		embeddeds, ecopied := substTypes(t.embeddeds, smap)
+		if mcopied || ecopied {
+			return &Interface{methods: methods, embeddeds: embeddeds, implicit: t.implicit}
+		}
+	case *Union:
+		var terms []*Term
+		for i, term := range t.terms {
+			if u := subst(term.typ, smap); u != term.typ && terms == nil {
+				terms = make([]*Term, len(t.terms))
+				copy(terms, t.terms[:i])
+			}
+			if terms != nil {
+				terms[i] = NewTerm(term.tilde, subst(term.typ, smap))
+			}
+		}
+		if terms != nil {
+			return NewUnion(terms)
+		}
+	case *Named:
+		if targs, copied := substTypes(t.targs, smap); copied {
+			return instantiateNamed(t.orig, targs)
+		}
+	}
+	return typ
+}
+
+// substVars returns the variables of list with their types substituted, and whether any of them
+// changed; list is returned if none did
+func substVars(list []*Var, smap substMap) ([]*Var, bool) {
+	var res []*Var
+	for i, v := range list {
+		if typ := subst(v.typ, smap); typ != v.typ {
+			if res == nil {
+				res = make([]*Var, len(list))
+				copy(res, list)
+			}
+			nv := *v
+			nv.typ = typ
+			res[i] = &nv
+		}
+	}
+	if res == nil {
+		return list, false
+	}
+	return res, true
+}
+
+// substFuncs is like substVars for the methods of an interface
+func substFuncs(list []*Func, smap substMap) ([]*Func, bool) {
+	var res []*Func
+	for i, m := range list {
+		if typ := subst(m.typ, smap); typ != m.typ {
+			if res == nil {
+				res = make([]*Func, len(list))
+				copy(res, list)
+			}
+			res[i] = &Func{object{name: m.name, typ: typ, pos: m.pos}}
+		}
+	}
+	if res == nil {
+		return list, false
+	}
+	return res, true
+}
+
+// substTypes is like substVars for a list of types
+func substTypes(list []Type, smap substMap) ([]Type, bool) {
+	var res []Type
+	for i, t := range list {
+		if u := subst(t, smap); u != t {
+			if res == nil {
+				res = make([]Type, len(list))
+				copy(res, list)
+			}
+			res[i] = u
+		}
+	}
+	if res == nil {
+		return list, false
+	}
+	return res, true
+}
+
+// instantiateNamed returns the instance of the generic type orig for the type arguments targs.
+// The instances for identical arguments are the same type
+func instantiateNamed(orig *Named, targs []Type) *Named {
+	for _, inst := range orig.instances {
+		if identicalList(inst.targs, targs) {
+			return inst
+		}
+	}
+	inst := &Named{obj: orig.obj, orig: orig, targs: targs}
+	orig.instances = append(orig.instances, inst)
+	return inst
+}
+
+// instantiateSignature returns the signature of the instance of the generic function with the
+// signature sig for the type arguments targs
+func instantiateSignature(sig *Signature, targs []Type) *Signature {
+	inst := &Signature{params: sig.params, results: sig.results, variadic: sig.variadic}
+	if res, isSig := subst(inst, makeSubstMap(sig.tparams, targs)).(*Signature); isSig {
+		return res
+	}
+	return inst
+}
+
+// identicalList reports whether the types of the lists x and y are identical
+func identicalList(x, y []Type) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i, t := range x {
+		if !identical(t, y[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// verify checks that the type arguments targs satisfy the constraints of the type parameters
+// tparams, which may refer to the parameters. It returns the index of the first argument that
+// does not with the cause, or -1
+func verify(tparams []*TypeParam, targs []Type) (int, string) {
+	smap := makeSubstMap(tparams, targs)
+	for i, tpar := range tparams {
+		if ok, cause := implements(targs[i], subst(tpar.bound, smap), true); !ok {
+			return i, cause
+		}
+	}
+	return -1, ""
+}
+
+// implements reports whether the type V implements the interface T, or satisfies it if
+// constraint is set, with the cause if it does not
+func implements(V, T Type, constraint bool) (bool, string) {
+	Vu, Tu := under(V), under(T)
+	if !isValid(Vu) || !isValid(Tu) {
+		return true, "" // avoid follow-up errors
+	}
+	verb := "implement"
+	if constraint {
+		verb = "satisfy"
+	}
+	Ti, isIface := Tu.(*Interface)
+	if !isIface {
+		return false, T.String() + " is not an interface"
+	}
+	Tset := Ti.typeSet()
+	if Tset.IsAll() {
+		return true, ""
+	}
+	// a type parameter, or an interface, stands for the types of its type set
+	Vi, _ := Vu.(*Interface)
+	if Vi != nil && Vi.typeSet().IsEmpty() {
+		return true, ""
+	}
+	if Tset.IsEmpty() {
+		return false, "cannot " + verb + " " + T.String() + " (empty type set)"
+	}
+
+	if m := missingMethod(V, Ti); m != nil {
+		return false, V.String() + " does not " + verb + " " + T.String() + " (missing method " + m.name + ")"
+	}
+
+	checkComparable := func() (bool, string) {
+		if !Tset.comparable || comparable(V) {
+			return true, ""
+		}
+		return false, V.String() + " does not " + verb + " comparable"
+	}
+	if Tset.terms.isAll() {
+		return checkComparable()
+	}
+
+	if Vi != nil {
+		if !Vi.typeSet().terms.subsetOf(Tset.terms) {
+			return false, V.String() + " does not " + verb + " " + T.String()
+		}
+		return checkComparable()
+	}
+	if !Tset.terms.includes(V) {
+		for _, t := range Tset.terms {
+			if !t.tilde && identical(t.typ, Vu) {
+				return false, V.String() + " does not " + verb + " " + T.String() +
+					" (possibly missing ~ for " + t.typ.String() + " in " + T.String() + ")"
+			}
+		}
+		return false, V.String() + " does not " + verb + " " + T.String() +
+			" (" + V.String() + " missing in " + Tset.terms.String() + ")"
+	}
+	return checkComparable()
+}
@@ -0,0 +1,215 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+)
+
+// labels checks the labels of the function body and the branch statements referring to them.
+// The labels have the whole body as scope, except the bodies of the function literals
+func (c *Checker) labels(body *ast.BlockStatement) {
+	all := map[string]*ast.LabeledStatement{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.LabeledStatement:
+			name := s.Label.Name
+			if name == "_" {
+				break
+			}
+			if alt := all[name]; alt != nil {
+				c.errorf(s.Label.Pos(), "label %s already declared\n\tprevious declaration at %s", name, alt.Label.Pos().ToString())
+				break
+			}
+			all[name] = s
+		}
+		return true
+	})
+
+	b := &labelBlocks{all: all, used: map[string]bool{}}
+	c.blockBranches(b, nil, body.List)
+	for name, s := range all {
+		if !b.used[name] {
+			c.errorf(s.Label.Pos(), "label %s declared and not used", name)
+		}
+	}
+}
+
+// labelBlocks holds the labels of a function body while its branch statements are checked
+type labelBlocks struct {
+	all     map[string]*ast.LabeledStatement // all labels of the body
+	used    map[string]bool                  // labels referred to by a branch statement
+	blocks  [][]ast.Statement                // statement lists enclosing the checked statement
+	index   []int                            // index of the checked statement in each of the blocks
+	targets []*ast.LabeledStatement          // labeled statements enclosing the checked statement
+}
+
+// blockBranches checks the branch statements of the statement list, the body of the labeled
+// statement target if it is not nil
+func (c *Checker) blockBranches(b *labelBlocks, target *ast.LabeledStatement, list []ast.Statement) {
+	b.blocks = append(b.blocks, list)
+	b.index = append(b.index, 0)
+	if target != nil {
+		b.targets = append(b.targets, target)
+	}
+	for i, s := range list {
+		b.index[len(b.index)-1] = i
+		c.stmtBranches(b, s)
+	}
+	b.blocks = b.blocks[:len(b.blocks)-1]
+	b.index = b.index[:len(b.index)-1]
+	if target != nil {
+		b.targets = b.targets[:len(b.targets)-1]
+	}
+}
+
+// stmtBranches checks the branch statements of s
+func (c *Checker) stmtBranches(b *labelBlocks, s ast.Statement) {
+	var target *ast.LabeledStatement
+	if l, isLabeled := s.(*ast.LabeledStatement); isLabeled {
+		if l.Stmt == nil {
+			return
+		}
+		target, s = l, l.Stmt
+	}
+
+	switch s := s.(type) {
+	case *ast.BranchStatement:
+		if s.Label == nil {
+			return
+		}
+		// a label is used by the valid branch statements, and by a goto into a block
+		name := s.Label.Name
+		switch s.Tok.Tok {
+		case tokens.BREAK:
+			if t := b.enclosingTarget(name); t == nil || !isBreakTarget(t.Stmt) {
+				c.errorf(s.Label.Pos(), "invalid break label %s", name)
+				return
+			}
+		case tokens.CONTINUE:
+			if t := b.enclosingTarget(name); t == nil || !isContinueTarget(t.Stmt) {
+				c.errorf(s.Label.Pos(), "invalid continue label %s", name)
+				return
+			}
+		case tokens.GOTO:
+			switch {
+			case b.all[name] == nil:
+				c.errorf(s.Label.Pos(), "label %s not declared", name)
+				return
+			case !b.visible(name):
+				c.errorf(s.Label.Pos(), "goto %s jumps into block", name)
+			default:
+				if pos := b.jumpedVar(name); pos.IsValid() {
+					c.errorf(s.Label.Pos(), "goto %s jumps over variable declaration at line %d", name, pos.Line)
+				}
+			}
+		}
+		b.used[name] = true
+
+	case *ast.BlockStatement:
+		c.blockBranches(b, nil, s.List)
+
+	case *ast.IfStatement:
+		c.blockBranches(b, nil, s.Body.List)
+		if s.Else != nil {
+			c.stmtBranches(b, s.Else)
+		}
+
+	case *ast.ForStatement:
+		c.blockBranches(b, target, s.Body.List)
+
+	case *ast.RangeStatement:
+		c.blockBranches(b, target, s.Body.List)
+
+	case *ast.SwitchStatement:
+		for _, clause := range s.Body.List {
+			if clause, isClause := clause.(*ast.CaseClause); isClause {
+				c.blockBranches(b, target, clause.Body)
+			}
+		}
+	}
+}
+
+// enclosingTarget returns the enclosing labeled statement with the given label, or nil
+func (b *labelBlocks) enclosingTarget(name string) *ast.LabeledStatement {
+	for i := len(b.targets) - 1; i >= 0; i-- {
+		if t := b.targets[i]; t.Label.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// visible reports whether the statement with the given label is in an enclosing statement list,
+// where a goto may jump to it
+func (b *labelBlocks) visible(name string) bool {
+	for _, list := range b.blocks {
+		for _, s := range list {
+			if l, isLabeled := s.(*ast.LabeledStatement); isLabeled && l.Label.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jumpedVar returns the position of the last variable declaration between the checked statement
+// and the following statement with the given label in an enclosing statement list, or an invalid
+// position if a goto to the label jumps over no declaration
+func (b *labelBlocks) jumpedVar(name string) tokens.Position {
+	for i, list := range b.blocks {
+		for j, s := range list {
+			if l, isLabeled := s.(*ast.LabeledStatement); isLabeled && l.Label.Name == name {
+				var pos tokens.Position
+				for k := b.index[i] + 1; k < j; k++ {
+					if p := varDecl(list[k]); p.IsValid() {
+						pos = p
+					}
+				}
+				return pos
+			}
+		}
+	}
+	return tokens.Position{}
+}
+
+// varDecl returns the position of s if it declares variables, or an invalid position
+func varDecl(s ast.Statement) tokens.Position {
+	if l, isLabeled := s.(*ast.LabeledStatement); isLabeled && l.Stmt != nil {
+		s = l.Stmt
+	}
+	switch s := s.(type) {
+	case *ast.DeclarationStatement:
+		if d, isGeneric := s.Decl.(*ast.GenericDeclaration); isGeneric && d.Token == tokens.VAR {
+			return d.TokPos
+		}
+	case *ast.AssignStatement:
+		if s.Tok.Tok == tokens.DEFINE {
+			return s.Pos()
+		}
+	}
+	return tokens.Position{}
+}
+
+func isBreakTarget(s ast.Statement) bool {
+	switch s.(type) {
+	case *ast.ForStatement, *ast.RangeStatement, *ast.SwitchStatement:
+		return true
+	}
+	return false
+}
+
+func isContinueTarget(s ast.Statement) bool {
+	switch s.(type) {
+	case *ast.ForStatement, *ast.RangeStatement:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+	"strconv"
+)
+
+// Object is a named language entity: a package name, constant, type name, variable,
+// function, built-in function or nil
+type Object interface {
+	Name() string         // package local object name
+	Type() Type           // object type
+	Pos() tokens.Position // position of the object identifier in the declaration; invalid for predeclared objects
+	String() string       // the kind, name and type of the object
+}
+
+type object struct {
+	name string
+	typ  Type
+	pos  tokens.Position
+}
+
+func (obj *object) Name() string         { return obj.name }
+func (obj *object) Type() Type           { return obj.typ }
+func (obj *object) Pos() tokens.Position { return obj.pos }
+
+// PkgName is the name of an imported package. The imported packages are not loaded:
+// the selectors of their members denote unknown objects
+type PkgName struct {
+	object
+	path string
+	used bool
+}
+
+func (obj *PkgName) Path() string { return obj.path }
+func (obj *PkgName) String() string {
+	return "package " + obj.name + " (" + strconv.Quote(obj.path) + ")"
+}
+
+// Const is a declared constant
+type Const struct {
+	object
+	val constant.Value
+}
+
+// NewConst returns a new constant with the value val
+func NewConst(pos tokens.Position, name string, typ Type, val constant.Value) *Const {
+	return &Const{object{name: name, typ: typ, pos: pos}, val}
+}
+
+// Val returns the value of the constant
+func (obj *Const) Val() constant.Value { return obj.val }
+
+func (obj *Const) String() string {
+	s := "const " + obj.name + " " + typeString(obj.typ)
+	if obj.val != nil {
+		s += " = " + obj.val.String()
+	}
+	return s
+}
+
+// TypeName is the name of a defined type or of an alias
+type TypeName struct {
+	object
+}
+
+// NewTypeName returns a new type name denoting the given typ
+func NewTypeName(pos tokens.Position, name string, typ Type) *TypeName {
+	return &TypeName{object{name: name, typ: typ, pos: pos}}
+}
+
+func (obj *TypeName) String() string {
+	if obj.typ == nil {
+		return "type " + obj.name
+	}
+	return "type " + obj.name + " " + typeString(obj.typ.Underlying())
+}
+
+// Var is a variable, a parameter, a result or a struct field
+type Var struct {
+	object
+	field    bool // the variable is a struct field
+	embedded bool // the variable is an embedded struct field, named after its type
+	used     bool // the variable is used, for the check of unused variables
+}
+
+// NewVar returns a new variable
+func NewVar(pos tokens.Position, name string, typ Type) *Var {
+	return &Var{object: object{name: name, typ: typ, pos: pos}}
+}
+
+// NewField returns a new struct field
+func NewField(pos tokens.Position, name string, typ Type, embedded bool) *Var {
+	return &Var{object: object{name: name, typ: typ, pos: pos}, field: true, embedded: embedded}
+}
+
+func (obj *Var) IsField() bool  { return obj.field }
+func (obj *Var) Embedded() bool { return obj.embedded }
+func (obj *Var) String() string {
+	kind := "var "
+	if obj.field {
+		kind = "field "
+	}
+	return kind + obj.name + " " + typeString(obj.typ)
+}
+
+// Func is a declared function; its type is a *Signature
+type Func struct {
+	object
+}
+
+func (obj *Func) String() string { return "func " + obj.name + typeString(obj.typ)[len("func"):] }
+
+// Builtin is a built-in function; it has no type
+type Builtin struct {
+	object
+	id builtinId
+}
+
+func (obj *Builtin) String() string { return "builtin " + obj.name }
+
+// Nil is the predeclared value nil
+type Nil struct {
+	object
+}
+
+func (obj *Nil) String() string { return "nil" }
+
+func typeString(t Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
+
+// Package describes a type-checked package
+type Package struct {
+	name    string
+	objects map[string]Object // package level objects
+}
+
+func (pkg *Package) Name() string { return pkg.name }
+
+// Lookup returns the package level object with the given name, or nil
+func (pkg *Package) Lookup(name string) Object { return pkg.objects[name] }
+
+// Info holds the results of type checking
+type Info struct {
+	// Types maps the expressions, identifiers included, to their types and the
+	// kinds of their values. The declaring identifiers are not recorded
+	Types map[ast.Expression]TypeAndValue
+
+	// Defs maps the identifiers to the objects they define, including the
+	// package names, struct fields and parameters; the package name of the package
+	// clause and the blank identifier on the left side of an assignment map to nil
+	Defs map[*ast.Ident]Object
+
+	// Uses maps the identifiers to the objects they denote, including the
+	// selected struct fields and the keys of struct literals
+	Uses map[*ast.Ident]Object
+}
+
+// TypeOf returns the type of the expression e, or nil if it is not known
+func (info *Info) TypeOf(e ast.Expression) Type {
+	if t, found := info.Types[e]; found {
+		return t.Type
+	}
+	if id, isIdent := e.(*ast.Ident); isIdent {
+		if obj := info.ObjectOf(id); obj != nil {
+			return obj.Type()
+		}
+	}
+	return nil
+}
+
+// ObjectOf returns the object denoted by the identifier id, or nil if it is not known
+func (info *Info) ObjectOf(id *ast.Ident) Object {
+	if obj := info.Defs[id]; obj != nil {
+		return obj
+	}
+	return info.Uses[id]
+}
+
+// TypeAndValue reports the type of an expression, the kind of its value and the value
+// of a constant expression
+type TypeAndValue struct {
+	mode  operandMode
+	Type  Type
+	Value constant.Value
+}
+
+// IsVoid reports whether the expression is a call of a function without results
+func (tv TypeAndValue) IsVoid() bool { return tv.mode == novalue }
+
+// IsType reports whether the expression denotes a type
+func (tv TypeAndValue) IsType() bool { return tv.mode == typexpr }
+
+// IsBuiltin reports whether the expression denotes a built-in function
+func (tv TypeAndValue) IsBuiltin() bool { return tv.mode == builtin }
+
+// IsValue reports whether the expression is a value; constants are values
+func (tv TypeAndValue) IsValue() bool {
+	switch tv.mode {
+	case constant_, variable, value:
+		return true
+	}
+	return false
+}
+
+// IsConstant reports whether the expression is a constant
+func (tv TypeAndValue) IsConstant() bool { return tv.mode == constant_ }
+
+// IsNil reports whether the expression is the predeclared nil
+func (tv TypeAndValue) IsNil() bool {
+	return tv.mode == value && tv.Type == Typ[UntypedNil]
+}
+
+// Addressable reports whether the expression is addressable
+func (tv TypeAndValue) Addressable() bool { return tv.mode == variable }
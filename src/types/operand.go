@@ -0,0 +1,121 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+)
+
+// operandMode describes the kind of an operand
+type operandMode byte
+
+const (
+	invalid   operandMode = iota // operand is invalid
+	novalue                      // operand represents no value (result of a function call without results)
+	builtin                      // operand is a built-in function
+	typexpr                      // operand is a type
+	constant_                    // operand is a constant; the operand's typ is a Basic type
+	variable                     // operand is an addressable variable
+	value                        // operand is a computed value
+)
+
+var operandModeString = [...]string{
+	invalid:   "invalid operand",
+	novalue:   "no value",
+	builtin:   "built-in",
+	typexpr:   "type",
+	constant_: "constant",
+	variable:  "variable",
+	value:     "value",
+}
+
+// operand represents an intermediate value during type checking: an expression
+// with the mode and type computed for it
+type operand struct {
+	mode operandMode
+	expr ast.Expression
+	typ  Type
+	val  constant.Value // the value if mode == constant_
+	id   builtinId      // the built-in function if mode == builtin
+}
+
+// String returns the expression of x followed by a description of its mode and type,
+// in the form used by the gc compiler:
+//
+//	x (invalid operand)
+//	f() (no value)
+//	len (built-in)
+//	int (type)
+//	1 (untyped int constant)
+//	1 << 10 (untyped int constant 1024)
+//	c (constant 1 of type T)
+//	x (variable of type T)
+//	y (variable of type P constrained by C)
+//	x == y (untyped bool value)
+//	f() (value of type T)
+func (x *operand) String() string {
+	expr := ""
+	if x.expr != nil {
+		expr = exprString(x.expr)
+	}
+	if x.mode == invalid || x.mode == novalue || x.mode == builtin || x.mode == typexpr {
+		return expr + " (" + operandModeString[x.mode] + ")"
+	}
+	mode := operandModeString[x.mode]
+	if x.mode == constant_ && x.val != nil {
+		// the value is shown unless it is the expression itself
+		if s := x.val.String(); s != expr {
+			mode += " " + s
+		}
+	}
+	if isUntyped(x.typ) {
+		if x.typ == Typ[UntypedNil] {
+			return expr + " (untyped nil)"
+		}
+		return expr + " (" + x.typ.String() + " " + mode + ")"
+	}
+	typ := x.typ.String()
+	if tpar, isTypeParam := x.typ.(*TypeParam); isTypeParam {
+		typ += " constrained by " + typeString(tpar.bound)
+	}
+	return expr + " (" + mode + " of type " + typ + ")"
+}
+
+// setConst sets x to the untyped constant of a basic literal
+func (x *operand) setConst(lit *ast.BasicLiteral) {
+	var kind BasicKind
+	switch lit.Type {
+	case tokens.INT:
+		kind = UntypedInt
+	case tokens.FLOAT:
+		kind = UntypedFloat
+	case tokens.IMAG:
+		kind = UntypedComplex
+	case tokens.CHAR:
+		kind = UntypedRune
+	case tokens.STRING:
+		kind = UntypedString
+	default:
+		x.mode = invalid
+		return
+	}
+	val := constant.MakeFromLiteral(lit.Value.Lit, lit.Type)
+	if val.Kind() == constant.Unknown {
+		x.mode = invalid
+		return
+	}
+	x.mode = constant_
+	x.typ = Typ[kind]
+	x.val = val
+}
+
+// isNil reports whether x is the predeclared nil
+func (x *operand) isNil() bool {
+	return x.mode == value && x.typ == Typ[UntypedNil]
+}
@@ -0,0 +1,272 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/printer"
+	"strings"
+)
+
+// under returns the underlying type of t
+func under(t Type) Type {
+	return t.Underlying()
+}
+
+// is reports whether the underlying type of t is a basic type with one of the properties of info
+func is(t Type, info BasicInfo) bool {
+	b, isBasic := under(t).(*Basic)
+	return isBasic && b.info&info != 0
+}
+
+func isBoolean(t Type) bool   { return is(t, IsBoolean) }
+func isInteger(t Type) bool   { return is(t, IsInteger) }
+func isUnsigned(t Type) bool  { return is(t, IsUnsigned) }
+func isFloat(t Type) bool     { return is(t, IsFloat) }
+func isComplex(t Type) bool   { return is(t, IsComplex) }
+func isNumeric(t Type) bool   { return is(t, IsNumeric) }
+func isString(t Type) bool    { return is(t, IsString) }
+func isOrdered(t Type) bool   { return is(t, IsOrdered) }
+func isConstType(t Type) bool { return is(t, IsConstType) }
+
+// isUntyped reports whether t is the type of an untyped value
+func isUntyped(t Type) bool {
+	b, isBasic := t.(*Basic)
+	return isBasic && b.info&IsUntyped != 0
+}
+
+// isTyped reports whether t is typed
+func isTyped(t Type) bool { return !isUntyped(t) }
+
+// isValid reports whether t is a valid type
+func isValid(t Type) bool { return under(t) != Typ[Invalid] }
+
+// allBasic reports whether t is a basic type with one of the properties of info, or a type
+// parameter all of whose types are
+func allBasic(t Type, info BasicInfo) bool {
+	if tp, isTypeParam := t.(*TypeParam); isTypeParam {
+		return tp.iface().typeSet().underIs(func(u Type) bool { return u != nil && is(u, info) })
+	}
+	return is(t, info)
+}
+
+func allBoolean(t Type) bool         { return allBasic(t, IsBoolean) }
+func allInteger(t Type) bool         { return allBasic(t, IsInteger) }
+func allNumeric(t Type) bool         { return allBasic(t, IsNumeric) }
+func allNumericOrString(t Type) bool { return allBasic(t, IsNumeric|IsString) }
+func allOrdered(t Type) bool         { return allBasic(t, IsOrdered) }
+
+// isInterface reports whether the underlying type of t is an interface; a type parameter
+// is not an interface
+func isInterface(t Type) bool {
+	_, isIface := under(t).(*Interface)
+	return isIface && !isTypeParam(t)
+}
+
+// isTypeParam reports whether t is a type parameter
+func isTypeParam(t Type) bool {
+	_, isTypeParam := t.(*TypeParam)
+	return isTypeParam
+}
+
+// isGeneric reports whether t is a generic named type that is not instantiated
+func isGeneric(t Type) bool {
+	named, isNamed := t.(*Named)
+	return isNamed && named.orig == nil && named.tparams != nil
+}
+
+// coreType returns the underlying type of t, or for a type parameter, the underlying type shared
+// by all the types of its type set, or nil if there is no such type
+func coreType(t Type) Type {
+	tp, isTypeParam := t.(*TypeParam)
+	if !isTypeParam {
+		return under(t)
+	}
+	var core Type
+	if !tp.iface().typeSet().underIs(func(u Type) bool {
+		if u == nil {
+			return false
+		}
+		if core != nil && !identical(core, u) {
+			return false
+		}
+		core = u
+		return true
+	}) {
+		return nil
+	}
+	return core
+}
+
+// hasName reports whether t is a named or a predeclared type, or a type parameter
+func hasName(t Type) bool {
+	switch t.(type) {
+	case *Basic, *Named, *TypeParam:
+		return true
+	}
+	return false
+}
+
+// hasNil reports whether nil can be assigned to a value of type t
+func hasNil(t Type) bool {
+	if tp, isTypeParam := t.(*TypeParam); isTypeParam {
+		return tp.iface().typeSet().underIs(func(u Type) bool { return u != nil && hasNil(u) })
+	}
+	switch under(t).(type) {
+	case *Slice, *Pointer, *Signature, *Interface:
+		return true
+	}
+	return under(t) == Typ[UntypedNil]
+}
+
+// comparable reports whether values of type t are comparable
+func comparable(t Type) bool {
+	if tp, isTypeParam := t.(*TypeParam); isTypeParam {
+		return tp.iface().IsComparable()
+	}
+	switch t := under(t).(type) {
+	case *Basic:
+		return t.kind != UntypedNil
+	case *Pointer, *Interface:
+		return true
+	case *Struct:
+		for _, f := range t.fields {
+			if !comparable(f.typ) {
+				return false
+			}
+		}
+		return true
+	case *Array:
+		return comparable(t.elem)
+	}
+	return false
+}
+
+// identical reports whether x and y are identical types
+func identical(x, y Type) bool {
+	if x == y {
+		return true
+	}
+	switch x := x.(type) {
+	case *Basic:
+		// byte and uint8, rune and int32 are distinct *Basic values of the same kind
+		if y, isBasic := y.(*Basic); isBasic {
+			return x.kind == y.kind
+		}
+	case *Array:
+		if y, isArray := y.(*Array); isArray {
+			return x.len == y.len && identical(x.elem, y.elem)
+		}
+	case *Slice:
+		if y, isSlice := y.(*Slice); isSlice {
+			return identical(x.elem, y.elem)
+		}
+	case *Pointer:
+		if y, isPointer := y.(*Pointer); isPointer {
+			return identical(x.base, y.base)
+		}
+	case *Struct:
+		if y, isStruct := y.(*Struct); isStruct && len(x.fields) == len(y.fields) {
+			for i, f := range x.fields {
+				g := y.fields[i]
+				if f.embedded != g.embedded || f.name != g.name || x.Tag(i) != y.Tag(i) || !identical(f.typ, g.typ) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Tuple:
+		if y, isTuple := y.(*Tuple); isTuple && x.Len() == y.Len() {
+			for i := 0; i < x.Len(); i++ {
+				if !identical(x.At(i).typ, y.At(i).typ) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Signature:
+		if y, isSig := y.(*Signature); isSig {
+			return x.variadic == y.variadic && identical(x.params, y.params) && identical(x.results, y.results)
+		}
+	case *Interface:
+		// interfaces are identical if they have the same type set
+		if y, isIface := y.(*Interface); isIface {
+			xs, ys := x.typeSet(), y.typeSet()
+			if xs.comparable != ys.comparable || len(xs.methods) != len(ys.methods) {
+				return false
+			}
+			for _, m := range xs.methods {
+				if n := lookupMethod(y, m.name); n == nil || !identical(m.typ, n.typ) {
+					return false
+				}
+			}
+			return xs.terms.subsetOf(ys.terms) && ys.terms.subsetOf(xs.terms)
+		}
+	case *Named:
+		// the instances of a generic type are identical if their type arguments are
+		if y, isNamed := y.(*Named); isNamed && x.orig != nil && x.orig == y.orig {
+			return identicalList(x.targs, y.targs)
+		}
+	}
+	return false
+}
+
+// lookupMethod returns the method of t with the given name, or nil
+func lookupMethod(t *Interface, name string) *Func {
+	for _, m := range t.typeSet().methods {
+		if m.name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// missingMethod returns the first method of the interface t that the type v does not have,
+// or nil if v has all the methods of t. The types of the subset have methods only if they are
+// interfaces or type parameters, whose methods are those of their constraints
+func missingMethod(v Type, t *Interface) *Func {
+	vi, _ := under(v).(*Interface)
+	for _, m := range t.typeSet().methods {
+		if vi == nil {
+			return m
+		}
+		if n := lookupMethod(vi, m.name); n == nil || !identical(m.typ, n.typ) {
+			return m
+		}
+	}
+	return nil
+}
+
+// defaultType returns the default type of an untyped type, or t itself if it is typed
+func defaultType(t Type) Type {
+	if b, isBasic := t.(*Basic); isBasic {
+		switch b.kind {
+		case UntypedBool:
+			return Typ[Bool]
+		case UntypedInt:
+			return Typ[Int]
+		case UntypedRune:
+			return universe["rune"].Type()
+		case UntypedFloat:
+			return Typ[Float64]
+		case UntypedComplex:
+			return Typ[Complex128]
+		case UntypedString:
+			return Typ[String]
+		}
+	}
+	return t
+}
+
+// exprString returns the source text of x, as printed by the printer
+func exprString(x ast.Expression) string {
+	b, err := printer.Source(x)
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,123 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/tokens"
+)
+
+// isTerminating reports whether s is a terminating statement, after which the function body
+// cannot continue; label is the label of s, or ""
+func (c *Checker) isTerminating(s ast.Statement, label string) bool {
+	switch s := s.(type) {
+	case *ast.LabeledStatement:
+		return s.Stmt != nil && c.isTerminating(s.Stmt, s.Label.Name)
+
+	case *ast.ExpressionStatement:
+		// a call of the built-in panic
+		if call, isCall := unparen(s.X).(*ast.CallExpression); isCall {
+			if ident, isIdent := unparen(call.Function).(*ast.Ident); isIdent {
+				b, isBuiltin := c.lookup(ident).(*Builtin)
+				return isBuiltin && b.id == _Panic
+			}
+		}
+
+	case *ast.ReturnStatement:
+		return true
+
+	case *ast.BranchStatement:
+		return s.Tok.Tok == tokens.GOTO || s.Tok.Tok == tokens.FALLTHROUGH
+
+	case *ast.BlockStatement:
+		return c.isTerminatingList(s.List, "")
+
+	case *ast.IfStatement:
+		return s.Else != nil && c.isTerminating(s.Body, "") && c.isTerminating(s.Else, "")
+
+	case *ast.SwitchStatement:
+		return c.isTerminatingSwitch(s.Body, label)
+
+	case *ast.ForStatement:
+		// a range loop is never terminating
+		return s.Cond == nil && !hasBreak(s.Body, label, true)
+	}
+	return false
+}
+
+// isTerminatingList reports whether the statement list ends in a terminating statement
+func (c *Checker) isTerminatingList(list []ast.Statement, label string) bool {
+	if len(list) == 0 {
+		return false
+	}
+	return c.isTerminating(list[len(list)-1], label)
+}
+
+// isTerminatingSwitch reports whether the switch with the given body and label has a default
+// case and all its cases end in a terminating statement without breaking out of the switch
+func (c *Checker) isTerminatingSwitch(body *ast.BlockStatement, label string) bool {
+	hasDefault := false
+	for _, s := range body.List {
+		clause, isClause := s.(*ast.CaseClause)
+		if !isClause {
+			return false
+		}
+		if clause.List == nil {
+			hasDefault = true
+		}
+		if !c.isTerminatingList(clause.Body, "") || hasBreakList(clause.Body, label, true) {
+			return false
+		}
+	}
+	return hasDefault
+}
+
+// hasBreak reports whether s is or contains a break out of the statement with the given label,
+// or, if implicit is set, out of the innermost enclosing for or switch statement
+func hasBreak(s ast.Statement, label string, implicit bool) bool {
+	switch s := s.(type) {
+	case *ast.LabeledStatement:
+		return s.Stmt != nil && hasBreak(s.Stmt, label, implicit)
+
+	case *ast.BranchStatement:
+		if s.Tok.Tok == tokens.BREAK {
+			if s.Label == nil {
+				return implicit
+			}
+			return s.Label.Name == label
+		}
+
+	case *ast.BlockStatement:
+		return hasBreakList(s.List, label, implicit)
+
+	case *ast.IfStatement:
+		return hasBreak(s.Body, label, implicit) || s.Else != nil && hasBreak(s.Else, label, implicit)
+
+	case *ast.CaseClause:
+		return hasBreakList(s.Body, label, implicit)
+
+	// a break without a label inside a nested for or switch breaks out of the nested statement
+	case *ast.SwitchStatement:
+		return label != "" && hasBreak(s.Body, label, false)
+
+	case *ast.ForStatement:
+		return label != "" && hasBreak(s.Body, label, false)
+
+	case *ast.RangeStatement:
+		return label != "" && hasBreak(s.Body, label, false)
+	}
+	return false
+}
+
+func hasBreakList(list []ast.Statement, label string, implicit bool) bool {
+	for _, s := range list {
+		if hasBreak(s, label, implicit) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+)
+
+// stmtContext tells which branch statements without a label are permitted in a statement
+type stmtContext uint
+
+const (
+	breakOk         stmtContext = 1 << iota // in a for or switch statement
+	continueOk                              // in a for statement
+	fallthroughOk                           // the last statement of a case clause but the final one
+	finalSwitchCase                         // the last statement of the final case clause
+)
+
+// funcBody checks the body of a function with the signature sig; a function with results
+// must end in a terminating statement
+func (c *Checker) funcBody(sig *Signature, body *ast.BlockStatement) {
+	if body == nil {
+		return
+	}
+	defer func(sig *Signature, locals []*Var, ctxt stmtContext) {
+		c.sig, c.locals, c.ctxt = sig, locals, ctxt
+	}(c.sig, c.locals, c.ctxt)
+	c.sig, c.locals, c.ctxt = sig, nil, 0
+	c.stmtList(body.List)
+	c.labels(body)
+	if sig.results.Len() > 0 && !c.isTerminating(body, "") {
+		c.errorf(body.RbracePos, "missing return")
+	}
+	c.usage()
+}
+
+// usage reports the variables declared in the function body that are never used; the
+// variables of a function literal are reported with its body
+func (c *Checker) usage() {
+	for _, v := range c.locals {
+		if !v.used {
+			c.errorf(v.pos, "declared and not used: %s", v.name)
+		}
+	}
+}
+
+func (c *Checker) stmtList(list []ast.Statement) {
+	for _, s := range list {
+		c.stmt(s)
+	}
+}
+
+// simpleStmt checks the optional initialization or post statement s
+func (c *Checker) simpleStmt(s ast.Statement) {
+	if s != nil {
+		c.stmt(s)
+	}
+}
+
+func (c *Checker) stmt(s ast.Statement) {
+	// a fallthrough is permitted in the statement s only, not in the statements it contains
+	ctxt := c.ctxt
+	defer func() { c.ctxt = ctxt }()
+	c.ctxt &^= fallthroughOk | finalSwitchCase
+
+	switch s := s.(type) {
+	case *ast.BadStatement:
+
+	case *ast.DeclarationStatement:
+		if d, isGeneric := s.Decl.(*ast.GenericDeclaration); isGeneric {
+			c.declStmt(d)
+		}
+
+	case *ast.ExpressionStatement:
+		var x operand
+		kind := c.rawExpr(&x, s.X, nil)
+		var msg string
+		switch x.mode {
+		default:
+			if kind == statement {
+				return
+			}
+			msg = "is not used"
+		case builtin:
+			msg = "must be called"
+		case typexpr:
+			msg = "is not an expression"
+		case invalid:
+			return
+		}
+		c.errorf(x.expr.Pos(), "%s %s", &x, msg)
+
+	case *ast.IncDecStatement:
+		T := c.lhsVar(s.X)
+		if T == nil {
+			c.errorf(s.X.Pos(), "cannot use _ as value")
+			return
+		}
+		if isValid(T) && !allNumeric(T) {
+			c.errorf(s.X.Pos(), "invalid operation: %s%s (non-numeric type %s)", s.X, s.Tok.Tok, T)
+		}
+
+	case *ast.AssignStatement:
+		switch s.Tok.Tok {
+		case tokens.DEFINE:
+			c.shortVarDecl(s)
+		case tokens.ASSIGN:
+			c.assignVars(s.Lhs, s.Rhs)
+		default:
+			c.opAssign(s)
+		}
+
+	case *ast.ReturnStatement:
+		c.returnStmt(s)
+
+	case *ast.BranchStatement:
+		if s.Label != nil {
+			return // checked with the labels of the function body
+		}
+		switch s.Tok.Tok {
+		case tokens.BREAK:
+			if ctxt&breakOk == 0 {
+				c.errorf(s.Pos(), "break is not in a loop, switch, or select")
+			}
+		case tokens.CONTINUE:
+			if ctxt&continueOk == 0 {
+				c.errorf(s.Pos(), "continue is not in a loop")
+			}
+		case tokens.FALLTHROUGH:
+			if ctxt&fallthroughOk == 0 {
+				msg := "fallthrough statement out of place"
+				if ctxt&finalSwitchCase != 0 {
+					msg = "cannot fallthrough final case in switch"
+				}
+				c.errorf(s.Pos(), "%s", msg)
+			}
+		default:
+			// goto: checked with the labels of the function body
+		}
+
+	case *ast.LabeledStatement:
+		if s.Stmt != nil {
+			c.ctxt = ctxt
+			c.stmt(s.Stmt)
+		}
+
+	case *ast.BlockStatement:
+		c.stmtList(s.List)
+
+	case *ast.IfStatement:
+		c.simpleStmt(s.Init)
+		var x operand
+		c.expr(&x, s.Cond)
+		if x.mode != invalid && !isBoolean(x.typ) {
+			c.errorf(s.Cond.Pos(), "non-boolean condition in if statement")
+		}
+		c.stmt(s.Body)
+		if s.Else != nil {
+			c.stmt(s.Else)
+		}
+
+	case *ast.ForStatement:
+		c.simpleStmt(s.Init)
+		if s.Cond != nil {
+			var x operand
+			c.expr(&x, s.Cond)
+			if x.mode != invalid && !isBoolean(x.typ) {
+				c.errorf(s.Cond.Pos(), "non-boolean condition in for statement")
+			}
+		}
+		c.simpleStmt(s.Post)
+		c.ctxt |= breakOk | continueOk
+		c.stmt(s.Body)
+
+	case *ast.RangeStatement:
+		c.rangeStmt(s)
+
+	case *ast.SwitchStatement:
+		c.switchStmt(s)
+
+	default:
+		c.errorf(s.Pos(), "invalid statement")
+	}
+}
+
+// returnStmt checks the results of s against the results of the enclosing function
+func (c *Checker) returnStmt(s *ast.ReturnStatement) {
+	results := c.sig.results
+	if len(s.Results) == 0 {
+		if results.Len() > 0 && results.At(0).name == "" {
+			c.errorf(s.Pos(), "not enough return values\n\thave ()\n\twant %s", typesSummary(varTypes(results), false))
+		}
+		// a naked return returns the named results
+		return
+	}
+	if results.Len() == 0 {
+		c.errorf(s.Results[0].Pos(), "too many return values\n\thave %s\n\twant ()", typesSummary(operandTypes(c.exprList(s.Results)), false))
+		return
+	}
+
+	xs := c.exprList(s.Results)
+	for _, x := range xs {
+		if x.mode == invalid {
+			return
+		}
+	}
+	if len(xs) != results.Len() {
+		pos := s.Return
+		qualifier := "not enough"
+		if len(xs) > results.Len() {
+			pos = xs[results.Len()].expr.Pos()
+			qualifier = "too many"
+		} else if len(xs) > 0 {
+			pos = xs[len(xs)-1].expr.Pos()
+		}
+		c.errorf(pos, "%s return values\n\thave %s\n\twant %s",
+			qualifier, typesSummary(operandTypes(xs), false), typesSummary(varTypes(results), false))
+		return
+	}
+	for i, x := range xs {
+		c.assignment(x, results.At(i).typ, "return statement")
+	}
+}
+
+// rangeStmt checks the range clause s; the iteration variables take the key and value
+// types of the ranged expression
+func (c *Checker) rangeStmt(s *ast.RangeStatement) {
+	var x operand
+	c.expr(&x, s.X)
+
+	var key, val Type
+	if x.mode != invalid {
+		key, val = rangeKeyVal(x.typ)
+		if key == nil {
+			c.errorf(x.expr.Pos(), "cannot range over %s", &x)
+		} else if val == nil && s.Value != nil {
+			c.errorf(s.Value.Pos(), "range over %s permits only one iteration variable", &x)
+		} else if isUntyped(x.typ) {
+			// an untyped constant, like range 10, has the default type
+			c.convertUntyped(&x, defaultType(x.typ))
+			key = x.typ
+		}
+	}
+
+	lhs := [2]ast.Expression{s.Key, s.Value}
+	rhs := [2]Type{key, val}
+	if s.Tok.Tok == tokens.DEFINE {
+		var vars []*Var
+		var idents []*ast.Ident
+		for i, e := range lhs {
+			if e == nil {
+				continue
+			}
+			ident, isIdent := e.(*ast.Ident)
+			if !isIdent {
+				c.errorf(e.Pos(), "non-name %s on left side of :=", e)
+				c.use(e)
+				continue
+			}
+			v := NewVar(ident.Pos(), ident.Name, rhs[i])
+			if rhs[i] == nil {
+				v.typ = Typ[Invalid]
+			}
+			vars = append(vars, v)
+			idents = append(idents, ident)
+		}
+		for i, v := range vars {
+			c.declareVar(idents[i], v)
+		}
+	} else if s.Key != nil {
+		for i, e := range lhs {
+			if e == nil {
+				continue
+			}
+			if rhs[i] == nil {
+				c.lhsVar(e)
+				continue
+			}
+			y := operand{mode: value, expr: e, typ: rhs[i]}
+			c.assignVar(e, &y)
+		}
+	}
+
+	c.ctxt |= breakOk | continueOk
+	c.stmt(s.Body)
+}
+
+// rangeKeyVal returns the key and value types of a range over a value of type typ, or nil
+// if typ cannot be ranged over; the value type is nil if there is no value
+func rangeKeyVal(typ Type) (key, val Type) {
+	switch t := coreType(typ).(type) {
+	case *Basic:
+		if isString(t) {
+			return Typ[Int], universe["rune"].Type()
+		}
+		if isInteger(t) {
+			return typ, nil
+		}
+	case *Array:
+		return Typ[Int], t.elem
+	case *Slice:
+		return Typ[Int], t.elem
+	case *Pointer:
+		if a, isArray := under(t.base).(*Array); isArray {
+			return Typ[Int], a.elem
+		}
+	}
+	return nil, nil
+}
+
+// switchStmt checks the expression switch s; the case values are compared with the tag
+func (c *Checker) switchStmt(s *ast.SwitchStatement) {
+	c.simpleStmt(s.Init)
+
+	var x operand
+	if s.Tag != nil {
+		c.expr(&x, s.Tag)
+		c.assignment(&x, nil, "switch expression")
+		if x.mode != invalid && !comparable(x.typ) && !hasNil(x.typ) {
+			c.errorf(x.expr.Pos(), "cannot switch on %s (%s is not comparable)", &x, x.typ)
+			x.mode = invalid
+		}
+	} else {
+		// a missing tag is the constant true, without an expression to show in errors
+		x.mode = constant_
+		x.typ = Typ[Bool]
+		x.val = constant.MakeBool(true)
+	}
+
+	seen := valueMap{}
+	var defaultClause *ast.CaseClause
+	ctxt := c.ctxt | breakOk
+	for i, st := range s.Body.List {
+		clause, isClause := st.(*ast.CaseClause)
+		if !isClause {
+			c.errorf(st.Pos(), "invalid AST: case clause expected")
+			continue
+		}
+		if clause.List == nil {
+			if defaultClause != nil {
+				c.errorf(clause.Pos(), "multiple defaults (first at %s)", defaultClause.Pos().ToString())
+			} else {
+				defaultClause = clause
+			}
+		}
+		c.caseValues(&x, clause.List, seen)
+		for j, st := range clause.Body {
+			c.ctxt = ctxt
+			if j == len(clause.Body)-1 {
+				if i < len(s.Body.List)-1 {
+					c.ctxt |= fallthroughOk
+				} else {
+					c.ctxt |= finalSwitchCase
+				}
+			}
+			c.stmt(st)
+		}
+	}
+}
+
+// valueMap maps the exact values of the constant cases of a switch to their positions and types
+type valueMap map[string][]valueType
+
+type valueType struct {
+	pos tokens.Position
+	typ Type
+}
+
+// caseValues compares the values of a case clause with the switch tag x and reports the
+// constant values already seen in another case
+func (c *Checker) caseValues(x *operand, values []ast.Expression, seen valueMap) {
+	for _, e := range values {
+		var v operand
+		c.expr(&v, e)
+		if x.mode == invalid || v.mode == invalid {
+			continue
+		}
+		c.convertUntyped(&v, x.typ)
+		if v.mode == invalid {
+			continue
+		}
+		res := v
+		c.comparison(&res, x, tokens.EQL, e.Pos(), true)
+		if res.mode == invalid || v.mode != constant_ {
+			continue
+		}
+		// the values of different types are distinct, as with an interface tag
+		key := v.val.ExactString()
+		for _, vt := range seen[key] {
+			if identical(v.typ, vt.typ) {
+				c.errorf(v.expr.Pos(), "duplicate case %s in expression switch\n\tprevious case at %s", &v, vt.pos.ToString())
+				break
+			}
+		}
+		seen[key] = append(seen[key], valueType{v.expr.Pos(), v.typ})
+	}
+}
@@ -0,0 +1,460 @@
+// Copyright 2011 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+// Package types declares the data types of the Go subset and implements the type checker
+// computing the type of every expression of a parsed package
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type is implemented by all types
+type Type interface {
+	// Underlying returns the underlying type of the type
+	Underlying() Type
+	// String returns the type as written in Go source
+	String() string
+}
+
+// BasicKind describes the kind of a basic type
+type BasicKind int
+
+const (
+	Invalid BasicKind = iota // type is invalid
+
+	// predeclared types
+	Bool
+	Int
+	Int8
+	Int16
+	Int32
+	Int64
+	Uint
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Uintptr
+	Float32
+	Float64
+	Complex64
+	Complex128
+	String
+
+	// types for untyped values
+	UntypedBool
+	UntypedInt
+	UntypedRune
+	UntypedFloat
+	UntypedComplex
+	UntypedString
+	UntypedNil
+
+	// aliases
+	Byte = Uint8
+	Rune = Int32
+)
+
+// BasicInfo is a set of flags describing properties of a basic type
+type BasicInfo int
+
+const (
+	IsBoolean BasicInfo = 1 << iota
+	IsInteger
+	IsUnsigned
+	IsFloat
+	IsComplex
+	IsString
+	IsUntyped
+
+	IsOrdered   = IsInteger | IsFloat | IsString
+	IsNumeric   = IsInteger | IsFloat | IsComplex
+	IsConstType = IsBoolean | IsNumeric | IsString
+)
+
+// Basic is a basic type
+type Basic struct {
+	kind BasicKind
+	info BasicInfo
+	name string
+}
+
+func (b *Basic) Kind() BasicKind        { return b.kind }
+func (b *Basic) Info() BasicInfo        { return b.info }
+func (b *Basic) Name() string           { return b.name }
+func (b *Basic) Underlying() Type       { return b }
+func (b *Basic) String() string         { return b.name }
+func (b *Basic) is(info BasicInfo) bool { return b.info&info != 0 }
+
+// Array is an array type; its length is negative if it is not known because of an error
+type Array struct {
+	len  int64
+	elem Type
+}
+
+// NewArray returns a new array type for the given element type and length
+func NewArray(elem Type, len int64) *Array { return &Array{len: len, elem: elem} }
+
+func (a *Array) Len() int64       { return a.len }
+func (a *Array) Elem() Type       { return a.elem }
+func (a *Array) Underlying() Type { return a }
+func (a *Array) String() string {
+	if a.len < 0 {
+		return "[?]" + a.elem.String()
+	}
+	return "[" + strconv.FormatInt(a.len, 10) + "]" + a.elem.String()
+}
+
+// Slice is a slice type
+type Slice struct {
+	elem Type
+}
+
+// NewSlice returns a new slice type for the given element type
+func NewSlice(elem Type) *Slice { return &Slice{elem: elem} }
+
+func (s *Slice) Elem() Type       { return s.elem }
+func (s *Slice) Underlying() Type { return s }
+func (s *Slice) String() string   { return "[]" + s.elem.String() }
+
+// Pointer is a pointer type
+type Pointer struct {
+	base Type
+}
+
+// NewPointer returns a new pointer type for the given element (base) type
+func NewPointer(elem Type) *Pointer { return &Pointer{base: elem} }
+
+func (p *Pointer) Elem() Type       { return p.base }
+func (p *Pointer) Underlying() Type { return p }
+func (p *Pointer) String() string   { return "*" + p.base.String() }
+
+// Struct is a struct type
+type Struct struct {
+	fields []*Var
+	tags   []string // field tags; nil if there are no tags
+}
+
+// NewStruct returns a new struct with the given fields and corresponding field tags.
+// If a field with index i has a tag, tags[i] must be that tag, but len(tags) may be
+// only as long as required to hold the tag with the largest index i
+func NewStruct(fields []*Var, tags []string) *Struct {
+	return &Struct{fields: fields, tags: tags}
+}
+
+func (s *Struct) NumFields() int   { return len(s.fields) }
+func (s *Struct) Field(i int) *Var { return s.fields[i] }
+func (s *Struct) Underlying() Type { return s }
+func (s *Struct) Tag(i int) string {
+	if i < len(s.tags) {
+		return s.tags[i]
+	}
+	return ""
+}
+func (s *Struct) String() string {
+	var b strings.Builder
+	b.WriteString("struct{")
+	for i, f := range s.fields {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if !f.embedded {
+			b.WriteString(f.name + " ")
+		}
+		b.WriteString(f.typ.String())
+		if tag := s.Tag(i); tag != "" {
+			b.WriteString(" " + strconv.Quote(tag))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Tuple is an ordered list of variables; a nil *Tuple is a valid (empty) tuple.
+// Tuples are used for the parameters and results of signatures and for the
+// values of calls returning several results
+type Tuple struct {
+	vars []*Var
+}
+
+// NewTuple returns a new tuple for the given variables
+func NewTuple(x ...*Var) *Tuple {
+	if len(x) > 0 {
+		return &Tuple{vars: x}
+	}
+	return nil
+}
+
+func (t *Tuple) Len() int {
+	if t != nil {
+		return len(t.vars)
+	}
+	return 0
+}
+func (t *Tuple) At(i int) *Var    { return t.vars[i] }
+func (t *Tuple) Underlying() Type { return t }
+func (t *Tuple) String() string {
+	var b strings.Builder
+	b.WriteString("(")
+	for i := 0; i < t.Len(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.vars[i].typ.String())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Signature is the type of a function. The last parameter of a variadic signature is of
+// slice type []T and stands for the parameters ...T. A generic function has type parameters,
+// the signatures of its instances have none
+type Signature struct {
+	tparams  []*TypeParam
+	params   *Tuple
+	results  *Tuple
+	variadic bool
+}
+
+// NewSignature returns a new function type for the given parameters and results
+func NewSignature(params, results *Tuple, variadic bool) *Signature {
+	return &Signature{params: params, results: results, variadic: variadic}
+}
+
+func (s *Signature) TypeParams() []*TypeParam { return s.tparams }
+func (s *Signature) Params() *Tuple           { return s.params }
+func (s *Signature) Results() *Tuple          { return s.results }
+func (s *Signature) Variadic() bool           { return s.variadic }
+func (s *Signature) Underlying() Type         { return s }
+func (s *Signature) String() string {
+	return "func" + tparamList(s.tparams) + s.signatureString()
+}
+
+// signatureString returns the parameter and result lists of s
+func (s *Signature) signatureString() string {
+	var b strings.Builder
+	b.WriteString("(")
+	for i := 0; i < s.params.Len(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		typ := s.params.At(i).typ
+		if s.variadic && i == s.params.Len()-1 {
+			b.WriteString("..." + typ.(*Slice).elem.String())
+		} else {
+			b.WriteString(typ.String())
+		}
+	}
+	b.WriteString(")")
+	switch n := s.results.Len(); {
+	case n == 1 && s.results.At(0).name == "":
+		b.WriteString(" " + s.results.At(0).typ.String())
+	case n > 0:
+		b.WriteString(" " + s.results.String())
+	}
+	return b.String()
+}
+
+// Interface is an interface type. Its type set, the set of the types implementing it, is
+// given by the methods it declares and the elements it embeds: interfaces, and in a constraint,
+// other types and unions of them. Every type implements an interface whose type set is not
+// restricted by methods or types
+type Interface struct {
+	methods   []*Func  // declared methods
+	embeddeds []Type   // embedded elements
+	implicit  bool     // the interface stands for a constraint written as a type, like [T ~int]
+	tset      *typeSet // type set, computed on first use
+}
+
+// NewInterface returns a new interface for the given methods
+func NewInterface(methods []*Func) *Interface {
+	return &Interface{methods: methods}
+}
+
+// NewInterfaceType returns a new interface for the given methods and embedded elements
+func NewInterfaceType(methods []*Func, embeddeds []Type) *Interface {
+	return &Interface{methods: methods, embeddeds: embeddeds}
+}
+
+func (t *Interface) NumMethods() int         { return len(t.typeSet().methods) }
+func (t *Interface) Method(i int) *Func      { return t.typeSet().methods[i] }
+func (t *Interface) NumEmbeddeds() int       { return len(t.embeddeds) }
+func (t *Interface) EmbeddedType(i int) Type { return t.embeddeds[i] }
+func (t *Interface) Empty() bool             { return t.typeSet().IsAll() }
+func (t *Interface) IsComparable() bool      { return t.typeSet().IsComparable() }
+func (t *Interface) IsMethodSet() bool       { return t.typeSet().IsMethodSet() }
+func (t *Interface) IsImplicit() bool        { return t.implicit }
+func (t *Interface) Underlying() Type        { return t }
+func (t *Interface) String() string {
+	if t == universeAny {
+		return "any"
+	}
+	if t.implicit && len(t.methods) == 0 && len(t.embeddeds) == 1 {
+		return t.embeddeds[0].String()
+	}
+	var b strings.Builder
+	b.WriteString("interface{")
+	for i, m := range t.methods {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(m.name + m.typ.(*Signature).signatureString())
+	}
+	for i, e := range t.embeddeds {
+		if i > 0 || len(t.methods) > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.String())
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Union is a union of terms, an element of a constraint interface
+type Union struct {
+	terms []*Term
+}
+
+// NewUnion returns a new union of the given terms
+func NewUnion(terms []*Term) *Union { return &Union{terms: terms} }
+
+func (u *Union) Len() int         { return len(u.terms) }
+func (u *Union) Term(i int) *Term { return u.terms[i] }
+func (u *Union) Underlying() Type { return u }
+func (u *Union) String() string {
+	list := make([]string, len(u.terms))
+	for i, t := range u.terms {
+		list[i] = t.String()
+	}
+	return strings.Join(list, " | ")
+}
+
+// Term is a term of a union: the type typ, or with tilde set, all the types whose underlying
+// type is typ
+type Term struct {
+	tilde bool
+	typ   Type
+}
+
+// NewTerm returns a new union term
+func NewTerm(tilde bool, typ Type) *Term { return &Term{tilde: tilde, typ: typ} }
+
+func (t *Term) Tilde() bool { return t.tilde }
+func (t *Term) Type() Type  { return t.typ }
+func (t *Term) String() string {
+	if t.tilde {
+		return "~" + t.typ.String()
+	}
+	return t.typ.String()
+}
+
+// Named is a named (defined) type. A generic named type has type parameters, and its instances
+// for type arguments are the named types whose underlying type is the underlying type of the
+// generic type with the type parameters replaced by the arguments
+type Named struct {
+	obj        *TypeName
+	underlying Type         // nil while the declaration of the type is checked or an instance not expanded
+	tparams    []*TypeParam // type parameters of a generic type
+	orig       *Named       // generic type of an instance; nil otherwise
+	targs      []Type       // type arguments of an instance
+	instances  []*Named     // instances of a generic type
+}
+
+// NewNamed returns a new named type for the given type name and underlying type
+func NewNamed(obj *TypeName, underlying Type) *Named {
+	t := &Named{obj: obj, underlying: underlying}
+	if obj.typ == nil {
+		obj.typ = t
+	}
+	return t
+}
+
+func (t *Named) Obj() *TypeName           { return t.obj }
+func (t *Named) TypeParams() []*TypeParam { return t.tparams }
+func (t *Named) TypeArgs() []Type         { return t.targs }
+
+// Origin returns the generic type of an instance, or t itself
+func (t *Named) Origin() *Named {
+	if t.orig != nil {
+		return t.orig
+	}
+	return t
+}
+
+func (t *Named) Underlying() Type {
+	if t.underlying == nil && t.orig != nil && t.orig.underlying != nil {
+		// expand the instance once the generic type is declared
+		t.underlying = Typ[Invalid]
+		t.underlying = subst(t.orig.underlying, makeSubstMap(t.orig.tparams, t.targs))
+	}
+	if t.underlying == nil {
+		return Typ[Invalid]
+	}
+	return t.underlying
+}
+func (t *Named) String() string {
+	if t.targs != nil {
+		return t.obj.name + "[" + typeListString(t.targs) + "]"
+	}
+	return t.obj.name
+}
+
+// TypeParam is a type parameter of a generic type or function. Its underlying type is the
+// interface of its constraint
+type TypeParam struct {
+	obj   *TypeName
+	index int  // index in the type parameter list
+	bound Type // constraint; nil while it is checked
+}
+
+// NewTypeParam returns a new type parameter for the given type name and constraint
+func NewTypeParam(obj *TypeName, constraint Type) *TypeParam {
+	t := &TypeParam{obj: obj, index: -1, bound: constraint}
+	if obj.typ == nil {
+		obj.typ = t
+	}
+	return t
+}
+
+func (t *TypeParam) Obj() *TypeName   { return t.obj }
+func (t *TypeParam) Index() int       { return t.index }
+func (t *TypeParam) Constraint() Type { return t.bound }
+func (t *TypeParam) Underlying() Type { return t.iface() }
+func (t *TypeParam) String() string   { return t.obj.name }
+
+// iface returns the interface of the constraint of t
+func (t *TypeParam) iface() *Interface {
+	if t.bound != nil {
+		if iface, isIface := under(t.bound).(*Interface); isIface {
+			return iface
+		}
+	}
+	return universeAny
+}
+
+// typeListString returns the types of list separated by commas
+func typeListString(list []Type) string {
+	s := make([]string, len(list))
+	for i, t := range list {
+		s[i] = t.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// tparamList returns the type parameters with their constraints in brackets, or "" if
+// there are none
+func tparamList(tparams []*TypeParam) string {
+	if len(tparams) == 0 {
+		return ""
+	}
+	s := make([]string, len(tparams))
+	for i, t := range tparams {
+		s[i] = t.obj.name + " " + typeString(t.bound)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
@@ -0,0 +1,390 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import "strings"
+
+// typeSet is the type set of an interface: the types that have its methods, are comparable if
+// comparable is set, and are included in its terms
+type typeSet struct {
+	comparable bool     // the types must be comparable
+	methods    []*Func  // all methods, the declared and the embedded ones
+	terms      termlist // types of the set; allTermlist if not restricted to particular types
+}
+
+// IsAll reports whether s is the set of all types
+func (s *typeSet) IsAll() bool {
+	return !s.comparable && len(s.methods) == 0 && s.terms.isAll()
+}
+
+// IsEmpty reports whether s contains no types
+func (s *typeSet) IsEmpty() bool { return s.terms.isEmpty() }
+
+// IsMethodSet reports whether s is described by its methods only
+func (s *typeSet) IsMethodSet() bool { return !s.comparable && s.terms.isAll() }
+
+// IsComparable reports whether all types of s are comparable
+func (s *typeSet) IsComparable() bool {
+	if s.terms.isAll() {
+		return s.comparable
+	}
+	for _, t := range s.terms {
+		if !comparable(t.typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTerms reports whether s is restricted to particular types
+func (s *typeSet) hasTerms() bool { return !s.terms.isEmpty() && !s.terms.isAll() }
+
+// underIs reports whether f holds for the underlying types of all the terms of s, or for nil
+// if s is not restricted to particular types
+func (s *typeSet) underIs(f func(Type) bool) bool {
+	if !s.hasTerms() {
+		return f(nil)
+	}
+	for _, t := range s.terms {
+		if !f(under(t.typ)) {
+			return false
+		}
+	}
+	return true
+}
+
+// allTerms reports whether f holds for the types of all the terms of the type set of the type
+// parameter t; it does not if the set is not restricted to particular types
+func (t *TypeParam) allTerms(f func(Type) bool) bool {
+	s := t.iface().typeSet()
+	if !s.hasTerms() {
+		return false
+	}
+	for _, term := range s.terms {
+		if !f(term.typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeSet returns the type set of t, computing it on first use
+func (t *Interface) typeSet() *typeSet {
+	if t.tset == nil {
+		computeInterfaceTypeSet(t)
+	}
+	return t.tset
+}
+
+// computeInterfaceTypeSet computes the type set of t: the intersection of the sets of its
+// methods and of its embedded elements. The errors in the elements are reported when the
+// interface type is checked
+func computeInterfaceTypeSet(t *Interface) *typeSet {
+	if t.tset != nil {
+		return t.tset
+	}
+	// a set for an interface embedding itself through an invalid declaration
+	t.tset = &typeSet{terms: allTermlist}
+
+	var methods []*Func
+	addMethod := func(m *Func) {
+		for _, n := range methods {
+			if n.name == m.name {
+				return
+			}
+		}
+		methods = append(methods, m)
+	}
+	for _, m := range t.methods {
+		addMethod(m)
+	}
+
+	terms, comparable := allTermlist, false
+	for _, e := range t.embeddeds {
+		var eterms termlist
+		ecomparable := false
+		switch u := under(e).(type) {
+		case *Interface:
+			s := computeInterfaceTypeSet(u)
+			for _, m := range s.methods {
+				addMethod(m)
+			}
+			eterms, ecomparable = s.terms, s.comparable
+		case *Union:
+			eterms = computeUnionTypeSet(u).terms
+		default:
+			if !isValid(u) {
+				continue
+			}
+			eterms = termlist{{false, e}}
+		}
+		terms, comparable = intersectTermLists(terms, comparable, eterms, ecomparable)
+	}
+	t.tset.comparable = comparable
+	t.tset.methods = methods
+	t.tset.terms = terms
+	return t.tset
+}
+
+// intersectTermLists returns the intersection of the term lists x and y, where xcomp and ycomp
+// tell whether the types of the lists must be comparable. The result is reduced to the
+// comparable types if the lists are restricted to particular types
+func intersectTermLists(x termlist, xcomp bool, y termlist, ycomp bool) (termlist, bool) {
+	terms := x.intersect(y)
+	comp := xcomp || ycomp
+	if comp && !terms.isAll() {
+		var r termlist
+		for _, t := range terms {
+			if comparable(t.typ) {
+				r = append(r, t)
+			}
+		}
+		terms, comp = r, false
+	}
+	return terms, comp
+}
+
+// computeUnionTypeSet returns the type set of the union u, the union of its terms
+func computeUnionTypeSet(u *Union) *typeSet {
+	var terms termlist
+	for _, t := range u.terms {
+		var tterms termlist
+		if iface, isIface := under(t.typ).(*Interface); isIface && !t.tilde {
+			tterms = computeInterfaceTypeSet(iface).terms
+		} else {
+			tterms = termlist{{t.tilde, t.typ}}
+		}
+		terms = terms.union(tterms)
+	}
+	return &typeSet{terms: terms}
+}
+
+// A term is the set of the types of a union term: the type typ, or all the types whose
+// underlying type is typ if tilde is set. A term with a nil typ is the set of all types,
+// a nil term the empty set
+type term struct {
+	tilde bool
+	typ   Type
+}
+
+func (x *term) String() string {
+	switch {
+	case x == nil:
+		return "∅"
+	case x.typ == nil:
+		return "𝓤"
+	case x.tilde:
+		return "~" + x.typ.String()
+	}
+	return x.typ.String()
+}
+
+// union returns the union x ∪ y: a single term, or x and y if they are disjoint
+func (x *term) union(y *term) (_, _ *term) {
+	switch {
+	case x == nil && y == nil:
+		return nil, nil
+	case x == nil:
+		return y, nil
+	case y == nil:
+		return x, nil
+	case x.typ == nil:
+		return x, nil
+	case y.typ == nil:
+		return y, nil
+	}
+	if x.disjoint(y) {
+		return x, y
+	}
+	// x.typ and y.typ have identical underlying types; the term with ~ includes the other
+	if x.tilde || !y.tilde {
+		return x, nil
+	}
+	return y, nil
+}
+
+// intersect returns the intersection x ∩ y
+func (x *term) intersect(y *term) *term {
+	switch {
+	case x == nil || y == nil:
+		return nil
+	case x.typ == nil:
+		return y
+	case y.typ == nil:
+		return x
+	}
+	if x.disjoint(y) {
+		return nil
+	}
+	if !x.tilde || y.tilde {
+		return x
+	}
+	return y
+}
+
+// includes reports whether t ∈ x
+func (x *term) includes(t Type) bool {
+	switch {
+	case x == nil:
+		return false
+	case x.typ == nil:
+		return true
+	}
+	u := t
+	if x.tilde {
+		u = under(u)
+	}
+	return identical(x.typ, u)
+}
+
+// subsetOf reports whether x ⊆ y
+func (x *term) subsetOf(y *term) bool {
+	switch {
+	case x == nil:
+		return true
+	case y == nil:
+		return false
+	case y.typ == nil:
+		return true
+	case x.typ == nil:
+		return false
+	}
+	if x.disjoint(y) {
+		return false
+	}
+	return !x.tilde || y.tilde
+}
+
+// disjoint reports whether x ∩ y == ∅ for terms that are neither nil nor all types
+func (x *term) disjoint(y *term) bool {
+	ux := x.typ
+	if y.tilde {
+		ux = under(ux)
+	}
+	uy := y.typ
+	if x.tilde {
+		uy = under(uy)
+	}
+	return !identical(ux, uy)
+}
+
+// A termlist is the union of its terms; an empty list is the empty set
+type termlist []*term
+
+// allTermlist is the set of all types
+var allTermlist = termlist{new(term)}
+
+func (xl termlist) String() string {
+	if len(xl) == 0 {
+		return "∅"
+	}
+	s := make([]string, len(xl))
+	for i, x := range xl {
+		s[i] = x.String()
+	}
+	return strings.Join(s, " | ")
+}
+
+// isEmpty reports whether xl is the empty set
+func (xl termlist) isEmpty() bool {
+	for _, x := range xl {
+		if x != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isAll reports whether xl is the set of all types
+func (xl termlist) isAll() bool {
+	for _, x := range xl {
+		if x != nil && x.typ == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// norm returns xl with the terms that overlap merged
+func (xl termlist) norm() termlist {
+	used := make([]bool, len(xl))
+	var rl termlist
+	for i, xi := range xl {
+		if xi == nil || used[i] {
+			continue
+		}
+		for j := i + 1; j < len(xl); j++ {
+			xj := xl[j]
+			if xj == nil || used[j] {
+				continue
+			}
+			if u1, u2 := xi.union(xj); u2 == nil {
+				if u1.typ == nil {
+					return allTermlist
+				}
+				xi = u1
+				used[j] = true
+			}
+		}
+		rl = append(rl, xi)
+	}
+	return rl
+}
+
+// union returns xl ∪ yl
+func (xl termlist) union(yl termlist) termlist {
+	return append(append(termlist(nil), xl...), yl...).norm()
+}
+
+// intersect returns xl ∩ yl
+func (xl termlist) intersect(yl termlist) termlist {
+	if xl.isEmpty() || yl.isEmpty() {
+		return nil
+	}
+	var rl termlist
+	for _, x := range xl {
+		for _, y := range yl {
+			if r := x.intersect(y); r != nil {
+				rl = append(rl, r)
+			}
+		}
+	}
+	return rl.norm()
+}
+
+// includes reports whether t ∈ xl
+func (xl termlist) includes(t Type) bool {
+	for _, x := range xl {
+		if x.includes(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// supersetOf reports whether y ⊆ xl
+func (xl termlist) supersetOf(y *term) bool {
+	for _, x := range xl {
+		if y.subsetOf(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// subsetOf reports whether xl ⊆ yl
+func (xl termlist) subsetOf(yl termlist) bool {
+	if yl.isEmpty() {
+		return xl.isEmpty()
+	}
+	for _, x := range xl {
+		if !yl.supersetOf(x) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,464 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import (
+	"gocompiler/src/ast"
+	"gocompiler/src/constant"
+	"gocompiler/src/tokens"
+	"strconv"
+)
+
+// typ checks the type expression e and returns its type, or Typ[Invalid] after an error
+func (c *Checker) typ(e ast.Expression) Type {
+	typ := c.typInternal(e)
+	c.recordTypeAndValue(e, typexpr, typ, nil)
+	return typ
+}
+
+func (c *Checker) typInternal(e ast.Expression) Type {
+	switch e := e.(type) {
+	case *ast.BadExpression:
+		return Typ[Invalid]
+
+	case *ast.Ident, *ast.SelectorExpression:
+		var x operand
+		c.exprOrType(&x, e)
+		switch x.mode {
+		case typexpr:
+			return x.typ
+		case invalid:
+		case novalue:
+			c.errorf(e.Pos(), "%s used as type", &x)
+		default:
+			c.errorf(e.Pos(), "%s is not a type", e)
+		}
+		return Typ[Invalid]
+
+	case *ast.IndexExpression, *ast.IndexExpressions:
+		return c.instantiatedType(e)
+
+	case *ast.ParenExpression:
+		return c.typ(e.X)
+
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return NewSlice(c.varType(e.ElementType))
+		}
+		n := c.arrayLength(e.Len)
+		return NewArray(c.varType(e.ElementType), n)
+
+	case *ast.Ellipsis:
+		c.errorf(e.Pos(), "invalid use of ...")
+		return Typ[Invalid]
+
+	case *ast.StructType:
+		return c.structType(e)
+
+	case *ast.InterfaceType:
+		return c.interfaceType(e)
+
+	case *ast.StarExpression:
+		return NewPointer(c.typ(e.X))
+
+	case *ast.FunctionType:
+		if e.TypeParams != nil {
+			c.errorf(e.TypeParams.Pos(), "function type must have no type parameters")
+		}
+		return c.signature(e)
+	}
+
+	c.errorf(e.Pos(), "%s is not a type", e)
+	return Typ[Invalid]
+}
+
+// indexedExpr returns the indexed expression of an index expression
+func indexedExpr(e ast.Expression) ast.Expression {
+	switch e := e.(type) {
+	case *ast.IndexExpression:
+		return e.X
+	case *ast.IndexExpressions:
+		return e.X
+	}
+	return e
+}
+
+// indexList returns the indices of an index expression
+func indexList(e ast.Expression) []ast.Expression {
+	switch e := e.(type) {
+	case *ast.IndexExpression:
+		return []ast.Expression{e.Index}
+	case *ast.IndexExpressions:
+		return e.Indices
+	}
+	return nil
+}
+
+// instantiatedType returns the instance of a generic type for the type arguments of the index
+// expression e
+func (c *Checker) instantiatedType(e ast.Expression) Type {
+	var x operand
+	c.genericExprOrType(&x, indexedExpr(e))
+	return c.typeInstance(&x, e)
+}
+
+// typeInstance returns the instance of the generic type x, the indexed expression of e, for the
+// type arguments of e; the arguments must satisfy the constraints of the type parameters
+func (c *Checker) typeInstance(x *operand, e ast.Expression) Type {
+	xlist := indexList(e)
+	switch {
+	case x.mode == invalid:
+		c.use(xlist...)
+		return Typ[Invalid]
+	case x.mode != typexpr:
+		c.errorf(e.Pos(), "%s is not a type", e)
+		c.use(xlist...)
+		return Typ[Invalid]
+	case !isGeneric(x.typ):
+		if isValid(x.typ) {
+			c.errorf(e.Pos(), "%s is not a generic type", x.typ)
+		}
+		c.use(xlist...)
+		return Typ[Invalid]
+	}
+	orig := x.typ.(*Named)
+
+	targs := c.typeList(xlist)
+	if targs == nil {
+		return Typ[Invalid]
+	}
+	if got, want := len(targs), len(orig.tparams); got != want {
+		if got > want {
+			c.errorf(xlist[want].Pos(), "too many type arguments for type %s: have %d, want %d", orig.obj.name, got, want)
+		} else {
+			c.errorf(e.Pos(), "not enough type arguments for type %s: have %d, want %d", orig.obj.name, got, want)
+		}
+		return Typ[Invalid]
+	}
+	if i, cause := verify(orig.tparams, targs); i >= 0 {
+		c.errorf(xlist[i].Pos(), "%s", cause)
+		return Typ[Invalid]
+	}
+	return instantiateNamed(orig, targs)
+}
+
+// typeList returns the types of the type arguments list, or nil if one of them is invalid
+func (c *Checker) typeList(list []ast.Expression) []Type {
+	res := make([]Type, len(list))
+	valid := true
+	for i, e := range list {
+		res[i] = c.varType(e)
+		if !isValid(res[i]) {
+			valid = false
+		}
+	}
+	if !valid {
+		return nil
+	}
+	return res
+}
+
+// arrayLength returns the length of an array type with the length expression e,
+// or -1 if it is not a valid length
+func (c *Checker) arrayLength(e ast.Expression) int64 {
+	var x operand
+	c.expr(&x, e)
+	if x.mode != constant_ {
+		if x.mode != invalid {
+			c.errorf(e.Pos(), "array length %s must be constant", &x)
+		}
+		return -1
+	}
+	if isUntyped(x.typ) || isInteger(x.typ) {
+		if val := constant.ToInt(x.val); val.Kind() == constant.Int {
+			if representableConst(val, Typ[Int], nil) {
+				if n, ok := constant.Int64Val(val); ok && n >= 0 {
+					return n
+				}
+			}
+		}
+	}
+	if isInteger(x.typ) {
+		c.errorf(e.Pos(), "invalid array length %s", &x)
+	} else {
+		c.errorf(e.Pos(), "array length %s must be integer", &x)
+	}
+	return -1
+}
+
+// structType returns the struct type of e; the names of the fields must be unique
+func (c *Checker) structType(e *ast.StructType) *Struct {
+	var fields []*Var
+	var tags []string
+	seen := map[string]*Var{}
+	add := func(fld *Var, tag string) {
+		if tag != "" && tags == nil {
+			tags = make([]string, len(fields))
+		}
+		if tags != nil {
+			tags = append(tags, tag)
+		}
+		fields = append(fields, fld)
+		if fld.name == "_" {
+			return
+		}
+		if alt := seen[fld.name]; alt != nil {
+			c.errorf(fld.pos, "%s redeclared\n\tprevious declaration at %s", fld.name, alt.pos.ToString())
+			return
+		}
+		seen[fld.name] = fld
+	}
+
+	for _, f := range e.Fields.List {
+		typ := c.varType(f.Type)
+		tag := ""
+		if f.Tag != nil {
+			tag, _ = strconv.Unquote(f.Tag.Value.Lit)
+		}
+		if len(f.Names) == 0 {
+			// an embedded field is named after its type
+			ident := embeddedFieldIdent(f.Type)
+			fld := NewField(ident.Pos(), ident.Name, typ, true)
+			base := typ
+			if p, isPointer := typ.(*Pointer); isPointer {
+				base = p.base
+			}
+			if _, isPointer := under(base).(*Pointer); isPointer {
+				c.errorf(f.Type.Pos(), "embedded field type cannot be a pointer")
+			}
+			c.recordDef(ident, fld)
+			add(fld, tag)
+			continue
+		}
+		for _, ident := range f.Names {
+			fld := NewField(ident.Pos(), ident.Name, typ, false)
+			c.declare(ident, fld)
+			add(fld, tag)
+		}
+	}
+	return NewStruct(fields, tags)
+}
+
+// embeddedFieldIdent returns the identifier naming an embedded field of type e
+func embeddedFieldIdent(e ast.Expression) *ast.Ident {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpression:
+		return embeddedFieldIdent(e.X)
+	case *ast.SelectorExpression:
+		return e.Selector
+	case *ast.IndexExpression:
+		return embeddedFieldIdent(e.X)
+	case *ast.IndexExpressions:
+		return embeddedFieldIdent(e.X)
+	case *ast.ParenExpression:
+		return embeddedFieldIdent(e.X)
+	}
+	return &ast.Ident{NamePos: e.Pos(), Name: "_"}
+}
+
+// interfaceType returns the interface type of e; the names of the methods must be unique
+func (c *Checker) interfaceType(e *ast.InterfaceType) *Interface {
+	ityp := &Interface{}
+	seen := map[string]bool{}
+	for _, f := range e.Methods.List {
+		if len(f.Names) == 0 {
+			ityp.embeddeds = append(ityp.embeddeds, c.typeElem(f.Type))
+			continue
+		}
+		name := f.Names[0]
+		ftype, isFunc := f.Type.(*ast.FunctionType)
+		if !isFunc {
+			c.errorf(f.Type.Pos(), "%s is not a method signature", f.Type)
+			continue
+		}
+		m := &Func{object{name: name.Name, typ: c.signature(ftype), pos: name.Pos()}}
+		c.recordDef(name, m)
+		switch {
+		case name.Name == "_":
+			c.errorf(name.Pos(), "methods must have a unique non-blank name")
+		case seen[name.Name]:
+			c.errorf(name.Pos(), "duplicate method %s", name.Name)
+		default:
+			seen[name.Name] = true
+			ityp.methods = append(ityp.methods, m)
+		}
+	}
+	return ityp
+}
+
+// typeElem returns the type of the element e embedded in an interface: a type, or a union
+// of terms, each a type or ~T for the types whose underlying type is T
+func (c *Checker) typeElem(e ast.Expression) Type {
+	exprs := unionTerms(e, nil)
+	terms := make([]*Term, len(exprs))
+	for i, x := range exprs {
+		tilde := false
+		if u, isUnary := x.(*ast.UnaryExpression); isUnary && u.Operator == tokens.TILDE {
+			tilde, x = true, u.X
+		}
+		typ := c.typ(x)
+		if isTypeParam(typ) {
+			switch {
+			case tilde:
+				c.errorf(x.Pos(), "type in term %s cannot be a type parameter", exprs[i])
+			case len(exprs) == 1:
+				c.errorf(x.Pos(), "cannot embed a type parameter")
+			default:
+				c.errorf(x.Pos(), "term cannot be a type parameter")
+			}
+			typ = Typ[Invalid]
+		}
+		terms[i] = NewTerm(tilde, typ)
+	}
+	if len(terms) == 1 && !terms[0].tilde {
+		return terms[0].typ
+	}
+
+	for i, t := range terms {
+		if !isValid(t.typ) {
+			continue
+		}
+		u := under(t.typ)
+		iface, isIface := u.(*Interface)
+		if t.tilde {
+			if isIface {
+				c.errorf(exprs[i].Pos(), "invalid use of ~ (%s is an interface)", t.typ)
+				continue
+			}
+			if !identical(u, t.typ) {
+				c.errorf(exprs[i].Pos(), "invalid use of ~ (underlying type of %s is %s)", t.typ, u)
+				continue
+			}
+		}
+		if isIface && len(terms) > 1 {
+			switch s := iface.typeSet(); {
+			case t.typ == universe["comparable"].Type():
+				c.errorf(exprs[i].Pos(), "cannot use comparable in union")
+				continue
+			case len(s.methods) > 0:
+				c.errorf(exprs[i].Pos(), "cannot use %s in union (%s contains methods)", exprs[i], t.typ)
+				continue
+			case s.comparable:
+				c.errorf(exprs[i].Pos(), "cannot use %s in union (%s embeds comparable)", exprs[i], t.typ)
+				continue
+			}
+		}
+		for _, prev := range terms[:i] {
+			if isValid(prev.typ) && !isInterface(prev.typ) && !isIface &&
+				!(&term{t.tilde, t.typ}).disjoint(&term{prev.tilde, prev.typ}) {
+				c.errorf(exprs[i].Pos(), "overlapping terms %s and %s", t, prev)
+				break
+			}
+		}
+	}
+	return NewUnion(terms)
+}
+
+// unionTerms appends the terms of the union e to list
+func unionTerms(e ast.Expression, list []ast.Expression) []ast.Expression {
+	if b, isBinary := e.(*ast.BinaryExpression); isBinary && b.Operator == tokens.OR {
+		return unionTerms(b.RightX, unionTerms(b.LeftX, list))
+	}
+	return append(list, e)
+}
+
+// signature returns the function type of e and declares its type parameters, parameters
+// and results
+func (c *Checker) signature(e *ast.FunctionType) *Signature {
+	var tparams []*TypeParam
+	if e.TypeParams != nil {
+		tparams = c.collectTypeParams(e.TypeParams)
+	}
+	params, variadic := c.collectParams(e.Params, true)
+	results, _ := c.collectParams(e.Results, false)
+	sig := NewSignature(params, results, variadic)
+	sig.tparams = tparams
+	return sig
+}
+
+// collectTypeParams declares the type parameters of list and returns them; their constraints
+// may refer to any of them
+func (c *Checker) collectTypeParams(list *ast.FieldList) []*TypeParam {
+	var tparams []*TypeParam
+	for _, f := range list.List {
+		for _, name := range f.Names {
+			tpar := NewTypeParam(NewTypeName(name.Pos(), name.Name, nil), nil)
+			tpar.index = len(tparams)
+			c.declare(name, tpar.obj)
+			tparams = append(tparams, tpar)
+		}
+	}
+	i := 0
+	for _, f := range list.List {
+		bound := c.bound(f.Type)
+		for range f.Names {
+			tparams[i].bound = bound
+			i++
+		}
+	}
+	return tparams
+}
+
+// bound returns the constraint of a type parameter declared with the type expression e: an
+// interface, or the implicit interface of the other types and of the unions, like ~int
+func (c *Checker) bound(e ast.Expression) Type {
+	if b, isBinary := e.(*ast.BinaryExpression); isBinary && b.Operator == tokens.OR {
+		return &Interface{embeddeds: []Type{c.typeElem(e)}, implicit: true}
+	}
+	if u, isUnary := e.(*ast.UnaryExpression); isUnary && u.Operator == tokens.TILDE {
+		return &Interface{embeddeds: []Type{c.typeElem(e)}, implicit: true}
+	}
+	t := c.typ(e)
+	switch {
+	case isTypeParam(t):
+		c.errorf(e.Pos(), "cannot use a type parameter as constraint")
+		return Typ[Invalid]
+	case !isValid(t) || isInterface(t):
+		return t
+	}
+	return &Interface{embeddeds: []Type{t}, implicit: true}
+}
+
+// collectParams returns the variables of a parameter or result list and whether the last
+// parameter is variadic
+func (c *Checker) collectParams(list *ast.FieldList, variadicOk bool) (*Tuple, bool) {
+	if list == nil {
+		return nil, false
+	}
+	var vars []*Var
+	variadic := false
+	for i, field := range list.List {
+		ftype := field.Type
+		isVariadic := false
+		if t, isEllipsis := ftype.(*ast.Ellipsis); isEllipsis && t.Elt != nil {
+			ftype = t.Elt
+			if variadicOk && i == len(list.List)-1 && len(field.Names) <= 1 {
+				isVariadic = true
+			} else if !variadicOk {
+				c.errorf(t.Pos(), "invalid use of ...")
+			}
+			// otherwise reported by the parser
+		}
+		typ := c.varType(ftype)
+		if isVariadic {
+			typ = NewSlice(typ)
+			variadic = true
+		}
+		if len(field.Names) == 0 {
+			vars = append(vars, NewVar(ftype.Pos(), "", typ))
+			continue
+		}
+		for _, name := range field.Names {
+			v := NewVar(name.Pos(), name.Name, typ)
+			c.declare(name, v)
+			vars = append(vars, v)
+		}
+	}
+	return NewTuple(vars...), variadic
+}
@@ -0,0 +1,145 @@
+// Copyright 2011 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Adapted from go/types to the syntax trees of this module.
+
+package types
+
+import "gocompiler/src/constant"
+
+// Typ contains the predeclared *Basic types indexed by their kind
+var Typ = [...]*Basic{
+	Invalid: {Invalid, 0, "invalid type"},
+
+	Bool:       {Bool, IsBoolean, "bool"},
+	Int:        {Int, IsInteger, "int"},
+	Int8:       {Int8, IsInteger, "int8"},
+	Int16:      {Int16, IsInteger, "int16"},
+	Int32:      {Int32, IsInteger, "int32"},
+	Int64:      {Int64, IsInteger, "int64"},
+	Uint:       {Uint, IsInteger | IsUnsigned, "uint"},
+	Uint8:      {Uint8, IsInteger | IsUnsigned, "uint8"},
+	Uint16:     {Uint16, IsInteger | IsUnsigned, "uint16"},
+	Uint32:     {Uint32, IsInteger | IsUnsigned, "uint32"},
+	Uint64:     {Uint64, IsInteger | IsUnsigned, "uint64"},
+	Uintptr:    {Uintptr, IsInteger | IsUnsigned, "uintptr"},
+	Float32:    {Float32, IsFloat, "float32"},
+	Float64:    {Float64, IsFloat, "float64"},
+	Complex64:  {Complex64, IsComplex, "complex64"},
+	Complex128: {Complex128, IsComplex, "complex128"},
+	String:     {String, IsString, "string"},
+
+	UntypedBool:    {UntypedBool, IsBoolean | IsUntyped, "untyped bool"},
+	UntypedInt:     {UntypedInt, IsInteger | IsUntyped, "untyped int"},
+	UntypedRune:    {UntypedRune, IsInteger | IsUntyped, "untyped rune"},
+	UntypedFloat:   {UntypedFloat, IsFloat | IsUntyped, "untyped float"},
+	UntypedComplex: {UntypedComplex, IsComplex | IsUntyped, "untyped complex"},
+	UntypedString:  {UntypedString, IsString | IsUntyped, "untyped string"},
+	UntypedNil:     {UntypedNil, IsUntyped, "untyped nil"},
+}
+
+// the aliases byte and rune are distinct objects denoting the same types as uint8 and int32
+var aliases = [...]*Basic{
+	{Byte, IsInteger | IsUnsigned, "byte"},
+	{Rune, IsInteger, "rune"},
+}
+
+var (
+	universe      = map[string]Object{}
+	universeAny   = &Interface{}
+	universeError *Named
+	universeIota  *Const
+)
+
+// builtinId identifies a built-in function
+type builtinId int
+
+const (
+	_Append builtinId = iota
+	_Cap
+	_Clear
+	_Close
+	_Complex
+	_Copy
+	_Delete
+	_Imag
+	_Len
+	_Make
+	_Max
+	_Min
+	_New
+	_Panic
+	_Print
+	_Println
+	_Real
+	_Recover
+)
+
+var predeclaredFuncs = [...]struct {
+	name     string
+	nargs    int
+	variadic bool
+	kind     exprKind
+}{
+	_Append:  {"append", 1, true, expression},
+	_Cap:     {"cap", 1, false, expression},
+	_Clear:   {"clear", 1, false, statement},
+	_Close:   {"close", 1, false, statement},
+	_Complex: {"complex", 2, false, expression},
+	_Copy:    {"copy", 2, false, statement},
+	_Delete:  {"delete", 2, false, statement},
+	_Imag:    {"imag", 1, false, expression},
+	_Len:     {"len", 1, false, expression},
+	_Make:    {"make", 1, true, expression},
+	_Max:     {"max", 1, true, expression},
+	_Min:     {"min", 1, true, expression},
+	_New:     {"new", 1, false, expression},
+	_Panic:   {"panic", 1, false, statement},
+	_Print:   {"print", 0, true, statement},
+	_Println: {"println", 0, true, statement},
+	_Real:    {"real", 1, false, expression},
+	_Recover: {"recover", 0, false, statement},
+}
+
+// exprKind tells whether a built-in call may be used as an expression statement
+type exprKind int
+
+const (
+	expression exprKind = iota // only as an expression
+	statement                  // also as a statement
+)
+
+func init() {
+	for _, t := range Typ {
+		if t.kind != Invalid && !t.is(IsUntyped) {
+			universe[t.name] = NewTypeName(noPos, t.name, t)
+		}
+	}
+	for _, t := range aliases {
+		universe[t.name] = NewTypeName(noPos, t.name, t)
+	}
+	universe["any"] = NewTypeName(noPos, "any", universeAny)
+
+	// type error interface{ Error() string }
+	errorObj := NewTypeName(noPos, "error", nil)
+	universeError = NewNamed(errorObj, nil)
+	result := NewVar(noPos, "", Typ[String])
+	errorMethod := &Func{object{name: "Error", typ: NewSignature(nil, NewTuple(result), false)}}
+	universeError.underlying = NewInterface([]*Func{errorMethod})
+	universe["error"] = errorObj
+
+	// comparable is a constraint interface, usable for type parameters only
+	comparableIface := &Interface{tset: &typeSet{comparable: true, terms: allTermlist}}
+	universe["comparable"] = NewTypeName(noPos, "comparable", NewNamed(NewTypeName(noPos, "comparable", nil), comparableIface))
+
+	universe["true"] = NewConst(noPos, "true", Typ[UntypedBool], constant.MakeBool(true))
+	universe["false"] = NewConst(noPos, "false", Typ[UntypedBool], constant.MakeBool(false))
+	universeIota = NewConst(noPos, "iota", Typ[UntypedInt], constant.MakeInt64(0))
+	universe["iota"] = universeIota
+	universe["nil"] = &Nil{object{name: "nil", typ: Typ[UntypedNil]}}
+
+	for id, f := range predeclaredFuncs {
+		universe[f.name] = &Builtin{object{name: f.name, typ: Typ[Invalid]}, builtinId(id)}
+	}
+}